@@ -0,0 +1,80 @@
+package xmltokenizer
+
+import "math/bits"
+
+// adaptiveTokensPerRead is the number of tokens of the typical size a read
+// aims to hold, see WithAdaptiveBuffer.
+const adaptiveTokensPerRead = 64
+
+// WithAdaptiveBuffer directs XML Tokenizer to observe the sizes of the tokens
+// it reads, see Stats, and to size its reads and its buffer accordingly rather
+// than guessing a read buffer size for documents of every kind: a read aims to
+// hold 64 tokens of the typical size, and on Reset the buffer is sized up front
+// to hold the largest recent token, so a Tokenizer reused for a mix of small
+// and large documents neither reads in tiny chunks nor grows again and again.
+// The size set by WithReadBufferSize is the minimum read size, and the buffer
+// never grows beyond the auto grow buffer max limit. It has no effect with
+// WithFixedBuffer.
+func WithAdaptiveBuffer() Option {
+	return func(o *options) { o.adaptiveBuffer = true }
+}
+
+// TokenStats are statistics of the sizes of the tokens read by a Tokenizer,
+// kept across Reset, see WithAdaptiveBuffer.
+type TokenStats struct {
+	Tokens      int64 // Number of tokens.
+	Bytes       int64 // Total size of the tokens in bytes, including their CharData.
+	TypicalSize int   // Moving average of the token sizes, weighing the recent ones more.
+	PeakSize    int   // Size of the largest recent token, halved on every Reset.
+}
+
+// Stats returns the statistics of the sizes of the tokens read so far. They
+// are only collected when WithAdaptiveBuffer is specified.
+func (t *Tokenizer) Stats() TokenStats { return t.stats }
+
+// observe records the size of a token, see WithAdaptiveBuffer.
+func (t *Tokenizer) observe(size int) {
+	s := &t.stats
+	s.Tokens++
+	s.Bytes += int64(size)
+	if s.Tokens == 1 {
+		s.TypicalSize = size
+	} else {
+		s.TypicalSize += (size - s.TypicalSize) / 8
+	}
+	s.PeakSize = max(s.PeakSize, size)
+}
+
+// readSize returns the number of bytes to read at once. The adaptive read
+// size is bounded so a read does not push a token over the auto grow buffer
+// max limit much sooner than a read of the default size would.
+func (t *Tokenizer) readSize() int {
+	size := t.options.readBufferSize
+	if !t.options.adaptiveBuffer || t.options.fixedBuffer != nil {
+		return size
+	}
+	adaptive := min(ceilPow2(t.stats.TypicalSize*adaptiveTokensPerRead),
+		t.options.autoGrowBufferMaxLimitSize/8)
+	return max(size, adaptive)
+}
+
+// bufferSize returns the capacity the buffer needs on reset, excluding the
+// additional bytes needed to memmove the remaining bytes, and decays the peak
+// size so a single huge document does not inflate the buffer forever.
+func (t *Tokenizer) bufferSize() int {
+	size := t.readSize()
+	if !t.options.adaptiveBuffer || t.stats.PeakSize == 0 {
+		return size
+	}
+	size = min(ceilPow2(t.stats.PeakSize+size), t.options.autoGrowBufferMaxLimitSize)
+	t.stats.PeakSize /= 2
+	return size
+}
+
+// ceilPow2 returns the smallest power of two not less than n, or 0 if n <= 0.
+func ceilPow2(n int) int {
+	if n <= 0 {
+		return 0
+	}
+	return 1 << bits.Len(uint(n-1))
+}
@@ -0,0 +1,70 @@
+package xmltokenizer_test
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/muktihari/xmltokenizer"
+)
+
+// readCounter counts the reads from the underlying io.Reader.
+type readCounter struct {
+	r     io.Reader
+	reads int
+}
+
+func (c *readCounter) Read(p []byte) (int, error) {
+	c.reads++
+	return c.r.Read(p)
+}
+
+func TestWithAdaptiveBuffer(t *testing.T) {
+	small := "<a>" + strings.Repeat(`<b x="`+strings.Repeat("x", 200)+`"/>`, 1000) + "</a>"
+	large := "<a><b>" + strings.Repeat("x", 100<<10) + "</b></a>"
+
+	tokenize := func(tok *xmltokenizer.Tokenizer) {
+		for {
+			_, err := tok.Token()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	reads := func(doc string, opts ...xmltokenizer.Option) int {
+		r := &readCounter{r: strings.NewReader(doc)}
+		tok := xmltokenizer.New(struct{ io.Reader }{r}, opts...)
+		tokenize(tok)
+		return r.reads
+	}
+
+	if fixed, adaptive := reads(small), reads(small, xmltokenizer.WithAdaptiveBuffer()); adaptive >= fixed/2 {
+		t.Fatalf("expected adaptive reads less than %d, got: %d", fixed/2, adaptive)
+	}
+
+	m := new(metrics)
+	tok := xmltokenizer.New(strings.NewReader(large), xmltokenizer.WithAdaptiveBuffer(), xmltokenizer.WithMetrics(m))
+	tokenize(tok)
+	stats := tok.Stats()
+	if stats.Tokens != 4 || stats.PeakSize != 100<<10+3 || stats.Bytes != int64(len(large)) {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+	if len(m.grows) == 0 {
+		t.Fatalf("expected buffer to grow")
+	}
+
+	// The buffer is sized up front once released, e.g. by Close.
+	tok.Close()
+	m = new(metrics)
+	tok.Reset(strings.NewReader(large), xmltokenizer.WithAdaptiveBuffer(), xmltokenizer.WithMetrics(m))
+	tokenize(tok)
+	if len(m.grows) != 0 {
+		t.Fatalf("expected no grow, got: %v", m.grows)
+	}
+	if stats = tok.Stats(); stats.Tokens != 8 {
+		t.Fatalf("expected 8 tokens, got: %d", stats.Tokens)
+	}
+}
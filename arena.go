@@ -0,0 +1,141 @@
+package xmltokenizer
+
+import "bytes"
+
+// defaultArenaBlockSize is the default size in bytes of a block of an Arena.
+const defaultArenaBlockSize = 64 << 10
+
+// Arena hands out memory from large blocks, so retaining many tokens, e.g. all
+// rows of a sheet, costs one allocation per block rather than per token. Memory
+// is never reused: a block is garbage collected once none of the tokens copied
+// into it is referenced. The zero value is ready to use. An Arena is not safe
+// for concurrent use.
+type Arena struct {
+	blockSize int
+	bytes     []byte
+	attrs     []Attr
+}
+
+// NewArena creates an Arena allocating blocks of at least blockSize bytes.
+// Default: 64 KB.
+func NewArena(blockSize int) *Arena {
+	if blockSize <= 0 {
+		blockSize = defaultArenaBlockSize
+	}
+	return &Arena{blockSize: blockSize}
+}
+
+func (a *Arena) size() int {
+	if a.blockSize <= 0 {
+		return defaultArenaBlockSize
+	}
+	return a.blockSize
+}
+
+// alloc returns a zero-length slice with capacity n.
+func (a *Arena) alloc(n int) []byte {
+	if n > cap(a.bytes)-len(a.bytes) {
+		size := a.size()
+		if n > size {
+			size = n
+		}
+		a.bytes = make([]byte, 0, size)
+	}
+	b := a.bytes[len(a.bytes) : len(a.bytes) : len(a.bytes)+n]
+	a.bytes = a.bytes[:len(a.bytes)+n]
+	return b
+}
+
+// allocAttrs returns a zero-length slice of Attr with capacity n.
+func (a *Arena) allocAttrs(n int) []Attr {
+	if n > cap(a.attrs)-len(a.attrs) {
+		size := a.size() / 128
+		if n > size {
+			size = n
+		}
+		a.attrs = make([]Attr, 0, size)
+	}
+	attrs := a.attrs[len(a.attrs) : len(a.attrs) : len(a.attrs)+n]
+	a.attrs = a.attrs[:len(a.attrs)+n]
+	return attrs
+}
+
+// copyToken returns a deep copy of token whose memory is owned by the arena.
+func (a *Arena) copyToken(token Token) Token {
+	n := nameSize(token.Name) + len(token.Data)
+	for i := range token.Attrs {
+		n += nameSize(token.Attrs[i].Name) + len(token.Attrs[i].Value)
+	}
+	b := a.alloc(n)
+
+	token.Name, b = copyName(b, token.Name)
+	token.Data, b = copyBytes(b, token.Data)
+	if token.Attrs != nil {
+		attrs := a.allocAttrs(len(token.Attrs))
+		for _, attr := range token.Attrs {
+			attr.Name, b = copyName(b, attr.Name)
+			attr.Value, b = copyBytes(b, attr.Value)
+			attrs = append(attrs, attr)
+		}
+		token.Attrs = attrs
+	}
+	return token
+}
+
+// nameSize returns the number of bytes copyName appends for name.
+func nameSize(name Name) int {
+	n := len(name.Full) + len(name.Space)
+	if !sharesPrefix(name) {
+		n += len(name.Prefix)
+	}
+	if !sharesLocal(name) {
+		n += len(name.Local)
+	}
+	return n
+}
+
+// sharesPrefix reports whether the Prefix of name can refer to its Full, as
+// it does for a name returned by the Tokenizer.
+func sharesPrefix(name Name) bool {
+	return name.Full != nil && bytes.HasPrefix(name.Full, name.Prefix)
+}
+
+// sharesLocal reports whether the Local of name can refer to its Full.
+func sharesLocal(name Name) bool {
+	return name.Full != nil && bytes.HasSuffix(name.Full, name.Local)
+}
+
+// copyName appends the name to b, which must have enough capacity,
+// returning the copied name referring to b. Prefix and Local refer to the
+// copied Full when they are its prefix and suffix, and are copied on their
+// own otherwise, e.g. for a hand-built name.
+func copyName(b []byte, name Name) (Name, []byte) {
+	var copied Name
+	copied.Full, b = copyBytes(b, name.Full)
+	switch {
+	case name.Prefix == nil:
+	case sharesPrefix(name):
+		copied.Prefix = copied.Full[:len(name.Prefix):len(name.Prefix)]
+	default:
+		copied.Prefix, b = copyBytes(b, name.Prefix)
+	}
+	switch {
+	case name.Local == nil:
+	case sharesLocal(name):
+		copied.Local = copied.Full[len(name.Full)-len(name.Local):]
+	default:
+		copied.Local, b = copyBytes(b, name.Local)
+	}
+	copied.Space, b = copyBytes(b, name.Space)
+	return copied, b
+}
+
+// copyBytes appends src to b, which must have enough capacity, returning the
+// copy of src referring to b, nil if src is nil.
+func copyBytes(b, src []byte) ([]byte, []byte) {
+	if src == nil {
+		return nil, b
+	}
+	b = append(b, src...)
+	return b[len(b)-len(src) : len(b) : len(b)], b
+}
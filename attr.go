@@ -0,0 +1,61 @@
+package xmltokenizer
+
+import "bytes"
+
+// AttrIter lazily iterates the attributes of a raw tag, such as the bytes
+// returned by RawToken or Raw, so callers only interested in a few attributes
+// of an element with many don't pay for parsing and storing them all.
+//
+//	it := xmltokenizer.NewAttrIter(b)
+//	for it.Next() {
+//		attr := it.Attr()
+//		...
+//	}
+type AttrIter struct {
+	b    []byte
+	attr Attr
+	n    int // number of attributes iterated
+}
+
+// NewAttrIter returns an AttrIter over the attributes of the given raw tag.
+// Tags starting with "</", "<?" or "<!" have no attributes.
+func NewAttrIter(tag []byte) AttrIter {
+	if len(tag) < 2 || tag[0] != '<' || tag[1] == '/' || tag[1] == '?' || tag[1] == '!' {
+		return AttrIter{}
+	}
+	tag = tag[1:]
+	pos := bytes.IndexAny(tag, "/> \t\r\n")
+	if pos == -1 {
+		return AttrIter{}
+	}
+	return AttrIter{b: tag[pos:]}
+}
+
+// Next advances to the next attribute, it returns false when there are
+// no more attributes or the tag is malformed.
+func (it *AttrIter) Next() bool {
+	attr, n, ok := nextAttr(it.b)
+	if !ok {
+		it.b, it.attr = nil, Attr{}
+		return false
+	}
+	attr.Index, it.n = it.n, it.n+1
+	it.b, it.attr = it.b[n:], attr
+	return true
+}
+
+// Attr returns the current attribute. The returned Attr
+// refers to the raw tag given to NewAttrIter.
+func (it *AttrIter) Attr() Attr { return it.attr }
+
+// LookupAttr returns the value of the first attribute of the raw tag whose
+// Full name equals full, and whether it is found.
+func LookupAttr(tag []byte, full string) (value []byte, ok bool) {
+	it := NewAttrIter(tag)
+	for it.Next() {
+		if string(it.attr.Name.Full) == full {
+			return it.attr.Value, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,60 @@
+package xmltokenizer_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/muktihari/xmltokenizer"
+)
+
+func TestAttrIter(t *testing.T) {
+	tt := []struct {
+		name     string
+		tag      string
+		expected []xmltokenizer.Attr
+	}{
+		{
+			name: "start element with chardata",
+			tag:  `<trkpt lat="47.1" gpx:lon='8>5'>text = "x"`,
+			expected: []xmltokenizer.Attr{
+				{Name: xmltokenizer.Name{Local: []byte("lat"), Full: []byte("lat")}, Value: []byte("47.1")},
+				{Name: xmltokenizer.Name{Prefix: []byte("gpx"), Local: []byte("lon"), Full: []byte("gpx:lon")}, Value: []byte("8>5"), Index: 1},
+			},
+		},
+		{
+			name: "self-closing with spaces",
+			tag:  "<c\n r = \"E3\" s=\"1\" />",
+			expected: []xmltokenizer.Attr{
+				{Name: xmltokenizer.Name{Local: []byte("r"), Full: []byte("r")}, Value: []byte("E3")},
+				{Name: xmltokenizer.Name{Local: []byte("s"), Full: []byte("s")}, Value: []byte("1"), Index: 1},
+			},
+		},
+		{name: "no attrs", tag: "<a/>"},
+		{name: "end element", tag: "</a>"},
+		{name: "procinst", tag: `<?xml version="1.0"?>`},
+		{name: "malformed", tag: `<a x="1`},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var attrs []xmltokenizer.Attr
+			it := xmltokenizer.NewAttrIter([]byte(tc.tag))
+			for it.Next() {
+				attrs = append(attrs, it.Attr())
+			}
+			if diff := cmp.Diff(attrs, tc.expected); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
+
+func TestLookupAttr(t *testing.T) {
+	tag := []byte(`<trkpt lat="47.1" lon="8.5">`)
+	if value, ok := xmltokenizer.LookupAttr(tag, "lon"); !ok || string(value) != "8.5" {
+		t.Fatalf("expected: %q, got: %q (%t)", "8.5", value, ok)
+	}
+	if value, ok := xmltokenizer.LookupAttr(tag, "ele"); ok {
+		t.Fatalf("expected not found, got: %q", value)
+	}
+}
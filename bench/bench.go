@@ -0,0 +1,113 @@
+// Package bench provides reusable benchmark drivers to compare the xmltokenizer
+// against encoding/xml on your own corpora, e.g.:
+//
+//	func BenchmarkCorpus(b *testing.B) {
+//		paths, _ := filepath.Glob("corpus/*.gpx")
+//		bench.Run(b, paths, bench.Tokenize, bench.DecodeGPX)
+//	}
+//
+// Then run it with -benchmem and compare the results using benchstat.
+package bench
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/muktihari/xmltokenizer"
+	"github.com/muktihari/xmltokenizer/internal/gpx"
+	"github.com/muktihari/xmltokenizer/internal/xlsx"
+)
+
+// Driver is a workload measured by Run, implemented on top of the
+// xmltokenizer and, for comparison, on top of encoding/xml.
+type Driver struct {
+	Name         string
+	XMLTokenizer func(r io.Reader) error
+	Stdlib       func(r io.Reader) error // Optional.
+}
+
+var (
+	// Tokenize reads every token of the document.
+	Tokenize = Driver{
+		Name:         "tokenize",
+		XMLTokenizer: tokenizeWithXMLTokenizer,
+		Stdlib:       tokenizeWithStdlibXML,
+	}
+	// DecodeGPX decodes a GPX document into a struct.
+	DecodeGPX = Driver{
+		Name:         "decode-gpx",
+		XMLTokenizer: func(r io.Reader) error { _, err := gpx.UnmarshalWithXMLTokenizer(r); return err },
+		Stdlib:       func(r io.Reader) error { _, err := gpx.UnmarshalWithStdlibXML(r); return err },
+	}
+	// DecodeXLSX decodes the sheetData of an XLSX worksheet, e.g. xl/worksheets/sheet1.xml, into a struct.
+	DecodeXLSX = Driver{
+		Name:         "decode-xlsx",
+		XMLTokenizer: func(r io.Reader) error { _, err := xlsx.UnmarshalWithXMLTokenizer(r); return err },
+		Stdlib:       func(r io.Reader) error { _, err := xlsx.UnmarshalWithStdlibXML(r); return err },
+	}
+)
+
+// Run runs a sub-benchmark named "file/driver/implementation" for every file in
+// paths, driver and implementation, reporting allocations and the throughput
+// in bytes. The files are read into memory beforehand so only decoding is
+// measured. A sub-benchmark is skipped if its implementation returns an error.
+func Run(b *testing.B, paths []string, drivers ...Driver) {
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			b.Fatalf("could not read file: %v", err)
+		}
+		b.Run(filepath.Base(path), func(b *testing.B) {
+			for _, d := range drivers {
+				b.Run(d.Name, func(b *testing.B) {
+					b.Run("xmltokenizer", func(b *testing.B) { run(b, data, d.XMLTokenizer) })
+					if d.Stdlib != nil {
+						b.Run("stdlib.xml", func(b *testing.B) { run(b, data, d.Stdlib) })
+					}
+				})
+			}
+		})
+	}
+}
+
+func run(b *testing.B, data []byte, fn func(r io.Reader) error) {
+	b.ReportAllocs()
+	b.SetBytes(int64(len(data)))
+	r := bytes.NewReader(data)
+	for i := 0; i < b.N; i++ {
+		r.Reset(data)
+		if err := fn(r); err != nil {
+			b.Skipf("could not decode: %v", err)
+		}
+	}
+}
+
+func tokenizeWithXMLTokenizer(r io.Reader) error {
+	tok := xmltokenizer.New(r)
+	for {
+		_, err := tok.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func tokenizeWithStdlibXML(r io.Reader) error {
+	dec := xml.NewDecoder(r)
+	for {
+		_, err := dec.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package bench_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/muktihari/xmltokenizer/bench"
+)
+
+func TestDrivers(t *testing.T) {
+	tt := []struct {
+		driver   bench.Driver
+		filename string
+	}{
+		{driver: bench.Tokenize, filename: "long_comment_token.xml"},
+		{driver: bench.DecodeGPX, filename: "hike_mt_prau.gpx"},
+		{driver: bench.DecodeXLSX, filename: "xlsx_sheet1.xml"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.driver.Name, func(t *testing.T) {
+			f, err := os.Open(filepath.Join("..", "testdata", tc.filename))
+			if err != nil {
+				panic(err)
+			}
+			defer f.Close()
+			if err := tc.driver.XMLTokenizer(f); err != nil {
+				t.Fatalf("xmltokenizer: expected nil, got: %v", err)
+			}
+			if _, err := f.Seek(0, 0); err != nil {
+				t.Fatal(err)
+			}
+			if err := tc.driver.Stdlib(f); err != nil {
+				t.Fatalf("stdlib: expected nil, got: %v", err)
+			}
+		})
+	}
+}
+
+func BenchmarkRun(b *testing.B) {
+	paths := []string{filepath.Join("..", "testdata", "hike_mt_prau.gpx")}
+	bench.Run(b, paths, bench.Tokenize, bench.DecodeGPX)
+}
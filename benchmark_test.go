@@ -129,3 +129,49 @@ func BenchmarkUnmarshalXLSX(b *testing.B) {
 		}
 	})
 }
+
+func BenchmarkTrimSpace(b *testing.B) {
+	for _, in := range []struct{ name, text string }{
+		{"none", "text"},
+		{"newline", "\ntext\n"},
+		{"indented", "\n\t\t\t\t\ttext\n\t\t\t\t"},
+		{"deeply indented", "\r\n" + strings.Repeat("  ", 24) + "text\r\n" + strings.Repeat("  ", 23)},
+	} {
+		text := []byte(in.text)
+		b.Run(in.name, func(b *testing.B) {
+			b.SetBytes(int64(len(text)))
+			for i := 0; i < b.N; i++ {
+				_ = xmltokenizer.TrimSpace(text)
+			}
+		})
+	}
+}
+
+func BenchmarkTokenDecoder(b *testing.B) {
+	path := filepath.Join("testdata", "ride_sembalun.gpx")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		panic(err)
+	}
+	var buf bytes.Buffer
+	if err := xmltokenizer.DumpBinary(&buf, bytes.NewReader(data)); err != nil {
+		panic(err)
+	}
+	cached := buf.Bytes()
+
+	b.Run("xmltokenizer", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_ = unmarshalWithXMLTokenizer(bytes.NewReader(data))
+		}
+	})
+	b.Run("binary", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			dec := xmltokenizer.NewTokenDecoder(bytes.NewReader(cached))
+			for {
+				if _, _, err := dec.Decode(); err != nil {
+					break
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,294 @@
+package xmltokenizer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+)
+
+// ErrInvalidBinary is returned when decoding bytes that are not a token, or a
+// stream of tokens, in the binary form, see Token.AppendBinary.
+const ErrInvalidBinary = errorString("invalid binary token")
+
+// binaryMagic starts a stream of tokens, its last byte is the format version.
+const binaryMagic = "xmlt\x04"
+
+// flags of a token in the binary form.
+const (
+	binarySelfClosing = 1 << iota
+	binaryEndElement
+	binaryContinued
+)
+
+// AppendBinary appends the binary form of t to b: its flags, Name, Data,
+// Attrs and positions, where every slice of bytes is prefixed by its length
+// as a varint, so it is decoded without reflection nor parsing, see
+// UnmarshalBinary. It never returns an error.
+func (t *Token) AppendBinary(b []byte) ([]byte, error) {
+	var flags uint64
+	if t.SelfClosing {
+		flags |= binarySelfClosing
+	}
+	if t.IsEndElement {
+		flags |= binaryEndElement
+	}
+	if t.Continued {
+		flags |= binaryContinued
+	}
+	b = binary.AppendUvarint(b, flags)
+	b = appendBinaryName(b, t.Name)
+	b = appendBinaryBytes(b, t.Data)
+	b = binary.AppendUvarint(b, uint64(len(t.Attrs)))
+	for i := range t.Attrs {
+		b = appendBinaryName(b, t.Attrs[i].Name)
+		b = appendBinaryBytes(b, t.Attrs[i].Value)
+		b = binary.AppendVarint(b, int64(t.Attrs[i].Index))
+	}
+	for _, p := range [...]Pos{t.Begin, t.End} {
+		b = binary.AppendUvarint(b, uint64(p.Line))
+		b = binary.AppendUvarint(b, uint64(p.Column))
+		b = binary.AppendUvarint(b, uint64(p.Offset))
+	}
+	return b, nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler, see AppendBinary.
+func (t *Token) MarshalBinary() ([]byte, error) {
+	return t.AppendBinary(nil)
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, it decodes the binary
+// form of a token in data into t, see AppendBinary. The bytes of t are copied
+// from data at once, and its Attrs reuse the memory of t's.
+func (t *Token) UnmarshalBinary(data []byte) error {
+	d := binaryDecoder{b: bytes.Clone(data)}
+	d.token(t)
+	if len(t.Attrs) == 0 {
+		t.Attrs = nil
+	}
+	if d.err == nil && len(d.b) > 0 {
+		d.err = ErrInvalidBinary
+	}
+	return d.err
+}
+
+func appendBinaryBytes(b, v []byte) []byte {
+	b = binary.AppendUvarint(b, uint64(len(v)))
+	return append(b, v...)
+}
+
+// appendBinaryName appends the Full name followed by the lengths of its
+// Prefix and Local, which are the head and the tail of the Full name, and
+// its Space.
+func appendBinaryName(b []byte, n Name) []byte {
+	b = appendBinaryBytes(b, n.Full)
+	b = binary.AppendUvarint(b, uint64(len(n.Prefix)))
+	b = binary.AppendUvarint(b, uint64(len(n.Local)))
+	return appendBinaryBytes(b, n.Space)
+}
+
+// binaryDecoder decodes the binary form in b, the decoded slices alias b.
+// Once an error occurs, it is latched and the subsequent values are zero.
+type binaryDecoder struct {
+	b   []byte
+	err error
+}
+
+func (d *binaryDecoder) uvarint() int {
+	v, n := binary.Uvarint(d.b)
+	if n <= 0 || v > math.MaxInt {
+		d.fail()
+		return 0
+	}
+	d.b = d.b[n:]
+	return int(v)
+}
+
+func (d *binaryDecoder) varint() int {
+	v, n := binary.Varint(d.b)
+	if n <= 0 || v < math.MinInt || v > math.MaxInt {
+		d.fail()
+		return 0
+	}
+	d.b = d.b[n:]
+	return int(v)
+}
+
+func (d *binaryDecoder) bytes() []byte {
+	n := d.uvarint()
+	if n > len(d.b) {
+		d.fail()
+		return nil
+	}
+	if n == 0 {
+		return nil
+	}
+	v := d.b[:n:n]
+	d.b = d.b[n:]
+	return v
+}
+
+func (d *binaryDecoder) name() Name {
+	full := d.bytes()
+	prefix, local := d.uvarint(), d.uvarint()
+	space := d.bytes()
+	if prefix > len(full) || local > len(full) {
+		d.fail()
+		return Name{}
+	}
+	n := Name{Full: full, Space: space}
+	if prefix > 0 {
+		n.Prefix = full[:prefix:prefix]
+	}
+	if local > 0 {
+		n.Local = full[len(full)-local:]
+	}
+	return n
+}
+
+func (d *binaryDecoder) token(t *Token) {
+	flags := d.uvarint()
+	t.SelfClosing = flags&binarySelfClosing != 0
+	t.IsEndElement = flags&binaryEndElement != 0
+	t.Continued = flags&binaryContinued != 0
+	t.Name = d.name()
+	t.Data = d.bytes()
+	n := d.uvarint()
+	if n > len(d.b) { // every attribute takes several bytes
+		d.fail()
+		n = 0
+	}
+	t.Attrs = t.Attrs[:0]
+	for i := 0; i < n; i++ {
+		t.Attrs = append(t.Attrs, Attr{Name: d.name(), Value: d.bytes(), Index: d.varint()})
+	}
+	for _, p := range [...]*Pos{&t.Begin, &t.End} {
+		p.Line, p.Column, p.Offset = d.uvarint(), d.uvarint(), d.uvarint()
+	}
+}
+
+func (d *binaryDecoder) fail() {
+	if d.err == nil {
+		d.err = ErrInvalidBinary
+	}
+	d.b = nil
+}
+
+// TokenEncoder writes a stream of tokens in the binary form, see
+// Token.AppendBinary, each along with its raw bytes, so the pre-tokenized
+// representation of a frequently parsed document can be cached and decoded by
+// a TokenDecoder faster than the XML is tokenized again.
+type TokenEncoder struct {
+	w      io.Writer
+	rec    []byte
+	buf    []byte
+	header bool
+}
+
+// NewTokenEncoder creates a TokenEncoder writing to w, one write per token.
+func NewTokenEncoder(w io.Writer) *TokenEncoder {
+	return &TokenEncoder{w: w}
+}
+
+// Encode writes token along with raw, its raw bytes such as returned by the
+// Tokenizer's Raw, which may be nil.
+func (e *TokenEncoder) Encode(token *Token, raw []byte) error {
+	e.rec, _ = token.AppendBinary(e.rec[:0])
+	e.rec = appendBinaryBytes(e.rec, raw)
+	e.buf = e.buf[:0]
+	if !e.header {
+		e.buf, e.header = append(e.buf, binaryMagic...), true
+	}
+	e.buf = appendBinaryBytes(e.buf, e.rec)
+	_, err := e.w.Write(e.buf)
+	return err
+}
+
+// TokenDecoder reads a stream of tokens written by a TokenEncoder.
+type TokenDecoder struct {
+	r      *bufio.Reader
+	buf    []byte
+	token  Token
+	header bool
+}
+
+// NewTokenDecoder creates a TokenDecoder reading from r.
+func NewTokenDecoder(r io.Reader) *TokenDecoder {
+	return &TokenDecoder{r: bufio.NewReader(r)}
+}
+
+// Decode returns the next token along with its raw bytes, or io.EOF once
+// there is none. The returned token and raw bytes are only valid before next
+// Decode invocation. A stream that is truncated or not written by a
+// TokenEncoder returns io.ErrUnexpectedEOF or ErrInvalidBinary.
+func (d *TokenDecoder) Decode() (token Token, raw []byte, err error) {
+	if !d.header {
+		var magic [len(binaryMagic)]byte
+		if _, err = io.ReadFull(d.r, magic[:]); err != nil {
+			if err == io.ErrUnexpectedEOF {
+				err = ErrInvalidBinary
+			}
+			return token, nil, err // io.EOF for an empty stream
+		}
+		if string(magic[:]) != binaryMagic {
+			return token, nil, ErrInvalidBinary
+		}
+		d.header = true
+	}
+
+	n, err := binary.ReadUvarint(d.r)
+	if err != nil {
+		return token, nil, err // io.EOF at the end, io.ErrUnexpectedEOF within the varint
+	}
+	if n > math.MaxInt32 {
+		return token, nil, ErrInvalidBinary
+	}
+	if int(n) > cap(d.buf) {
+		d.buf = make([]byte, n)
+	}
+	d.buf = d.buf[:n]
+	if _, err = io.ReadFull(d.r, d.buf); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return token, nil, err
+	}
+
+	dec := binaryDecoder{b: d.buf}
+	dec.token(&d.token)
+	raw = dec.bytes()
+	if dec.err == nil && len(dec.b) > 0 {
+		dec.err = ErrInvalidBinary
+	}
+	if dec.err != nil {
+		return token, nil, dec.err
+	}
+	token = d.token
+	if len(token.Attrs) == 0 {
+		token.Attrs = nil
+	}
+	return token, raw, nil
+}
+
+// DumpBinary tokenizes r and writes the tokens along with their raw bytes to
+// w in the binary form, see TokenEncoder. It stops on the first error other
+// than io.EOF.
+func DumpBinary(w io.Writer, r io.Reader, opts ...Option) error {
+	bw := bufio.NewWriter(w)
+	enc := NewTokenEncoder(bw)
+	tok := New(r, opts...)
+	for {
+		token, err := tok.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			bw.Flush()
+			return err
+		}
+		enc.Encode(&token, tok.Raw()) // a write error is returned by Flush
+	}
+	return bw.Flush()
+}
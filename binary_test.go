@@ -0,0 +1,127 @@
+package xmltokenizer_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/muktihari/xmltokenizer"
+)
+
+func TestTokenMarshalBinary(t *testing.T) {
+	tt := []xmltokenizer.Token{
+		{},
+		{
+			Name: xmltokenizer.Name{Prefix: []byte("gpxtpx"), Local: []byte("atemp"), Full: []byte("gpxtpx:atemp")},
+			Data: []byte("26.0"),
+			Attrs: []xmltokenizer.Attr{
+				{Name: xmltokenizer.Name{Local: []byte("a"), Full: []byte("a")}, Value: []byte("1")},
+				{Name: xmltokenizer.Name{Prefix: []byte("x"), Local: []byte("b"), Full: []byte("x:b")}, Value: []byte(""), Index: 1},
+				{Name: xmltokenizer.Name{Local: []byte("c"), Full: []byte("c")}, Value: []byte("added"), Index: -1},
+			},
+			Begin: xmltokenizer.Pos{Line: 10, Column: 3, Offset: 120},
+			End:   xmltokenizer.Pos{Line: 10, Column: 30, Offset: 147},
+		},
+		{Data: []byte(`<?xml version="1.0"?>`), SelfClosing: true},
+		{Name: xmltokenizer.Name{Local: []byte("a"), Full: []byte("a")}, IsEndElement: true},
+		{Data: []byte("continued"), Continued: true},
+	}
+
+	for i, token := range tt {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			b, err := token.MarshalBinary()
+			if err != nil {
+				t.Fatalf("expected nil, got: %v", err)
+			}
+			var got xmltokenizer.Token
+			if err := got.UnmarshalBinary(b); err != nil {
+				t.Fatalf("expected nil, got: %v", err)
+			}
+			if expected, got := fmt.Sprintf("%#v", token), fmt.Sprintf("%#v", got); got != expected {
+				t.Fatalf("expected: %s, got: %s", expected, got)
+			}
+			if token.Begin != got.Begin || token.End != got.End {
+				t.Fatalf("expected positions: %v %v, got: %v %v", token.Begin, token.End, got.Begin, got.End)
+			}
+
+			for n := 0; n < len(b); n++ {
+				if err := new(xmltokenizer.Token).UnmarshalBinary(b[:n]); !errors.Is(err, xmltokenizer.ErrInvalidBinary) {
+					t.Fatalf("truncated to %d bytes: expected error: %v, got: %v", n, xmltokenizer.ErrInvalidBinary, err)
+				}
+			}
+		})
+	}
+}
+
+func TestDumpBinary(t *testing.T) {
+	filenames, err := filepath.Glob("testdata/*.*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, filename := range filenames {
+		t.Run(filename, func(t *testing.T) {
+			f, err := os.ReadFile(filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var buf bytes.Buffer
+			if err := xmltokenizer.DumpBinary(&buf, bytes.NewReader(f)); err != nil {
+				t.Fatalf("expected nil, got: %v", err)
+			}
+
+			tok := xmltokenizer.New(bytes.NewReader(f))
+			dec := xmltokenizer.NewTokenDecoder(&buf)
+			for {
+				expected, err := tok.Token()
+				token, raw, decErr := dec.Decode()
+				if err != decErr {
+					t.Fatalf("expected error: %v, got: %v", err, decErr)
+				}
+				if err == io.EOF {
+					break
+				}
+				if !expected.Equal(token) || expected.Begin != token.Begin || expected.End != token.End {
+					t.Fatalf("expected: %#v, got: %#v", expected, token)
+				}
+				if !bytes.Equal(raw, tok.Raw()) {
+					t.Fatalf("expected raw: %q, got: %q", tok.Raw(), raw)
+				}
+			}
+		})
+	}
+}
+
+func TestTokenDecoderErrors(t *testing.T) {
+	var buf bytes.Buffer
+	enc := xmltokenizer.NewTokenEncoder(&buf)
+	token := xmltokenizer.Token{Name: xmltokenizer.Name{Local: []byte("a"), Full: []byte("a")}}
+	if err := enc.Encode(&token, []byte("<a>")); err != nil {
+		t.Fatalf("expected nil, got: %v", err)
+	}
+	stream := buf.Bytes()
+
+	tt := []struct {
+		name string
+		in   []byte
+		err  error
+	}{
+		{name: "empty", in: nil, err: io.EOF},
+		{name: "not a stream", in: []byte("<a/>\n"), err: xmltokenizer.ErrInvalidBinary},
+		{name: "truncated header", in: stream[:3], err: xmltokenizer.ErrInvalidBinary},
+		{name: "truncated token", in: stream[:len(stream)-1], err: io.ErrUnexpectedEOF},
+		{name: "corrupted token", in: append(stream[:len(stream)-4:len(stream)-4], 5, '<', 'a', '>'), err: xmltokenizer.ErrInvalidBinary},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			_, _, err := xmltokenizer.NewTokenDecoder(bytes.NewReader(tc.in)).Decode()
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expected error: %v, got: %v", tc.err, err)
+			}
+		})
+	}
+}
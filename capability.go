@@ -0,0 +1,111 @@
+package xmltokenizer
+
+import (
+	"fmt"
+	"math/bits"
+	"strings"
+)
+
+// ErrMissingCapabilities is returned by Require when a Tokenizer lacks some of
+// the required Capabilities.
+const ErrMissingCapabilities = errorString("missing capabilities")
+
+// Capabilities is a set of optional behaviors of a Tokenizer, mostly enabled
+// by options, so a library accepting a Tokenizer created by its caller can
+// verify the behaviors it relies on, see Tokenizer.Capabilities and Require.
+type Capabilities uint32
+
+const (
+	CapSpace               Capabilities = 1 << iota // Space returns the skipped bytes, see WithSpace.
+	CapChunkedCharData                              // Long CharData is split into Continued tokens, see WithChunkedCharData.
+	CapCaseFold                                     // Names are lowercased, see WithCaseFold.
+	CapPersistentTokens                             // Tokens remain valid after the next Token, see WithPersistentTokens.
+	CapNamespaceAliases                             // Names are prefixed by namespace aliases, see WithNamespaceAliases.
+	CapNamespaceCheck                               // Namespace constraints are checked, see WithNamespaceCheck.
+	CapIllegalCharCheck                             // Illegal characters are rejected, see WithIllegalCharCheck.
+	CapAdaptiveBuffer                               // Reads and buffer are sized by the token sizes, see WithAdaptiveBuffer.
+	CapFixedBuffer                                  // The buffer never grows, see WithFixedBuffer.
+	CapCloseReader                                  // Close closes the io.Reader, see WithCloseReader.
+	CapRestore                                      // A Checkpoint can be restored, see New.
+	CapRanged                                       // Only the tokens within a byte range are read, see NewRanged.
+	CapNamespaceResolution                          // Names have their namespace URI, see WithNamespaceResolution.
+	CapUnescapeData                                 // Entities of Data and attribute values are decoded, see WithUnescapeData.
+)
+
+var capabilityNames = [...]string{
+	"Space",
+	"ChunkedCharData",
+	"CaseFold",
+	"PersistentTokens",
+	"NamespaceAliases",
+	"NamespaceCheck",
+	"IllegalCharCheck",
+	"AdaptiveBuffer",
+	"FixedBuffer",
+	"CloseReader",
+	"Restore",
+	"Ranged",
+	"NamespaceResolution",
+	"UnescapeData",
+}
+
+// Has reports whether c has every capability of want.
+func (c Capabilities) Has(want Capabilities) bool { return c&want == want }
+
+// String returns the names of the capabilities joined by "|", e.g.
+// "Space|CaseFold", or "0" if there is none.
+func (c Capabilities) String() string {
+	if c == 0 {
+		return "0"
+	}
+	var names []string
+	for rest := c; rest != 0; rest &= rest - 1 {
+		i := bits.TrailingZeros32(uint32(rest))
+		if i < len(capabilityNames) {
+			names = append(names, capabilityNames[i])
+		} else {
+			names = append(names, fmt.Sprintf("Capabilities(1<<%d)", i))
+		}
+	}
+	return strings.Join(names, "|")
+}
+
+// Capabilities returns the optional behaviors active on t.
+func (t *Tokenizer) Capabilities() Capabilities {
+	var c Capabilities
+	for _, b := range [...]struct {
+		active bool
+		c      Capabilities
+	}{
+		{t.options.space, CapSpace},
+		{t.options.chunkedCharData, CapChunkedCharData},
+		{t.options.caseFold, CapCaseFold},
+		{t.options.persistentTokens, CapPersistentTokens},
+		{t.options.namespaceAliases != nil, CapNamespaceAliases},
+		{t.options.namespaceCheck, CapNamespaceCheck},
+		{t.options.illegalCharCheck, CapIllegalCharCheck},
+		{t.options.adaptiveBuffer, CapAdaptiveBuffer},
+		{t.options.fixedBuffer != nil, CapFixedBuffer},
+		{t.options.closeReader, CapCloseReader},
+		{t.ra != nil, CapRestore},
+		{t.rangeEnd > 0, CapRanged},
+		{t.options.namespaceResolution, CapNamespaceResolution},
+		{t.options.unescapeData, CapUnescapeData},
+	} {
+		if b.active {
+			c |= b.c
+		}
+	}
+	return c
+}
+
+// Require returns an error wrapping ErrMissingCapabilities naming the
+// capabilities of want that t lacks, if any, so a library can reject a
+// Tokenizer that is not configured as it expects upfront rather than
+// misbehaving later.
+func (t *Tokenizer) Require(want Capabilities) error {
+	if missing := want &^ t.Capabilities(); missing != 0 {
+		return fmt.Errorf("%s: %w", missing, ErrMissingCapabilities)
+	}
+	return nil
+}
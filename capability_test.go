@@ -0,0 +1,85 @@
+package xmltokenizer_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/muktihari/xmltokenizer"
+)
+
+func TestCapabilities(t *testing.T) {
+	r := func() io.Reader { return struct{ io.Reader }{strings.NewReader("<a/>")} } // neither io.ReaderAt nor io.Seeker
+
+	tt := []struct {
+		name     string
+		tok      *xmltokenizer.Tokenizer
+		expected xmltokenizer.Capabilities
+	}{
+		{
+			name:     "default",
+			tok:      xmltokenizer.New(r()),
+			expected: 0,
+		},
+		{
+			name: "options",
+			tok: xmltokenizer.New(r(),
+				xmltokenizer.WithSpace(),
+				xmltokenizer.WithChunkedCharData(),
+				xmltokenizer.WithNamespaceAliases(map[string]string{}),
+			),
+			expected: xmltokenizer.CapSpace | xmltokenizer.CapChunkedCharData | xmltokenizer.CapNamespaceAliases,
+		},
+		{
+			name:     "reader at and seeker",
+			tok:      xmltokenizer.New(bytes.NewReader([]byte("<a/>"))),
+			expected: xmltokenizer.CapRestore,
+		},
+		{
+			name:     "ranged",
+			tok:      xmltokenizer.NewRanged(bytes.NewReader([]byte("<a/><b/>")), 8, 0, 4, xmltokenizer.WithCaseFold()),
+			expected: xmltokenizer.CapCaseFold | xmltokenizer.CapRestore | xmltokenizer.CapRanged,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if c := tc.tok.Capabilities(); c != tc.expected {
+				t.Fatalf("expected: %v, got: %v", tc.expected, c)
+			}
+		})
+	}
+}
+
+func TestTokenizerRequire(t *testing.T) {
+	tok := xmltokenizer.New(strings.NewReader("<a/>"), xmltokenizer.WithSpace())
+	if err := tok.Require(xmltokenizer.CapSpace); err != nil {
+		t.Fatalf("expected nil, got: %v", err)
+	}
+	err := tok.Require(xmltokenizer.CapSpace | xmltokenizer.CapNamespaceCheck | xmltokenizer.CapChunkedCharData)
+	if !errors.Is(err, xmltokenizer.ErrMissingCapabilities) {
+		t.Fatalf("expected error: %v, got: %v", xmltokenizer.ErrMissingCapabilities, err)
+	}
+	if expected := "ChunkedCharData|NamespaceCheck: missing capabilities"; err.Error() != expected {
+		t.Fatalf("expected: %q, got: %q", expected, err.Error())
+	}
+}
+
+func TestCapabilitiesString(t *testing.T) {
+	tt := []struct {
+		c        xmltokenizer.Capabilities
+		expected string
+	}{
+		{c: 0, expected: "0"},
+		{c: xmltokenizer.CapSpace, expected: "Space"},
+		{c: xmltokenizer.CapRanged | xmltokenizer.CapSpace, expected: "Space|Ranged"},
+		{c: 1 << 31, expected: "Capabilities(1<<31)"},
+	}
+	for _, tc := range tt {
+		if s := tc.c.String(); s != tc.expected {
+			t.Fatalf("expected: %q, got: %q", tc.expected, s)
+		}
+	}
+}
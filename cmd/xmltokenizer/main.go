@@ -0,0 +1,105 @@
+// Command xmltokenizer inspects XML documents using the xmltokenizer package.
+//
+// Usage:
+//
+//	xmltokenizer dump [-jsonl] [file ...]
+//	xmltokenizer preview [-depth n] [-text n] [file ...]
+//
+// The dump command prints the tokens of the files, or of the standard input
+// if there is none, one per line in the form of xmltokenizer.Dump, or as
+// JSON objects in the form of xmltokenizer.DumpJSONL with -jsonl, e.g.
+//
+//	xmltokenizer dump -jsonl track.gpx | jq 'select(.name == "trkpt")'
+//
+// The preview command prints the files, or the standard input, indented one
+// tag per line in the form of xmltokenizer.Preview, summarizing the elements
+// deeper than -depth and eliding the texts longer than -text bytes.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/muktihari/xmltokenizer"
+)
+
+const usage = `usage: xmltokenizer <command> [flags] [file ...]
+
+commands:
+  dump    print the tokens of the files or the standard input
+  preview print an indented preview of the files or the standard input
+`
+
+var errUsage = errors.New("invalid usage")
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr); err != nil {
+		if err != errUsage {
+			fmt.Fprintf(os.Stderr, "xmltokenizer: %v\n", err)
+		}
+		os.Exit(2)
+	}
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return errUsage
+	}
+	switch args[0] {
+	case "dump":
+		return dump(args[1:], stdin, stdout, stderr)
+	case "preview":
+		return preview(args[1:], stdin, stdout, stderr)
+	}
+	fmt.Fprint(stderr, usage)
+	return errUsage
+}
+
+func dump(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("dump", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	jsonl := fs.Bool("jsonl", false, "print one JSON object per token")
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	fn := xmltokenizer.Dump
+	if *jsonl {
+		fn = xmltokenizer.DumpJSONL
+	}
+	return eachInput(fs.Args(), stdin, func(r io.Reader) error { return fn(stdout, r) })
+}
+
+func preview(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("preview", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var po xmltokenizer.PreviewOptions
+	fs.IntVar(&po.MaxDepth, "depth", 0, "summarize the elements deeper than `n`, 0 for no limit")
+	fs.IntVar(&po.MaxText, "text", 0, "elide the texts longer than `n` bytes, 0 for no limit")
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	return eachInput(fs.Args(), stdin, func(r io.Reader) error { return xmltokenizer.Preview(stdout, r, po) })
+}
+
+// eachInput invokes fn with every file named, or with stdin if there is none.
+func eachInput(names []string, stdin io.Reader, fn func(r io.Reader) error) error {
+	if len(names) == 0 {
+		return fn(stdin)
+	}
+	for _, name := range names {
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		err = fn(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	tt := []struct {
+		name     string
+		args     []string
+		stdin    string
+		expected string
+		err      error
+	}{
+		{
+			name:     "dump",
+			args:     []string{"dump"},
+			stdin:    `<a/>`,
+			expected: "1:1\t1:5\tSelfClosing\ta\t\"\"\n",
+		},
+		{
+			name:     "dump jsonl",
+			args:     []string{"dump", "-jsonl"},
+			stdin:    `<a/>`,
+			expected: `{"kind":"SelfClosing","name":"a","selfClosing":true,"begin":{"line":1,"column":1,"offset":0},"end":{"line":1,"column":5,"offset":4}}` + "\n",
+		},
+		{
+			name:  "dump file",
+			args:  []string{"dump", "-jsonl", "../../testdata/cdata.xml"},
+			stdin: `ignored`,
+		},
+		{
+			name:     "preview",
+			args:     []string{"preview", "-depth", "1", "-text", "2"},
+			stdin:    `<a k="value"><b/></a>`,
+			expected: "<a k=\"va...(3 bytes elided)\">\n  <!-- 1 elements and 0 bytes of text elided -->\n</a>\n",
+		},
+		{name: "no command", err: errUsage},
+		{name: "unknown command", args: []string{"nope"}, err: errUsage},
+		{name: "unknown flag", args: []string{"dump", "-nope"}, err: errUsage},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			err := run(tc.args, strings.NewReader(tc.stdin), &stdout, &stderr)
+			if err != tc.err {
+				t.Fatalf("expected error: %v, got: %v", tc.err, err)
+			}
+			if tc.expected != "" && stdout.String() != tc.expected {
+				t.Fatalf("expected: %q, got: %q", tc.expected, stdout.String())
+			}
+			if tc.err == nil && stdout.Len() == 0 {
+				t.Fatalf("expected output, got none")
+			}
+		})
+	}
+}
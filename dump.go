@@ -0,0 +1,79 @@
+package xmltokenizer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// dumpDataLimit is the maximum number of Data bytes printed by Dump.
+const dumpDataLimit = 48
+
+// Dump tokenizes r and prints one line per token to w in the following form:
+//
+//	begin	end	kind	name	data
+//
+// where begin and end are the token's positions in "line:column" form, kind
+// is the Tokenizer's Kind and data is a quoted and truncated token's Data. It stops on the first error
+// other than io.EOF. Dump is intended for debugging, the output format is not
+// guaranteed to be stable.
+func Dump(w io.Writer, r io.Reader, opts ...Option) error {
+	bw := bufio.NewWriter(w)
+	tok := New(r, opts...)
+	for {
+		token, err := tok.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			bw.Flush()
+			return err
+		}
+		data, ellipsis := token.Data, ""
+		if len(data) > dumpDataLimit {
+			data, ellipsis = data[:dumpDataLimit], "..."
+		}
+		fmt.Fprintf(bw, "%d:%d\t%d:%d\t%s\t%s\t%q%s\n",
+			token.Begin.Line, token.Begin.Column,
+			token.End.Line, token.End.Column,
+			tok.Kind(), token.Name.Full, data, ellipsis)
+	}
+	return bw.Flush()
+}
+
+// DumpJSONL tokenizes r and writes one JSON object per line to w, so token
+// streams can be inspected with tools such as jq. Each object is the token's
+// JSON representation, see Token.MarshalJSON, with its kind added, e.g.
+//
+//	{"kind":"StartElement","name":"trkpt","attrs":[{"name":"lat","value":"47.1"}],"begin":{...},"end":{...}}
+//
+// where kind is the Tokenizer's Kind. Unlike Dump, Data is not truncated.
+// It stops on the first error other than io.EOF.
+func DumpJSONL(w io.Writer, r io.Reader, opts ...Option) error {
+	bw := bufio.NewWriter(w)
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	tok := New(r, opts...)
+	for {
+		token, err := tok.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			bw.Flush()
+			return err
+		}
+		buf.Reset()
+		if err = enc.Encode(token); err != nil {
+			bw.Flush()
+			return err
+		}
+		// Splice the kind in as the first member of the object.
+		fmt.Fprintf(bw, "{\"kind\":%q,", tok.Kind())
+		bw.Write(buf.Bytes()[1:])
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,82 @@
+package xmltokenizer_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/muktihari/xmltokenizer"
+)
+
+func TestDump(t *testing.T) {
+	tt := []struct {
+		name     string
+		xml      string
+		expected string
+		err      error
+	}{
+		{
+			name: "all kinds",
+			xml: `<?xml version="1.0"?>
+<!DOCTYPE note>
+<!-- comment -->
+<note id="1">
+  <to>` + strings.Repeat("a", 50) + `</to>
+  <empty/>
+</note>`,
+			expected: "1:1\t1:22\tProcInst\t\t\"<?xml version=\\\"1.0\\\"?>\"\n" +
+				"2:1\t2:16\tDirective\t\t\"<!DOCTYPE note>\"\n" +
+				"3:1\t3:17\tComment\t\t\"<!-- comment -->\"\n" +
+				"4:1\t4:14\tStartElement\tnote\t\"\"\n" +
+				"5:3\t5:57\tStartElement\tto\t\"" + strings.Repeat("a", 48) + "\"...\n" +
+				"5:57\t5:62\tEndElement\tto\t\"\"\n" +
+				"6:3\t6:11\tSelfClosing\tempty\t\"\"\n" +
+				"7:1\t7:8\tEndElement\tnote\t\"\"\n",
+		},
+		{
+			name:     "truncated",
+			xml:      `<a><b`,
+			expected: "1:1\t1:4\tStartElement\ta\t\"\"\n",
+			err:      io.ErrUnexpectedEOF,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := xmltokenizer.Dump(&buf, strings.NewReader(tc.xml))
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expected error: %v, got: %v", tc.err, err)
+			}
+			if diff := cmp.Diff(buf.String(), tc.expected); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
+
+func TestDumpJSONL(t *testing.T) {
+	const xml = `<!-- c --><a x="1">text</a><b/>`
+	var buf bytes.Buffer
+	if err := xmltokenizer.DumpJSONL(&buf, strings.NewReader(xml)); err != nil {
+		t.Fatalf("expected nil, got: %v", err)
+	}
+	expected := `{"kind":"Comment","data":"<!-- c -->","selfClosing":true,"begin":{"line":1,"column":1,"offset":0},"end":{"line":1,"column":11,"offset":10}}` + "\n" +
+		`{"kind":"StartElement","name":"a","attrs":[{"name":"x","value":"1"}],"data":"text","begin":{"line":1,"column":11,"offset":10},"end":{"line":1,"column":24,"offset":23}}` + "\n" +
+		`{"kind":"EndElement","name":"a","isEndElement":true,"begin":{"line":1,"column":24,"offset":23},"end":{"line":1,"column":28,"offset":27}}` + "\n" +
+		`{"kind":"SelfClosing","name":"b","selfClosing":true,"begin":{"line":1,"column":28,"offset":27},"end":{"line":1,"column":32,"offset":31}}` + "\n"
+	if diff := cmp.Diff(buf.String(), expected); diff != "" {
+		t.Fatal(diff)
+	}
+
+	buf.Reset()
+	if err := xmltokenizer.DumpJSONL(&buf, strings.NewReader(`<a><b`)); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("expected error: %v, got: %v", io.ErrUnexpectedEOF, err)
+	}
+	if n := strings.Count(buf.String(), "\n"); n != 1 {
+		t.Fatalf("expected lines: 1, got: %d", n)
+	}
+}
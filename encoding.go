@@ -0,0 +1,261 @@
+package xmltokenizer
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// sniffLimit is the number of leading bytes inspected by NewUTF8Reader.
+const sniffLimit = 1024
+
+const errUnsupportedEncoding = errorString("unsupported encoding")
+
+// DetectEncoding detects the encoding of a document from its leading bytes,
+// following the XML 1.0 Appendix F algorithm: the byte order mark, the byte
+// patterns of "<?" in UTF-16 and UCS-4 without byte order mark, the encoding
+// declaration "<?xml ... encoding=...?>" and finally the HTML
+// <meta charset=...> or <meta http-equiv ... content="...; charset=...">.
+// The returned name is lowercased, "utf-8" is returned when nothing is found.
+func DetectEncoding(head []byte) string {
+	switch {
+	case bytes.HasPrefix(head, []byte{0xEF, 0xBB, 0xBF}):
+		return "utf-8"
+	case bytes.HasPrefix(head, []byte{0x00, 0x00, 0xFE, 0xFF}),
+		bytes.HasPrefix(head, []byte{0x00, 0x00, 0x00, '<'}):
+		return "ucs-4be"
+	case bytes.HasPrefix(head, []byte{0xFF, 0xFE, 0x00, 0x00}),
+		bytes.HasPrefix(head, []byte{'<', 0x00, 0x00, 0x00}):
+		return "ucs-4le"
+	case bytes.HasPrefix(head, []byte{0xFE, 0xFF}),
+		bytes.HasPrefix(head, []byte{0x00, '<', 0x00, '?'}):
+		return "utf-16be"
+	case bytes.HasPrefix(head, []byte{0xFF, 0xFE}),
+		bytes.HasPrefix(head, []byte{'<', 0x00, '?', 0x00}):
+		return "utf-16le"
+	}
+	if enc := declaredEncoding(head); enc != "" {
+		return enc
+	}
+	if enc := metaCharset(head); enc != "" {
+		return enc
+	}
+	return "utf-8"
+}
+
+// declaredEncoding returns the lowercased value of the encoding
+// pseudo-attribute of the XML declaration in head, if any.
+func declaredEncoding(head []byte) string {
+	if !bytes.HasPrefix(head, []byte("<?xml")) {
+		return ""
+	}
+	end := bytes.Index(head, []byte("?>"))
+	if end == -1 {
+		return ""
+	}
+	return string(bytes.ToLower(pseudoAttr(head[5:end], "encoding")))
+}
+
+// metaCharset returns the lowercased charset declared by an
+// HTML meta element in head, if any.
+func metaCharset(head []byte) string {
+	lower := bytes.ToLower(head)
+	for i := 0; ; {
+		p := bytes.Index(lower[i:], []byte("<meta"))
+		if p == -1 {
+			return ""
+		}
+		i += p + len("<meta")
+		end := bytes.IndexByte(lower[i:], '>')
+		if end == -1 {
+			return ""
+		}
+		tag := lower[i : i+end]
+		if charset := htmlAttr(tag, "charset"); charset != nil {
+			return string(charset)
+		}
+		if content := htmlAttr(tag, "content"); content != nil {
+			if p := bytes.Index(content, []byte("charset=")); p != -1 {
+				return string(bytes.TrimSpace(content[p+len("charset="):]))
+			}
+		}
+		i += end
+	}
+}
+
+// pseudoAttr returns the value of the attribute named name in b, which
+// holds the attributes of a tag, or nil if it is not found.
+func pseudoAttr(b []byte, name string) []byte {
+	for {
+		attr, n, ok := nextAttr(b)
+		if !ok {
+			return nil
+		}
+		if string(attr.Name.Full) == name {
+			return attr.Value
+		}
+		b = b[n:]
+	}
+}
+
+// htmlAttr returns the value of the attribute named name in b, which holds
+// the attributes of an HTML tag, or nil if it is not found. Unlike
+// pseudoAttr, the value may be unquoted, e.g. <meta charset=utf-8>, it is
+// then terminated by whitespace, "/" or ">".
+func htmlAttr(b []byte, name string) []byte {
+	for {
+		b = TrimLeftSpace(b)
+		if len(b) == 0 {
+			return nil
+		}
+		n := indexAnyOrLen(b, " \t\r\n=/>")
+		if n == 0 { // stray "=", "/" or ">"
+			b = b[1:]
+			continue
+		}
+		attr := b[:n]
+		b = TrimLeftSpace(b[n:])
+		if len(b) == 0 || b[0] != '=' {
+			continue // without value
+		}
+		b = TrimLeftSpace(b[1:])
+
+		var value []byte
+		switch {
+		case len(b) > 0 && (b[0] == '"' || b[0] == '\''):
+			end := indexAnyOrLen(b[1:], string(b[0]))
+			value, b = b[1:1+end], b[min(2+end, len(b)):]
+		default:
+			end := indexAnyOrLen(b, " \t\r\n/>")
+			value, b = b[:end], b[end:]
+		}
+		if string(attr) == name {
+			return value
+		}
+	}
+}
+
+// NewUTF8Reader detects the encoding of the document in r using DetectEncoding
+// on its first bytes and returns a reader producing it in UTF-8 without the
+// byte order mark, along with the detected encoding. UTF-8, US-ASCII, UTF-16
+// and ISO-8859-1 are supported natively, any other encoding is delegated to
+// charsetReader, if not nil, otherwise an error is returned.
+func NewUTF8Reader(r io.Reader, charsetReader func(charset string, input io.Reader) (io.Reader, error)) (io.Reader, string, error) {
+	return newUTF8Reader(r, "", charsetReader)
+}
+
+// NewCharsetReader is like NewUTF8Reader but the document in r is known to be
+// encoded in charset, e.g. declared by the Content-Type of an HTTP response,
+// which takes precedence over the document's own declaration. The encoding is
+// detected when charset is empty.
+func NewCharsetReader(r io.Reader, charset string, charsetReader func(charset string, input io.Reader) (io.Reader, error)) (io.Reader, error) {
+	rd, _, err := newUTF8Reader(r, strings.ToLower(strings.TrimSpace(charset)), charsetReader)
+	return rd, err
+}
+
+func newUTF8Reader(r io.Reader, enc string, charsetReader func(charset string, input io.Reader) (io.Reader, error)) (io.Reader, string, error) {
+	br := bufio.NewReaderSize(r, sniffLimit)
+	head, err := br.Peek(sniffLimit)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, "", err
+	}
+
+	if enc == "" {
+		enc = DetectEncoding(head)
+	}
+	switch enc {
+	case "utf-8", "utf8", "us-ascii", "ascii":
+		if bytes.HasPrefix(head, []byte{0xEF, 0xBB, 0xBF}) {
+			br.Discard(3)
+		}
+		return br, enc, nil
+	case "utf-16", "utf-16le", "utf-16be":
+		le := bytes.HasPrefix(head, []byte{0xFF, 0xFE}) || bytes.HasPrefix(head, []byte{'<', 0x00})
+		if bytes.HasPrefix(head, []byte{0xFF, 0xFE}) || bytes.HasPrefix(head, []byte{0xFE, 0xFF}) {
+			br.Discard(2)
+		}
+		return &transcoder{r: br, decode: utf16Decoder(le)}, enc, nil
+	case "iso-8859-1", "latin1", "l1":
+		return &transcoder{r: br, decode: latin1Decoder}, enc, nil
+	}
+	if charsetReader == nil {
+		return nil, enc, fmt.Errorf("%q: %w", enc, errUnsupportedEncoding)
+	}
+	rd, err := charsetReader(enc, br)
+	return rd, enc, err
+}
+
+// transcoder is an io.Reader converting the bytes of r into UTF-8 using decode,
+// which appends the decoded src to dst and returns the number of bytes consumed.
+type transcoder struct {
+	r      io.Reader
+	decode func(dst, src []byte, eof bool) ([]byte, int)
+	in     []byte // bytes read but not decoded yet
+	out    []byte // bytes decoded but not returned yet
+	err    error
+}
+
+func (t *transcoder) Read(p []byte) (int, error) {
+	for len(t.out) == 0 {
+		if t.err != nil {
+			return 0, t.err
+		}
+		var buf [sniffLimit]byte
+		n, err := t.r.Read(buf[:])
+		t.in = append(t.in, buf[:n]...)
+		t.err = err
+		var consumed int
+		t.out, consumed = t.decode(t.out[:0], t.in, err != nil)
+		t.in = append(t.in[:0], t.in[consumed:]...)
+	}
+	n := copy(p, t.out)
+	t.out = t.out[n:]
+	return n, nil
+}
+
+func utf16Decoder(le bool) func(dst, src []byte, eof bool) ([]byte, int) {
+	return func(dst, src []byte, eof bool) ([]byte, int) {
+		var i int
+		for ; i+1 < len(src); i += 2 {
+			u := rune(src[i])<<8 | rune(src[i+1])
+			if le {
+				u = rune(src[i+1])<<8 | rune(src[i])
+			}
+			if utf16.IsSurrogate(u) {
+				if i+3 >= len(src) && !eof {
+					break // wait for the rest of the surrogate pair
+				}
+				var u2 rune = utf8.RuneError
+				if i+3 < len(src) {
+					u2 = rune(src[i+2])<<8 | rune(src[i+3])
+					if le {
+						u2 = rune(src[i+3])<<8 | rune(src[i+2])
+					}
+				}
+				if r := utf16.DecodeRune(u, u2); r != utf8.RuneError {
+					dst = utf8.AppendRune(dst, r)
+					i += 2
+					continue
+				}
+				u = utf8.RuneError
+			}
+			dst = utf8.AppendRune(dst, u)
+		}
+		if eof && i < len(src) {
+			dst = utf8.AppendRune(dst, utf8.RuneError)
+			i = len(src)
+		}
+		return dst, i
+	}
+}
+
+func latin1Decoder(dst, src []byte, eof bool) ([]byte, int) {
+	for _, c := range src {
+		dst = utf8.AppendRune(dst, rune(c))
+	}
+	return dst, len(src)
+}
@@ -0,0 +1,179 @@
+package xmltokenizer_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"unicode/utf16"
+
+	"github.com/muktihari/xmltokenizer"
+)
+
+func encodeUTF16(s string, le, bom bool) []byte {
+	var b []byte
+	units := utf16.Encode([]rune(s))
+	if bom {
+		units = append([]uint16{0xFEFF}, units...)
+	}
+	for _, u := range units {
+		if le {
+			b = append(b, byte(u), byte(u>>8))
+		} else {
+			b = append(b, byte(u>>8), byte(u))
+		}
+	}
+	return b
+}
+
+func TestDetectEncoding(t *testing.T) {
+	tt := []struct {
+		name     string
+		head     []byte
+		expected string
+	}{
+		{name: "empty", head: nil, expected: "utf-8"},
+		{name: "utf-8 bom", head: []byte("\xEF\xBB\xBF<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?>"), expected: "utf-8"},
+		{name: "utf-16le bom", head: encodeUTF16("<a/>", true, true), expected: "utf-16le"},
+		{name: "utf-16be bom", head: encodeUTF16("<a/>", false, true), expected: "utf-16be"},
+		{name: "utf-16le pattern", head: encodeUTF16("<?xml?>", true, false), expected: "utf-16le"},
+		{name: "utf-16be pattern", head: encodeUTF16("<?xml?>", false, false), expected: "utf-16be"},
+		{name: "ucs-4be pattern", head: []byte{0, 0, 0, '<', 0, 0, 0, '?'}, expected: "ucs-4be"},
+		{name: "declaration", head: []byte(`<?xml version="1.0" encoding='Windows-1252'?><a/>`), expected: "windows-1252"},
+		{name: "declaration without encoding", head: []byte(`<?xml version="1.0"?><a/>`), expected: "utf-8"},
+		{name: "meta charset", head: []byte(`<html><head><META Charset="Shift_JIS">`), expected: "shift_jis"},
+		{name: "meta charset unquoted", head: []byte(`<html><head><meta charset=utf-8><title>`), expected: "utf-8"},
+		{name: "meta charset unquoted self-closing", head: []byte(`<meta name=x charset=Windows-1252/>`), expected: "windows-1252"},
+		{name: "meta http-equiv", head: []byte(`<meta http-equiv="Content-Type" content="text/html; charset=euc-jp">`), expected: "euc-jp"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if enc := xmltokenizer.DetectEncoding(tc.head); enc != tc.expected {
+				t.Fatalf("expected: %q, got: %q", tc.expected, enc)
+			}
+		})
+	}
+}
+
+func TestNewUTF8Reader(t *testing.T) {
+	const doc = `<?xml version="1.0"?><a x="é">😀 text</a>`
+
+	tt := []struct {
+		name          string
+		in            []byte
+		charsetReader func(charset string, input io.Reader) (io.Reader, error)
+		expected      string
+		expectedEnc   string
+		err           error
+	}{
+		{name: "utf-8 bom", in: append([]byte("\xEF\xBB\xBF"), doc...), expected: doc, expectedEnc: "utf-8"},
+		{name: "utf-16le bom", in: encodeUTF16(doc, true, true), expected: doc, expectedEnc: "utf-16le"},
+		{name: "utf-16be", in: encodeUTF16(doc, false, false), expected: doc, expectedEnc: "utf-16be"},
+		{
+			name:        "latin1",
+			in:          []byte("<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?><a>caf\xE9</a>"),
+			expected:    "<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?><a>café</a>",
+			expectedEnc: "iso-8859-1",
+		},
+		{
+			name:        "unsupported",
+			in:          []byte(`<?xml version="1.0" encoding="EBCDIC"?><a/>`),
+			expectedEnc: "ebcdic",
+			err:         errors.New("unsupported encoding"),
+		},
+		{
+			name: "charset reader",
+			in:   []byte(`<?xml version="1.0" encoding="x-upper"?><a/>`),
+			charsetReader: func(charset string, input io.Reader) (io.Reader, error) {
+				b, err := io.ReadAll(input)
+				return bytes.NewReader(bytes.ToUpper(b)), err
+			},
+			expected:    `<?XML VERSION="1.0" ENCODING="X-UPPER"?><A/>`,
+			expectedEnc: "x-upper",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			r, enc, err := xmltokenizer.NewUTF8Reader(iotest.OneByteReader(bytes.NewReader(tc.in)), tc.charsetReader)
+			if enc != tc.expectedEnc {
+				t.Fatalf("expected encoding: %q, got: %q", tc.expectedEnc, enc)
+			}
+			if (err == nil) != (tc.err == nil) {
+				t.Fatalf("expected error: %v, got: %v", tc.err, err)
+			}
+			if err != nil {
+				return
+			}
+			b, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatalf("expected nil, got: %v", err)
+			}
+			if string(b) != tc.expected {
+				t.Fatalf("expected: %q, got: %q", tc.expected, b)
+			}
+		})
+	}
+
+	t.Run("tokenize utf-16", func(t *testing.T) {
+		r, _, err := xmltokenizer.NewUTF8Reader(bytes.NewReader(encodeUTF16(doc, true, true)), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tok := xmltokenizer.New(r)
+		var names []string
+		for {
+			token, err := tok.Token()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			names = append(names, string(token.Name.Full)+string(token.Data))
+		}
+		if got := strings.Join(names, ","); got != `<?xml version="1.0"?>,a😀 text,a` {
+			t.Fatalf("unexpected tokens: %q", got)
+		}
+	})
+}
+
+func TestNewCharsetReader(t *testing.T) {
+	tt := []struct {
+		name     string
+		in       []byte
+		charset  string
+		expected string
+	}{
+		{
+			name:     "charset overrides declaration",
+			in:       []byte("<?xml version=\"1.0\" encoding=\"UTF-8\"?><a>caf\xE9</a>"),
+			charset:  " ISO-8859-1",
+			expected: "<?xml version=\"1.0\" encoding=\"UTF-8\"?><a>café</a>",
+		},
+		{
+			name:     "empty charset is detected",
+			in:       encodeUTF16(`<a>café</a>`, true, true),
+			expected: `<a>café</a>`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := xmltokenizer.NewCharsetReader(bytes.NewReader(tc.in), tc.charset, nil)
+			if err != nil {
+				t.Fatalf("expected nil, got: %v", err)
+			}
+			b, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatalf("expected nil, got: %v", err)
+			}
+			if string(b) != tc.expected {
+				t.Fatalf("expected: %q, got: %q", tc.expected, b)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+// Package epub parses the container and the OPF package document of EPUB
+// publications, such as their metadata, manifest and spine, using the
+// xmltokenizer.
+package epub
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"path"
+
+	"github.com/muktihari/xmltokenizer"
+)
+
+type errorString string
+
+func (e errorString) Error() string { return string(e) }
+
+const (
+	errNoRootfile = errorString("no rootfile")
+	errNotFound   = errorString("file not found")
+)
+
+// ContainerPath is the path of the container file within an EPUB.
+const ContainerPath = "META-INF/container.xml"
+
+// Rootfile is a rootfile of the container, typically the package document.
+type Rootfile struct {
+	FullPath  string
+	MediaType string
+}
+
+// ParseContainer parses the container file, see ContainerPath, and returns
+// its rootfiles.
+func ParseContainer(r io.Reader) ([]Rootfile, error) {
+	var rootfiles []Rootfile
+	tok := xmltokenizer.New(r)
+	for {
+		token, err := tok.Token()
+		if err == io.EOF {
+			return rootfiles, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("container: %w", err)
+		}
+		if token.IsEndElement || string(token.Name.Local) != "rootfile" {
+			continue
+		}
+		var rootfile Rootfile
+		for _, attr := range token.Attrs {
+			switch string(attr.Name.Local) {
+			case "full-path":
+				rootfile.FullPath = unescape(attr.Value)
+			case "media-type":
+				rootfile.MediaType = unescape(attr.Value)
+			}
+		}
+		rootfiles = append(rootfiles, rootfile)
+	}
+}
+
+// Open reads the EPUB of the given size from r and parses the package
+// document of its first rootfile.
+func Open(r io.ReaderAt, size int64) (*Package, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	return ReadZip(zr)
+}
+
+// ReadZip parses the package document of the first rootfile of the EPUB.
+func ReadZip(zr *zip.Reader) (*Package, error) {
+	var rootfiles []Rootfile
+	err := openZip(zr, ContainerPath, func(r io.Reader) (err error) {
+		rootfiles, err = ParseContainer(r)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(rootfiles) == 0 {
+		return nil, fmt.Errorf("%s: %w", ContainerPath, errNoRootfile)
+	}
+	var pkg *Package
+	err = openZip(zr, rootfiles[0].FullPath, func(r io.Reader) (err error) {
+		pkg, err = ParsePackage(r)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	pkg.Path = rootfiles[0].FullPath
+	return pkg, nil
+}
+
+// openZip invokes fn with the content of the named file of zr.
+func openZip(zr *zip.Reader, name string, fn func(r io.Reader) error) error {
+	for _, f := range zr.File {
+		if f.Name != name {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		defer rc.Close()
+		if err = fn(rc); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		return nil
+	}
+	return fmt.Errorf("%s: %w", name, errNotFound)
+}
+
+// Resolve returns the path within the EPUB of the href of an item of the
+// manifest, which is relative to the package document.
+func (p *Package) Resolve(href string) string {
+	return path.Join(path.Dir(p.Path), href)
+}
+
+func unescape(b []byte) string {
+	return string(xmltokenizer.Attr{Value: b}.ValueUnescaped(nil))
+}
@@ -0,0 +1,90 @@
+package epub_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/muktihari/xmltokenizer/epub"
+)
+
+const container = `<?xml version="1.0"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>`
+
+func TestParseContainer(t *testing.T) {
+	rootfiles, err := epub.ParseContainer(strings.NewReader(container))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []epub.Rootfile{{FullPath: "OEBPS/content.opf", MediaType: "application/oebps-package+xml"}}
+	if diff := cmp.Diff(rootfiles, expected); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	b := newZip(t, map[string]string{
+		"mimetype":          "application/epub+zip",
+		epub.ContainerPath:  container,
+		"OEBPS/content.opf": opf3,
+	})
+	p, err := epub.Open(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Path != "OEBPS/content.opf" || p.Identifier() != "urn:uuid:1234" {
+		t.Fatalf("unexpected package: %s %s", p.Path, p.Identifier())
+	}
+	if path := p.Resolve(p.Manifest[1].Href); path != "OEBPS/text/c1.xhtml" {
+		t.Fatalf("expected path: %q, got: %q", "OEBPS/text/c1.xhtml", path)
+	}
+}
+
+func TestOpenError(t *testing.T) {
+	tt := []struct {
+		name  string
+		files map[string]string
+	}{
+		{name: "no container", files: map[string]string{"mimetype": "application/epub+zip"}},
+		{name: "no rootfile", files: map[string]string{epub.ContainerPath: `<container/>`}},
+		{name: "no package", files: map[string]string{epub.ContainerPath: container}},
+		{name: "invalid package", files: map[string]string{
+			epub.ContainerPath:  container,
+			"OEBPS/content.opf": `<package><`,
+		}},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			b := newZip(t, tc.files)
+			if _, err := epub.Open(bytes.NewReader(b), int64(len(b))); err == nil {
+				t.Fatalf("expected error, got nil")
+			}
+		})
+	}
+
+	if _, err := epub.Open(bytes.NewReader(nil), 0); err == nil {
+		t.Fatalf("expected error, got nil")
+	}
+}
+
+func newZip(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,192 @@
+package epub
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/muktihari/xmltokenizer"
+)
+
+// Package is an OPF package document.
+type Package struct {
+	Path             string // Path within the EPUB, set by Open and ReadZip.
+	Version          string // Version of EPUB, e.g. "3.0".
+	UniqueIdentifier string // ID of the identifier element of the publication.
+	Metadata         Metadata
+	Manifest         []Item
+	Spine            Spine
+}
+
+// Metadata is the metadata of a Package.
+type Metadata struct {
+	// DC holds the Dublin Core elements by their local name, e.g. "title",
+	// "creator", "language" or "identifier", in document order.
+	DC   map[string][]Element
+	Meta []Meta
+}
+
+// Element is a Dublin Core element of the Metadata.
+type Element struct {
+	ID     string // Referred to by the Refines of a Meta.
+	Value  string
+	Role   string // opf:role attribute of EPUB 2, e.g. "aut".
+	FileAs string // opf:file-as attribute of EPUB 2.
+}
+
+// Meta is a meta element of the Metadata, either an EPUB 3 property or an
+// EPUB 2 name and content pair.
+type Meta struct {
+	ID       string
+	Property string // e.g. "dcterms:modified".
+	Refines  string // e.g. "#creator1".
+	Scheme   string
+	Value    string
+	Name     string // e.g. "cover".
+	Content  string
+}
+
+// Item is an item of the Manifest.
+type Item struct {
+	ID         string
+	Href       string // Relative to the package document, see Package.Resolve.
+	MediaType  string
+	Properties string // e.g. "nav" or "cover-image".
+	Fallback   string
+}
+
+// Spine is the default reading order of a Package.
+type Spine struct {
+	Toc                      string // ID of the NCX item of EPUB 2.
+	PageProgressionDirection string
+	Itemrefs                 []Itemref
+}
+
+// Itemref is an item of the Spine.
+type Itemref struct {
+	IDRef      string
+	Linear     bool // False when the item is auxiliary content.
+	Properties string
+}
+
+// First returns the value of the first Dublin Core element of the given local
+// name, e.g. "title", or an empty string if there is none.
+func (m *Metadata) First(name string) string {
+	if elems := m.DC[name]; len(elems) > 0 {
+		return elems[0].Value
+	}
+	return ""
+}
+
+// Identifier returns the unique identifier of the publication.
+func (p *Package) Identifier() string {
+	for _, elem := range p.Metadata.DC["identifier"] {
+		if elem.ID == p.UniqueIdentifier {
+			return elem.Value
+		}
+	}
+	return ""
+}
+
+// Item returns the item of the Manifest having the given ID.
+func (p *Package) Item(id string) (Item, bool) {
+	for _, item := range p.Manifest {
+		if item.ID == id {
+			return item, true
+		}
+	}
+	return Item{}, false
+}
+
+// ParsePackage parses an OPF package document.
+func ParsePackage(r io.Reader) (*Package, error) {
+	p := &Package{Metadata: Metadata{DC: make(map[string][]Element)}}
+	tok := xmltokenizer.New(r)
+	var section string // the child element of package being parsed
+	for {
+		token, err := tok.Token()
+		if err == io.EOF {
+			return p, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("package: %w", err)
+		}
+		if len(token.Name.Full) == 0 {
+			continue
+		}
+		local := string(token.Name.Local)
+		if token.IsEndElement {
+			if local == section {
+				section = ""
+			}
+			continue
+		}
+		switch section {
+		case "":
+			switch local {
+			case "package":
+				p.Version = attr(&token, "version")
+				p.UniqueIdentifier = attr(&token, "unique-identifier")
+			case "spine":
+				p.Spine.Toc = attr(&token, "toc")
+				p.Spine.PageProgressionDirection = attr(&token, "page-progression-direction")
+			}
+			if !token.SelfClosing && (local == "metadata" || local == "manifest" || local == "spine") {
+				section = local
+			}
+		case "metadata":
+			p.Metadata.parse(&token)
+		case "manifest":
+			if local == "item" {
+				p.Manifest = append(p.Manifest, Item{
+					ID:         attr(&token, "id"),
+					Href:       attr(&token, "href"),
+					MediaType:  attr(&token, "media-type"),
+					Properties: attr(&token, "properties"),
+					Fallback:   attr(&token, "fallback"),
+				})
+			}
+		case "spine":
+			if local == "itemref" {
+				p.Spine.Itemrefs = append(p.Spine.Itemrefs, Itemref{
+					IDRef:      attr(&token, "idref"),
+					Linear:     attr(&token, "linear") != "no",
+					Properties: attr(&token, "properties"),
+				})
+			}
+		}
+	}
+}
+
+// parse parses an element of the metadata.
+func (m *Metadata) parse(token *xmltokenizer.Token) {
+	switch local := string(token.Name.Local); local {
+	case "dc-metadata", "x-metadata", "link": // EPUB 2 grouping, links
+	case "meta":
+		m.Meta = append(m.Meta, Meta{
+			ID:       attr(token, "id"),
+			Property: attr(token, "property"),
+			Refines:  attr(token, "refines"),
+			Scheme:   attr(token, "scheme"),
+			Value:    string(token.DataUnescaped(nil)),
+			Name:     attr(token, "name"),
+			Content:  attr(token, "content"),
+		})
+	default:
+		m.DC[local] = append(m.DC[local], Element{
+			ID:     attr(token, "id"),
+			Value:  string(token.DataUnescaped(nil)),
+			Role:   attr(token, "role"),
+			FileAs: attr(token, "file-as"),
+		})
+	}
+}
+
+// attr returns the unescaped value of the attribute of the given local name.
+func attr(token *xmltokenizer.Token, local string) string {
+	for i := range token.Attrs {
+		if string(token.Attrs[i].Name.Local) == local {
+			return unescape(token.Attrs[i].Value)
+		}
+	}
+	return ""
+}
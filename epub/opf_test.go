@@ -0,0 +1,117 @@
+package epub_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/muktihari/xmltokenizer/epub"
+)
+
+const opf3 = `<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="pub-id">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:identifier id="pub-id">urn:uuid:1234</dc:identifier>
+    <dc:identifier>isbn:42</dc:identifier>
+    <dc:title>Pride &amp; Prejudice</dc:title>
+    <dc:creator id="creator1">Jane Austen</dc:creator>
+    <dc:language>en</dc:language>
+    <meta refines="#creator1" property="role" scheme="marc:relators">aut</meta>
+    <meta property="dcterms:modified">2024-01-01T00:00:00Z</meta>
+    <link rel="record" href="meta.xml"/>
+  </metadata>
+  <manifest>
+    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+    <item id="c1" href="text/c1.xhtml" media-type="application/xhtml+xml"/>
+    <item id="cover" href="images/cover.jpg" media-type="image/jpeg" properties="cover-image"/>
+  </manifest>
+  <spine page-progression-direction="ltr">
+    <itemref idref="c1"/>
+    <itemref idref="nav" linear="no"/>
+  </spine>
+</package>`
+
+func TestParsePackage(t *testing.T) {
+	p, err := epub.ParsePackage(strings.NewReader(opf3))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &epub.Package{
+		Version:          "3.0",
+		UniqueIdentifier: "pub-id",
+		Metadata: epub.Metadata{
+			DC: map[string][]epub.Element{
+				"identifier": {{ID: "pub-id", Value: "urn:uuid:1234"}, {Value: "isbn:42"}},
+				"title":      {{Value: "Pride & Prejudice"}},
+				"creator":    {{ID: "creator1", Value: "Jane Austen"}},
+				"language":   {{Value: "en"}},
+			},
+			Meta: []epub.Meta{
+				{Refines: "#creator1", Property: "role", Scheme: "marc:relators", Value: "aut"},
+				{Property: "dcterms:modified", Value: "2024-01-01T00:00:00Z"},
+			},
+		},
+		Manifest: []epub.Item{
+			{ID: "nav", Href: "nav.xhtml", MediaType: "application/xhtml+xml", Properties: "nav"},
+			{ID: "c1", Href: "text/c1.xhtml", MediaType: "application/xhtml+xml"},
+			{ID: "cover", Href: "images/cover.jpg", MediaType: "image/jpeg", Properties: "cover-image"},
+		},
+		Spine: epub.Spine{
+			PageProgressionDirection: "ltr",
+			Itemrefs: []epub.Itemref{
+				{IDRef: "c1", Linear: true},
+				{IDRef: "nav", Linear: false},
+			},
+		},
+	}
+	if diff := cmp.Diff(p, expected); diff != "" {
+		t.Fatal(diff)
+	}
+	if id := p.Identifier(); id != "urn:uuid:1234" {
+		t.Fatalf("expected identifier: %q, got: %q", "urn:uuid:1234", id)
+	}
+	if title := p.Metadata.First("title"); title != "Pride & Prejudice" {
+		t.Fatalf("expected title: %q, got: %q", "Pride & Prejudice", title)
+	}
+	if item, ok := p.Item("cover"); !ok || item.Href != "images/cover.jpg" {
+		t.Fatalf("expected cover item, got: %+v, %t", item, ok)
+	}
+}
+
+func TestParsePackageEPUB2(t *testing.T) {
+	const opf2 = `<package version="2.0" unique-identifier="id">
+  <metadata>
+    <dc-metadata>
+      <dc:Title>Old</dc:Title>
+      <dc:creator opf:role="aut" opf:file-as="Doe, John">John Doe</dc:creator>
+    </dc-metadata>
+    <meta name="cover" content="cover-img"/>
+  </metadata>
+  <manifest><item id="ncx" href="toc.ncx" media-type="application/x-dtbncx+xml"/></manifest>
+  <spine toc="ncx"/>
+</package>`
+	p, err := epub.ParsePackage(strings.NewReader(opf2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(p.Metadata, epub.Metadata{
+		DC: map[string][]epub.Element{
+			"Title":   {{Value: "Old"}},
+			"creator": {{Value: "John Doe", Role: "aut", FileAs: "Doe, John"}},
+		},
+		Meta: []epub.Meta{{Name: "cover", Content: "cover-img"}},
+	}); diff != "" {
+		t.Fatal(diff)
+	}
+	if p.Spine.Toc != "ncx" || len(p.Spine.Itemrefs) != 0 {
+		t.Fatalf("unexpected spine: %+v", p.Spine)
+	}
+
+	if _, err = epub.ParsePackage(strings.NewReader(`<package><metadata>`)); err != nil {
+		t.Fatalf("expected nil, got: %v", err)
+	}
+	if _, err = epub.ParsePackage(strings.NewReader(`<package><metadata`)); err == nil {
+		t.Fatalf("expected error, got nil")
+	}
+}
@@ -0,0 +1,99 @@
+package xmltokenizer
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// snippetRadius is the maximum number of bytes surrounding
+// the error position to be included in the SyntaxError's snippet.
+const snippetRadius = 32
+
+// SyntaxError represents an error occurred while tokenizing the XML
+// with the position where it occurs.
+type SyntaxError struct {
+	Pos Pos   // Position where the error occurs.
+	Err error // Underlying error.
+
+	Element      Name // Innermost element open when the error occurs, empty if none.
+	ElementBegin Pos  // Begin of Element.
+
+	snippet []byte // Copy of the bytes surrounding Pos.
+	mark    int    // Index of Pos within snippet.
+}
+
+// newSyntaxError creates new SyntaxError occurred at buf[i] with position pos.
+func newSyntaxError(err error, pos Pos, buf []byte, i int) *SyntaxError {
+	start, end := max(0, i-snippetRadius), min(len(buf), i+snippetRadius)
+	for start < i && !utf8.RuneStart(buf[start]) {
+		start++
+	}
+	for end > i && end < len(buf) && !utf8.RuneStart(buf[end]) {
+		end--
+	}
+	return &SyntaxError{
+		Pos:     pos,
+		Err:     err,
+		snippet: append([]byte(nil), buf[start:end]...),
+		mark:    i - start,
+	}
+}
+
+func (e *SyntaxError) Error() string {
+	if len(e.Element.Full) == 0 {
+		return fmt.Sprintf("line: %d column: %d byte offset %d: %v", e.Pos.Line, e.Pos.Column, e.Pos.Offset, e.Err)
+	}
+	return fmt.Sprintf("line: %d column: %d byte offset %d: %v, inside <%s> started at %s",
+		e.Pos.Line, e.Pos.Column, e.Pos.Offset, e.Err, e.Element.Full, e.ElementBegin)
+}
+
+func (e *SyntaxError) Unwrap() error { return e.Err }
+
+// Snippet returns a short excerpt of the bytes surrounding the error position
+// followed by a line with a caret marking the position, e.g.:
+//
+//	<trkpt lat="-7.2" lon="110.3"><ele>1205
+//	                                       ^
+//
+// Whitespace characters are shown as a single space to keep the caret aligned.
+func (e *SyntaxError) Snippet() string {
+	var sb strings.Builder
+	for _, r := range string(e.snippet) {
+		switch r {
+		case '\n', '\r', '\t':
+			r = ' '
+		}
+		sb.WriteRune(r)
+	}
+	sb.WriteByte('\n')
+	sb.WriteString(strings.Repeat(" ", utf8.RuneCount(e.snippet[:e.mark])))
+	sb.WriteByte('^')
+	return sb.String()
+}
+
+// TruncatedError is the underlying error of a SyntaxError when the input ends
+// within a CDATA section, a comment or a processing instruction, carrying
+// what was read of it so recovery tools can decide whether it is usable.
+// It wraps io.ErrUnexpectedEOF.
+type TruncatedError struct {
+	Begin      Pos    // Begin of the construct, or of its last chunk, see WithChunkedCharData.
+	Partial    []byte // Bytes following the opening delimiter, e.g. "<![CDATA[", up to the end of the input.
+	Terminator string // Expected terminator: "]]>", "-->" or "?>".
+}
+
+func (e *TruncatedError) Error() string {
+	var kind string
+	switch e.Terminator {
+	case "]]>":
+		kind = "CDATA section"
+	case "-->":
+		kind = "comment"
+	default:
+		kind = "processing instruction"
+	}
+	return fmt.Sprintf("%v in %s started at %s, expecting %q", io.ErrUnexpectedEOF, kind, e.Begin, e.Terminator)
+}
+
+func (e *TruncatedError) Unwrap() error { return io.ErrUnexpectedEOF }
@@ -0,0 +1,352 @@
+package xmltokenizer_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/muktihari/xmltokenizer"
+)
+
+func TestSyntaxError(t *testing.T) {
+	tt := []struct {
+		name            string
+		xml             string
+		opts            []xmltokenizer.Option
+		expectedPos     xmltokenizer.Pos
+		expectedSnippet string
+		err             error
+	}{
+		{
+			name:        "truncated tag",
+			xml:         "<a>\n\t<b x=\"1\"",
+			expectedPos: xmltokenizer.Pos{Line: 2, Column: 10, Offset: 13},
+			expectedSnippet: "<b x=\"1\"\n" +
+				"        ^",
+			err: io.ErrUnexpectedEOF,
+		},
+		{
+			name: "exceed max input bytes",
+			xml:  "<a>" + strings.Repeat("白", 20) + "</a>",
+			opts: []xmltokenizer.Option{
+				xmltokenizer.WithReadBufferSize(1),
+				xmltokenizer.WithMaxInputBytes(50),
+			},
+			expectedPos: xmltokenizer.Pos{Line: 1, Column: 22, Offset: 51},
+			expectedSnippet: "白白白白白白白白白白\n" +
+				strings.Repeat(" ", 10) + "^",
+			err: xmltokenizer.ErrMaxInputBytesExceeded,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tok := xmltokenizer.New(strings.NewReader(tc.xml), tc.opts...)
+			var err error
+			for err == nil {
+				_, err = tok.Token()
+			}
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expected error: %v, got: %v", tc.err, err)
+			}
+			var syntaxErr *xmltokenizer.SyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Fatalf("expected SyntaxError, got: %T", err)
+			}
+			if diff := cmp.Diff(syntaxErr.Pos, tc.expectedPos); diff != "" {
+				t.Fatal(diff)
+			}
+			if diff := cmp.Diff(syntaxErr.Snippet(), tc.expectedSnippet); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
+
+func TestReaderErrorIsNotSyntaxError(t *testing.T) {
+	errTimeout := errors.New("timeout")
+	r := io.MultiReader(strings.NewReader("<a><b>text"), iotest.ErrReader(errTimeout))
+	tok := xmltokenizer.New(r)
+	var err error
+	for err == nil {
+		_, err = tok.Token()
+	}
+	if !errors.Is(err, errTimeout) {
+		t.Fatalf("expected error: %v, got: %v", errTimeout, err)
+	}
+	var syntaxErr *xmltokenizer.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		t.Fatalf("expected an error of the reader not to be a SyntaxError, got: %v", syntaxErr)
+	}
+}
+
+func TestSyntaxErrorElement(t *testing.T) {
+	tt := []struct {
+		name     string
+		xml      string
+		expected string
+	}{
+		{
+			name:     "within nested elements",
+			xml:      "<gpx>\n  <trk>\n    <trkseg><trkpt/>\n  </trk>\n  <wpt></b>\n    <name",
+			expected: "line: 6 column: 10 byte offset 65: unexpected EOF, inside <wpt> started at line 5, col 3",
+		},
+		{
+			name:     "outside any element",
+			xml:      "<a></a><b",
+			expected: "line: 1 column: 10 byte offset 9: unexpected EOF",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tok := xmltokenizer.New(strings.NewReader(tc.xml))
+			var err error
+			for err == nil {
+				_, err = tok.Token()
+			}
+			if err.Error() != tc.expected {
+				t.Fatalf("expected error: %s, got: %v", tc.expected, err)
+			}
+		})
+	}
+
+	t.Run("element is retained", func(t *testing.T) {
+		tok := xmltokenizer.New(strings.NewReader("<x:a>\n<b"))
+		var err error
+		for err == nil {
+			_, err = tok.Token()
+		}
+		var syntaxErr *xmltokenizer.SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Fatalf("expected SyntaxError, got: %T", err)
+		}
+		expected := xmltokenizer.Name{Prefix: []byte("x"), Local: []byte("a"), Full: []byte("x:a")}
+		if diff := cmp.Diff(syntaxErr.Element, expected); diff != "" {
+			t.Fatal(diff)
+		}
+		if diff := cmp.Diff(syntaxErr.ElementBegin, xmltokenizer.Pos{Line: 1, Column: 1}); diff != "" {
+			t.Fatal(diff)
+		}
+	})
+}
+
+func TestTruncatedError(t *testing.T) {
+	tt := []struct {
+		name     string
+		xml      string
+		opts     []xmltokenizer.Option
+		expected *xmltokenizer.TruncatedError
+	}{
+		{
+			name: "cdata following a start element",
+			xml:  "<a>\n  <![CDATA[ partial",
+			expected: &xmltokenizer.TruncatedError{
+				Begin:      xmltokenizer.Pos{Line: 2, Column: 3, Offset: 6},
+				Partial:    []byte(" partial"),
+				Terminator: "]]>",
+			},
+		},
+		{
+			name: "cdata following an end element",
+			xml:  "<a></a><![CDATA[xy]]",
+			expected: &xmltokenizer.TruncatedError{
+				Begin:      xmltokenizer.Pos{Line: 1, Column: 8, Offset: 7},
+				Partial:    []byte("xy]]"),
+				Terminator: "]]>",
+			},
+		},
+		{
+			name: "chunked cdata",
+			xml:  "<a><![CDATA[" + strings.Repeat("x", 5000),
+			opts: []xmltokenizer.Option{
+				xmltokenizer.WithReadBufferSize(1),
+				xmltokenizer.WithAutoGrowBufferMaxLimitSize(16),
+				xmltokenizer.WithChunkedCharData(),
+			},
+			expected: &xmltokenizer.TruncatedError{
+				Begin:      xmltokenizer.Pos{Line: 1, Column: 4098, Offset: 4097}, // of the last chunk
+				Partial:    []byte(strings.Repeat("x", 5012-4097)),
+				Terminator: "]]>",
+			},
+		},
+		{
+			name: "comment",
+			xml:  "<a>\n<!-- a -- b ->",
+			expected: &xmltokenizer.TruncatedError{
+				Begin:      xmltokenizer.Pos{Line: 2, Column: 1, Offset: 4},
+				Partial:    []byte(" a -- b ->"),
+				Terminator: "-->",
+			},
+		},
+		{
+			name: "processing instruction",
+			xml:  `<?xml-stylesheet href="a.xsl"`,
+			expected: &xmltokenizer.TruncatedError{
+				Begin:      xmltokenizer.Pos{Line: 1, Column: 1, Offset: 0},
+				Partial:    []byte(`xml-stylesheet href="a.xsl"`),
+				Terminator: "?>",
+			},
+		},
+		{
+			name: "start element",
+			xml:  `<a><b x="1"`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tok := xmltokenizer.New(strings.NewReader(tc.xml), tc.opts...)
+			var err error
+			for err == nil {
+				_, err = tok.Token()
+			}
+			if !errors.Is(err, io.ErrUnexpectedEOF) {
+				t.Fatalf("expected error: %v, got: %v", io.ErrUnexpectedEOF, err)
+			}
+			var truncatedErr *xmltokenizer.TruncatedError
+			if !errors.As(err, &truncatedErr) {
+				truncatedErr = nil
+			}
+			if diff := cmp.Diff(truncatedErr, tc.expected); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+
+	err := &xmltokenizer.TruncatedError{Begin: xmltokenizer.Pos{Line: 4, Column: 5}, Terminator: "]]>"}
+	expected := `unexpected EOF in CDATA section started at line 4, col 5, expecting "]]>"`
+	if err.Error() != expected {
+		t.Fatalf("expected: %q, got: %q", expected, err.Error())
+	}
+}
+
+func TestWithMaxAttrValueSize(t *testing.T) {
+	const xml = "<a>\n  <img alt=\"ok\" src=\"data:image/png;base64,AAAAAAAAAAAAAAAAAAAA\"/>\n  <b x='12345678'/>\n</a>"
+
+	tt := []struct {
+		name     string
+		opts     []xmltokenizer.Option
+		expected xmltokenizer.Pos
+	}{
+		{
+			name:     "value fully buffered",
+			opts:     []xmltokenizer.Option{xmltokenizer.WithMaxAttrValueSize(16)},
+			expected: xmltokenizer.Pos{Line: 2, Column: 22, Offset: 25},
+		},
+		{
+			name: "value partially buffered",
+			opts: []xmltokenizer.Option{
+				xmltokenizer.WithMaxAttrValueSize(16),
+				xmltokenizer.WithReadBufferSize(1),
+			},
+			expected: xmltokenizer.Pos{Line: 2, Column: 22, Offset: 25},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tok := xmltokenizer.New(strings.NewReader(xml), tc.opts...)
+			var err error
+			for err == nil {
+				_, err = tok.Token()
+			}
+			if !errors.Is(err, xmltokenizer.ErrMaxAttrValueSizeExceeded) {
+				t.Fatalf("expected error: %v, got: %v", xmltokenizer.ErrMaxAttrValueSizeExceeded, err)
+			}
+			var syntaxErr *xmltokenizer.SyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Fatalf("expected SyntaxError, got: %T", err)
+			}
+			if diff := cmp.Diff(syntaxErr.Pos, tc.expected); diff != "" {
+				t.Fatal(diff)
+			}
+			if string(syntaxErr.Element.Full) != "a" {
+				t.Fatalf("expected element: a, got: %s", syntaxErr.Element.Full)
+			}
+
+			if err = tok.Recover(); err != nil {
+				t.Fatalf("expected nil, got: %v", err)
+			}
+			token, err := tok.Token()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s := token.String(); s != `<b x="12345678" @3:3/>` {
+				t.Fatalf("expected next token: <b>, got: %s", s)
+			}
+		})
+	}
+}
+
+func TestWithIllegalCharCheck(t *testing.T) {
+	tt := []struct {
+		name     string
+		xml      string
+		expected string
+		next     []string
+	}{
+		{
+			name:     "control character in text",
+			xml:      "<a>\n  <b>bell\a</b>\n  <c/>\n</a>",
+			expected: "line: 2 column: 10 byte offset 13: U+0007: illegal character, inside <a> started at line 1, col 1",
+			next:     []string{"</b @2:11>", "<c @3:3/>", "</a @4:1>"},
+		},
+		{
+			name:     "nul in attribute value",
+			xml:      "<a>\n  <b x=\"\x00\"/>\n  <c/>\n</a>",
+			expected: "line: 2 column: 9 byte offset 12: U+0000: illegal character, inside <a> started at line 1, col 1",
+			next:     []string{"<c @3:3/>", "</a @4:1>"},
+		},
+		{
+			name:     "noncharacter",
+			xml:      "<a>\n  <b>￿</b>\n  <c/>\n</a>",
+			expected: "line: 2 column: 6 byte offset 9: U+FFFF: illegal character, inside <a> started at line 1, col 1",
+			next:     []string{"</b @2:7>", "<c @3:3/>", "</a @4:1>"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tok := xmltokenizer.New(strings.NewReader(tc.xml), xmltokenizer.WithIllegalCharCheck())
+			var err error
+			for err == nil {
+				_, err = tok.Token()
+			}
+			if !errors.Is(err, xmltokenizer.ErrIllegalChar) || err.Error() != tc.expected {
+				t.Fatalf("expected error: %s, got: %v", tc.expected, err)
+			}
+			if err = tok.Recover(); err != nil {
+				t.Fatalf("expected nil, got: %v", err)
+			}
+			var names []string
+			for {
+				token, err := tok.Token()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				names = append(names, token.String())
+			}
+			if diff := cmp.Diff(names, tc.next); diff != "" {
+				t.Fatalf("after Recover: %s", diff)
+			}
+		})
+	}
+
+	tok := xmltokenizer.New(strings.NewReader("<a x=\"&#1;\">\t\r\n</a>"), xmltokenizer.WithIllegalCharCheck())
+	for {
+		_, err := tok.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package xmltokenizer
+
+import (
+	"bytes"
+	"strconv"
+	"unicode/utf8"
+)
+
+// ValueUnescaped appends the Value to dst with its entities decoded, see
+// Token.DataUnescaped, and returns the extended buffer.
+func (a Attr) ValueUnescaped(dst []byte) []byte { return appendUnescaped(dst, a.Value) }
+
+// DataUnescaped appends the Data to dst with the predefined entities (&lt;
+// &gt; &amp; &apos; &quot;) and the character references (&#N; &#xH;)
+// decoded, and returns the extended buffer. Any other entity is kept as it is.
+// Passing a reused buffer, e.g. dst[:0], decodes without allocation.
+//
+// Only CharData should be unescaped: the Data of a CDATA section, which can
+// not be told apart once tokenized, and of "<?" or "<!" tags are literal.
+func (t *Token) DataUnescaped(dst []byte) []byte { return appendUnescaped(dst, t.Data) }
+
+// WithUnescapeData directs XML Tokenizer to decode the predefined entities and
+// the character references of the CharData and the attribute values, see
+// Token.DataUnescaped, into an internal buffer reused across tokens, so
+// consumers do not need their own unescaper. Unlike the UnescapeEntities
+// Middleware, the Data of a CDATA section is told apart and left literal, as
+// is the Data of "<?" and "<!" tags. An entity split across the chunks of
+// WithChunkedCharData is left as it is. Raw and RawToken are left untouched,
+// RawData and RawAttrValue return the bytes before they are decoded, and
+// DataPos and AttrValuePos map the decoded bytes back to the source.
+func WithUnescapeData() Option {
+	return func(o *options) { o.unescapeData = true }
+}
+
+// unescapeToken decodes the entities of the Data and the attribute values of
+// the token into t.unescaped, leaving those without entities as they are.
+func (t *Tokenizer) unescapeToken() {
+	data := !t.cdata && (len(t.token.Name.Full) > 0 || t.token.Continued) &&
+		bytes.IndexByte(t.token.Data, '&') != -1
+	n := 0
+	if data {
+		n += len(t.token.Data)
+	}
+	for i := range t.token.Attrs {
+		if bytes.IndexByte(t.token.Attrs[i].Value, '&') != -1 {
+			n += len(t.token.Attrs[i].Value)
+		}
+	}
+	if n == 0 {
+		return
+	}
+	if cap(t.unescaped) < n {
+		t.unescaped = make([]byte, 0, n) // values must not be moved by append, decoding never grows
+	}
+	buf := t.unescaped[:0]
+	if data {
+		t.escaped = t.token.Data
+		t.token.Data, buf = unescapeInto(buf, t.token.Data)
+	}
+	for i := range t.token.Attrs {
+		var escaped []byte
+		if attr := &t.token.Attrs[i]; bytes.IndexByte(attr.Value, '&') != -1 {
+			escaped = attr.Value
+			attr.Value, buf = unescapeInto(buf, attr.Value)
+		}
+		t.escapedVals = append(t.escapedVals, escaped)
+	}
+	t.unescaped = buf
+}
+
+// RawData returns the Data of the last token returned by Token as it is in
+// the source, before WithUnescapeData decodes its entities, so round-trip
+// tools and security scanners see exactly what the producer wrote. It is the
+// Data itself if it is not decoded. The returned bytes are only valid before
+// next Token or RawToken method invocation.
+func (t *Tokenizer) RawData() []byte {
+	if t.escaped != nil {
+		return t.escaped
+	}
+	return t.token.Data
+}
+
+// RawAttrValue is like RawData for the Value of Attrs[attr] of the last token
+// returned by Token, it returns nil if attr is out of range.
+func (t *Tokenizer) RawAttrValue(attr int) []byte {
+	if attr < 0 || attr >= len(t.token.Attrs) {
+		return nil
+	}
+	if attr < len(t.escapedVals) && t.escapedVals[attr] != nil {
+		return t.escapedVals[attr]
+	}
+	return t.token.Attrs[attr].Value
+}
+
+// DataPos returns the position in the source of the byte at index i of the
+// Data of the last token returned by Token, the index len(Data) being the
+// position following it. The entities decoded by WithUnescapeData are
+// accounted for, the bytes of a decoded entity being at the position of its
+// "&", so errors and highlights found in the decoded Data point at the right
+// place of the source. It returns the zero Pos if i is out of range or the
+// Data is empty.
+func (t *Tokenizer) DataPos(i int) Pos {
+	return t.sourcePos(t.token.Data, t.escaped, i)
+}
+
+// AttrValuePos is like DataPos for the Value of Attrs[attr] of the last token
+// returned by Token.
+func (t *Tokenizer) AttrValuePos(attr, i int) Pos {
+	if attr < 0 || attr >= len(t.token.Attrs) {
+		return Pos{}
+	}
+	var escaped []byte
+	if attr < len(t.escapedVals) {
+		escaped = t.escapedVals[attr]
+	}
+	return t.sourcePos(t.token.Attrs[attr].Value, escaped, i)
+}
+
+// sourcePos returns the position in the source of the byte at index i of b,
+// the Data or a Value of the last token, escaped being b before it is decoded
+// or nil if it is not.
+func (t *Tokenizer) sourcePos(b, escaped []byte, i int) Pos {
+	if i < 0 || i > len(b) {
+		return Pos{}
+	}
+	if escaped != nil {
+		b, i = escaped, escapedIndex(escaped, i)
+	}
+	raw := t.Raw()
+	off := cap(raw) - cap(b) // b is a slice of raw, sharing its array
+	if len(b) == 0 || off < 0 || off+len(b) > len(raw) || !bytes.Equal(raw[off:off+len(b)], b) {
+		return Pos{}
+	}
+	pos := t.token.Begin
+	pos.step(raw[:off+i])
+	return pos
+}
+
+// escapedIndex returns the index in escaped of the byte at index i of escaped
+// decoded by appendUnescaped, the index of the "&" for the bytes of a decoded
+// entity.
+func escapedIndex(escaped []byte, i int) int {
+	j := 0
+	for {
+		p := bytes.IndexByte(escaped[j:], '&')
+		if p == -1 || i < p {
+			return j + i
+		}
+		i, j = i-p, j+p
+		end := bytes.IndexByte(escaped[j:], ';')
+		if end == -1 {
+			return j + i
+		}
+		n := end + 1
+		if r, ok := decodeEntity(escaped[j+1 : j+end]); ok {
+			if i < utf8.RuneLen(r) {
+				return j
+			}
+			i -= utf8.RuneLen(r)
+		} else {
+			if i < n {
+				return j + i
+			}
+			i -= n
+		}
+		j += n
+	}
+}
+
+// appendUnescaped appends b to dst with the predefined entities (&lt; &gt;
+// &amp; &apos; &quot;) and the character references (&#N; &#xH;) decoded.
+// Any other entity, or an invalid reference, is appended as it is.
+func appendUnescaped(dst, b []byte) []byte {
+	for {
+		i := bytes.IndexByte(b, '&')
+		if i == -1 {
+			return append(dst, b...)
+		}
+		dst = append(dst, b[:i]...)
+		b = b[i:]
+		end := bytes.IndexByte(b, ';')
+		if end == -1 {
+			return append(dst, b...)
+		}
+		if r, ok := decodeEntity(b[1:end]); ok {
+			dst = utf8.AppendRune(dst, r)
+		} else {
+			dst = append(dst, b[:end+1]...)
+		}
+		b = b[end+1:]
+	}
+}
+
+// decodeEntity decodes the entity name, e.g. "lt" or "#x767d", which must
+// be a predefined entity or a character reference.
+func decodeEntity(name []byte) (rune, bool) {
+	switch string(name) {
+	case "lt":
+		return '<', true
+	case "gt":
+		return '>', true
+	case "amp":
+		return '&', true
+	case "apos":
+		return '\'', true
+	case "quot":
+		return '"', true
+	}
+	if len(name) < 2 || name[0] != '#' {
+		return 0, false
+	}
+	base, digits := 10, name[1:]
+	if digits[0] == 'x' {
+		base, digits = 16, digits[1:]
+	}
+	n, err := strconv.ParseUint(string(digits), base, 32)
+	if err != nil || !utf8.ValidRune(rune(n)) {
+		return 0, false
+	}
+	return rune(n), true
+}
@@ -0,0 +1,220 @@
+package xmltokenizer_test
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/muktihari/xmltokenizer"
+)
+
+func TestUnescaped(t *testing.T) {
+	tok := xmltokenizer.New(strings.NewReader(
+		`<a title="&quot;Tom &amp; Jerry&quot;">1 &lt; 2 &#x2713; &#10003; &nbsp; &bogus</a>`))
+	token, err := tok.Token()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf := token.Attrs[0].ValueUnescaped(nil)
+	if expected := `"Tom & Jerry"`; string(buf) != expected {
+		t.Fatalf("expected: %q, got: %q", expected, buf)
+	}
+	buf = token.DataUnescaped(buf[:0])
+	if expected := "1 < 2 ✓ ✓ &nbsp; &bogus"; string(buf) != expected {
+		t.Fatalf("expected: %q, got: %q", expected, buf)
+	}
+	if string(token.Data) != "1 &lt; 2 &#x2713; &#10003; &nbsp; &bogus" {
+		t.Fatalf("expected Data unchanged, got: %q", token.Data)
+	}
+
+	alloc := testing.AllocsPerRun(10, func() {
+		buf = token.DataUnescaped(buf[:0])
+	})
+	if alloc != 0 {
+		t.Fatalf("expected alloc: 0, got: %g", alloc)
+	}
+}
+
+func TestWithUnescapeData(t *testing.T) {
+	in := `<!-- &amp; --><a title="&quot;Tom &amp; Jerry&quot;" id="1">&lt;&#x767d;&#40300;&gt;` +
+		`<b><![CDATA[&amp;]]></b>a &amp;&amp; b</a>`
+	tok := xmltokenizer.New(strings.NewReader(in), xmltokenizer.WithUnescapeData())
+
+	expected := []struct {
+		data  string
+		attrs []string
+		raw   string
+	}{
+		{data: "<!-- &amp; -->", raw: "<!-- &amp; -->"},
+		{data: "<白鵬>", attrs: []string{`"Tom & Jerry"`, "1"},
+			raw: `<a title="&quot;Tom &amp; Jerry&quot;" id="1">&lt;&#x767d;&#40300;&gt;`},
+		{data: "&amp;", raw: "<b><![CDATA[&amp;]]>"},
+		{data: "a && b", raw: "</b>a &amp;&amp; b"},
+		{data: "", raw: "</a>"},
+	}
+	for i, e := range expected {
+		token, err := tok.Token()
+		if err != nil {
+			t.Fatalf("[%d] expected nil, got: %v", i, err)
+		}
+		if string(token.Data) != e.data {
+			t.Fatalf("[%d] expected Data: %q, got: %q", i, e.data, token.Data)
+		}
+		for j, v := range e.attrs {
+			if string(token.Attrs[j].Value) != v {
+				t.Fatalf("[%d] expected Value: %q, got: %q", i, v, token.Attrs[j].Value)
+			}
+		}
+		if string(tok.Raw()) != e.raw {
+			t.Fatalf("[%d] expected Raw: %q, got: %q", i, e.raw, tok.Raw())
+		}
+	}
+
+	if c := tok.Capabilities(); c&xmltokenizer.CapUnescapeData == 0 {
+		t.Fatalf("expected %v in %v", xmltokenizer.CapUnescapeData, c)
+	}
+}
+
+func TestWithUnescapeDataPersistentTokens(t *testing.T) {
+	tok := xmltokenizer.New(strings.NewReader(`<a v="&amp;">&lt;</a><b v="&gt;">&amp;</b>`),
+		xmltokenizer.WithUnescapeData(), xmltokenizer.WithPersistentTokens())
+	first, err := tok.Token()
+	if err != nil {
+		t.Fatalf("expected nil, got: %v", err)
+	}
+	for {
+		if _, err = tok.Token(); err != nil {
+			break
+		}
+	}
+	if string(first.Data) != "<" || string(first.Attrs[0].Value) != "&" {
+		t.Fatalf("expected Data: %q and Value: %q, got: %q and %q", "<", "&", first.Data, first.Attrs[0].Value)
+	}
+}
+
+func TestDataPos(t *testing.T) {
+	const in = "<r>\n<a title=\"x &amp; y\">one\n&lt;&#x767d;é &bogus; z</a></r>"
+	tt := []struct {
+		name     string
+		opts     []xmltokenizer.Option
+		data     string
+		value    string
+		dataPos  map[string]string // source by the suffix of Data starting at the byte
+		valuePos map[string]string // source by the suffix of Value starting at the byte
+	}{
+		{
+			name:  "escaped",
+			data:  "one\n&lt;&#x767d;é &bogus; z",
+			value: "x &amp; y",
+			dataPos: map[string]string{
+				"one\n&lt;&#x767d;é &bogus; z": "one\n&lt;",
+				"&lt;&#x767d;é &bogus; z":      "&lt;",
+				"x767d;é &bogus; z":            "x767d;",
+				"z":                            "z</a>",
+				"":                             "</a>",
+			},
+			valuePos: map[string]string{"&amp; y": "&amp; y", "y": `y">`},
+		},
+		{
+			name:  "unescaped",
+			opts:  []xmltokenizer.Option{xmltokenizer.WithUnescapeData()},
+			data:  "one\n<白é &bogus; z",
+			value: "x & y",
+			dataPos: map[string]string{
+				"one\n<白é &bogus; z": "one\n&lt;",
+				"<白é &bogus; z":      "&lt;",
+				"白é &bogus; z":       "&#x767d;",
+				"白é &bogus; z"[1:]:   "&#x767d;", // within a decoded entity
+				"é &bogus; z":        "é",
+				"&bogus; z":          "&bogus;",
+				"z":                  "z</a>",
+				"":                   "</a>",
+			},
+			valuePos: map[string]string{"& y": "&amp; y", "y": `y">`},
+		},
+	}
+
+	posOf := func(src string) xmltokenizer.Pos {
+		off := strings.Index(in, src)
+		line := strings.Count(in[:off], "\n")
+		col := utf8.RuneCountInString(in[strings.LastIndex(in[:off], "\n")+1 : off])
+		return xmltokenizer.Pos{Line: line + 1, Column: col + 1, Offset: off}
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tok := xmltokenizer.New(strings.NewReader(in), tc.opts...)
+			tok.Token()
+			token, err := tok.Token()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(token.Data) != tc.data || string(token.Attrs[0].Value) != tc.value {
+				t.Fatalf("expected: %q, %q, got: %q, %q", tc.data, tc.value, token.Data, token.Attrs[0].Value)
+			}
+			for suffix, src := range tc.dataPos {
+				if pos, expected := tok.DataPos(len(tc.data)-len(suffix)), posOf(src); pos != expected {
+					t.Fatalf("Data %q: expected: %v, got: %v", suffix, expected, pos)
+				}
+			}
+			for suffix, src := range tc.valuePos {
+				if pos, expected := tok.AttrValuePos(0, len(tc.value)-len(suffix)), posOf(src); pos != expected {
+					t.Fatalf("Value %q: expected: %v, got: %v", suffix, expected, pos)
+				}
+			}
+			for _, i := range []int{-1, len(tc.data) + 1} {
+				if pos := tok.DataPos(i); !pos.IsZero() {
+					t.Fatalf("[%d] expected zero Pos, got: %v", i, pos)
+				}
+			}
+			if pos := tok.AttrValuePos(1, 0); !pos.IsZero() {
+				t.Fatalf("expected zero Pos, got: %v", pos)
+			}
+		})
+	}
+}
+
+func TestRawData(t *testing.T) {
+	const in = `<a title="&quot;Tom &amp; Jerry&quot;" id="1">&lt;&#x767d;&gt;<b><![CDATA[&amp;]]></b>a &amp; b</a>`
+	expected := []struct {
+		data, rawData     string
+		values, rawValues []string
+	}{
+		{data: "<白>", rawData: "&lt;&#x767d;&gt;",
+			values: []string{`"Tom & Jerry"`, "1"}, rawValues: []string{"&quot;Tom &amp; Jerry&quot;", "1"}},
+		{data: "&amp;", rawData: "&amp;"},
+		{data: "a & b", rawData: "a &amp; b"},
+		{},
+	}
+
+	tok := xmltokenizer.New(strings.NewReader(in), xmltokenizer.WithUnescapeData())
+	for i, e := range expected {
+		token, err := tok.Token()
+		if err != nil {
+			t.Fatalf("[%d] expected nil, got: %v", i, err)
+		}
+		if string(token.Data) != e.data || string(tok.RawData()) != e.rawData {
+			t.Fatalf("[%d] expected Data: %q, %q, got: %q, %q", i, e.data, e.rawData, token.Data, tok.RawData())
+		}
+		for j := range e.values {
+			if string(token.Attrs[j].Value) != e.values[j] || string(tok.RawAttrValue(j)) != e.rawValues[j] {
+				t.Fatalf("[%d] expected Value: %q, %q, got: %q, %q",
+					i, e.values[j], e.rawValues[j], token.Attrs[j].Value, tok.RawAttrValue(j))
+			}
+		}
+		if v := tok.RawAttrValue(len(token.Attrs)); v != nil {
+			t.Fatalf("[%d] expected nil, got: %q", i, v)
+		}
+	}
+
+	// Without unescaping, Data and values are the raw bytes.
+	tok = xmltokenizer.New(strings.NewReader(in))
+	token, err := tok.Token()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(tok.RawData()) != string(token.Data) || string(tok.RawAttrValue(0)) != string(token.Attrs[0].Value) {
+		t.Fatalf("expected: %q, %q, got: %q, %q", token.Data, token.Attrs[0].Value, tok.RawData(), tok.RawAttrValue(0))
+	}
+}
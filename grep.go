@@ -0,0 +1,75 @@
+package xmltokenizer
+
+import "io"
+
+// Grep tokenizes r and calls fn with the raw bytes of every element's subtree
+// whose start element, along with the path of its ancestors, satisfies pred.
+// The raw bytes are byte-exact, from the start element's "<" until the end
+// element's ">" including anything in between, see Tokenizer.Subtree, and they are only valid during
+// the fn invocation. The token and the path given to pred are only valid during
+// the pred invocation. Elements nested within a matching subtree are not tested.
+// It returns the first error encountered other than io.EOF.
+func Grep(r io.Reader, pred func(token Token, path []Name) bool, fn func(raw []byte), opts ...Option) error {
+	tok := New(r, opts...)
+	var p NameStack
+	for {
+		token, err := tok.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if len(token.Name.Full) == 0 {
+			continue
+		}
+		if token.IsEndElement {
+			p.Pop()
+			continue
+		}
+		if !pred(token, p.Names()) {
+			if !token.SelfClosing {
+				p.Push(token.Name)
+			}
+			continue
+		}
+		raw, err := tok.Subtree(&token)
+		if err != nil {
+			return err
+		}
+		fn(raw)
+	}
+}
+
+// Subtree reads the element's subtree started by the given start element,
+// which must be the last token returned by Token, up to and including its end
+// element, and returns its byte-exact form, from the start element's "<" until
+// the end element's ">" including anything in between, e.g. to verify a
+// signature over it. The CharData following the end element, which belongs to
+// the parent element, is left out. The returned bytes are only valid before the next Token
+// or RawToken invocation. It returns io.ErrUnexpectedEOF if the subtree is
+// not closed.
+func (t *Tokenizer) Subtree(se *Token) ([]byte, error) {
+	t.record()
+	if se.SelfClosing {
+		return t.stopRecording(), nil
+	}
+	for depth := 1; depth > 0; {
+		token, err := t.Token()
+		if err != nil {
+			t.stopRecording()
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		switch {
+		case len(token.Name.Full) == 0 || token.SelfClosing:
+		case token.IsEndElement:
+			depth--
+		default:
+			depth++
+		}
+	}
+	return t.stopRecording(), nil
+}
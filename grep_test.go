@@ -0,0 +1,148 @@
+package xmltokenizer_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/muktihari/xmltokenizer"
+)
+
+func TestGrep(t *testing.T) {
+	const xml = `<?xml version="1.0"?>
+<gpx>
+  <wpt lat="1" lon="2"><name>Start</name></wpt>
+  <trk>
+    <trkseg>
+      <trkpt lat="3" lon="4">
+        <ele>10</ele>tail
+        <!-- comment > -->
+      </trkpt>
+      <trkpt lat="5" lon="6"/>
+    </trkseg>
+  </trk>
+</gpx>`
+
+	tt := []struct {
+		name      string
+		xml       string
+		pred      func(token xmltokenizer.Token, path []xmltokenizer.Name) bool
+		expecteds []string
+		err       error
+	}{
+		{
+			name: "by element name and ancestors",
+			xml:  xml,
+			pred: func(token xmltokenizer.Token, path []xmltokenizer.Name) bool {
+				return string(token.Name.Local) == "trkpt" && len(path) == 3 &&
+					string(path[1].Local) == "trk" && string(path[2].Local) == "trkseg"
+			},
+			expecteds: []string{
+				"<trkpt lat=\"3\" lon=\"4\">\n        <ele>10</ele>tail\n        <!-- comment > -->\n      </trkpt>",
+				`<trkpt lat="5" lon="6"/>`,
+			},
+		},
+		{
+			name: "nested matches are not tested",
+			xml:  xml,
+			pred: func(token xmltokenizer.Token, path []xmltokenizer.Name) bool {
+				return len(token.Attrs) > 0 || string(token.Name.Local) == "name"
+			},
+			expecteds: []string{
+				`<wpt lat="1" lon="2"><name>Start</name></wpt>`,
+				"<trkpt lat=\"3\" lon=\"4\">\n        <ele>10</ele>tail\n        <!-- comment > -->\n      </trkpt>",
+				`<trkpt lat="5" lon="6"/>`,
+			},
+		},
+		{
+			name: "text following the subtree is left out",
+			xml:  `<r><a>1</a>tail<b/></r><r><a/>tail</r><r><a x=">">1<a/>in</a> tail</r>`,
+			pred: func(token xmltokenizer.Token, path []xmltokenizer.Name) bool {
+				return string(token.Name.Local) == "a"
+			},
+			expecteds: []string{`<a>1</a>`, `<a/>`, `<a x=">">1<a/>in</a>`},
+		},
+		{
+			name: "truncated subtree",
+			xml:  `<a><b><c>text</c>`,
+			pred: func(token xmltokenizer.Token, path []xmltokenizer.Name) bool {
+				return string(token.Name.Local) == "b"
+			},
+			err: io.ErrUnexpectedEOF,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, bufferSize := range []int{1, 7, 4096} {
+				var results []string
+				err := xmltokenizer.Grep(strings.NewReader(tc.xml), tc.pred, func(raw []byte) {
+					results = append(results, string(raw))
+				}, xmltokenizer.WithReadBufferSize(bufferSize))
+				if !errors.Is(err, tc.err) {
+					t.Fatalf("buffer size %d: expected error: %v, got: %v", bufferSize, tc.err, err)
+				}
+				if diff := cmp.Diff(results, tc.expecteds); diff != "" {
+					t.Fatalf("buffer size %d: %s", bufferSize, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestTokenizerSubtree(t *testing.T) {
+	tt := []struct {
+		name     string
+		xml      string
+		expected string
+		next     string
+	}{
+		{
+			name:     "text following the end element",
+			xml:      `<r><a>1</a>tail<b/></r>`,
+			expected: `<a>1</a>`,
+			next:     "b",
+		},
+		{
+			name:     "text following the self-closing element",
+			xml:      `<r><a/>tail</r>`,
+			expected: `<a/>`,
+			next:     "r",
+		},
+		{
+			name:     "quoted greater-than sign",
+			xml:      `<r><a><b c="/>"/> x </a> tail </r>`,
+			expected: `<a><b c="/>"/> x </a>`,
+			next:     "r",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tok := xmltokenizer.New(strings.NewReader(tc.xml))
+			if _, err := tok.Token(); err != nil {
+				t.Fatal(err)
+			}
+			token, err := tok.Token()
+			if err != nil {
+				t.Fatal(err)
+			}
+			raw, err := tok.Subtree(&token)
+			if err != nil {
+				t.Fatalf("expected error: nil, got: %v", err)
+			}
+			if diff := cmp.Diff(string(raw), tc.expected); diff != "" {
+				t.Fatal(diff)
+			}
+			token, err = tok.Token()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(token.Name.Local) != tc.next {
+				t.Fatalf("expected next: %s, got: %s", tc.next, token.Name.Local)
+			}
+		})
+	}
+}
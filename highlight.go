@@ -0,0 +1,280 @@
+package xmltokenizer
+
+import (
+	"bytes"
+	"io"
+	"sort"
+	"strconv"
+)
+
+// SpanKind is the kind of a Span.
+type SpanKind uint8
+
+const (
+	SpanPunct       SpanKind = iota // Tag punctuation: "<", "</", ">", "/>" and "=" between attribute name and value.
+	SpanElementName                 // Element name, e.g. "gpxtpx:hr".
+	SpanAttrName                    // Attribute name, e.g. "xmlns:gpxtpx".
+	SpanAttrValue                   // Attribute value, including the quotes.
+	SpanText                        // CharData, excluding the surrounding whitespace.
+	SpanCDATA                       // CDATA, including "<![CDATA[" and "]]>".
+	SpanComment                     // Comment, including "<!--" and "-->".
+	SpanProcInst                    // Processing instruction, including "<?" and "?>".
+	SpanDirective                   // Directive such as DOCTYPE, including "<!" and ">".
+)
+
+var spanKindNames = [...]string{
+	SpanPunct:       "Punct",
+	SpanElementName: "ElementName",
+	SpanAttrName:    "AttrName",
+	SpanAttrValue:   "AttrValue",
+	SpanText:        "Text",
+	SpanCDATA:       "CDATA",
+	SpanComment:     "Comment",
+	SpanProcInst:    "ProcInst",
+	SpanDirective:   "Directive",
+}
+
+func (k SpanKind) String() string {
+	if int(k) < len(spanKindNames) {
+		return spanKindNames[k]
+	}
+	return "SpanKind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// Span is a classified region of the XML source suitable for syntax highlighting.
+type Span struct {
+	Kind       SpanKind
+	Begin, End Pos // Begin and end of this span within the source.
+}
+
+// Highlight classifies src into spans of tag punctuations, element names,
+// attribute names, attribute values, texts, CDATAs, comments, processing
+// instructions and directives, ordered by their position. Whitespace
+// outside of those is not covered by any span. If src is not a complete
+// XML, e.g. while it is being edited, it returns the spans found so far
+// along with the error.
+func Highlight(src []byte) ([]Span, error) {
+	h := highlighter{src: src, pos: Pos{Line: 1, Column: 1}}
+	err := h.run(nil)
+	return h.spans, err
+}
+
+// Edit describes a change of the source, where the bytes in
+// [Offset, Offset+OldLen) are replaced with NewLen bytes.
+type Edit struct {
+	Offset int // Byte offset where the change begins.
+	OldLen int // Number of bytes replaced.
+	NewLen int // Number of bytes inserted.
+}
+
+// Rehighlight updates spans previously returned by Highlight or Rehighlight
+// after the given edit is applied, src is the source after the edit. Only the
+// region affected by the edit is highlighted again, the remaining spans are
+// reused by shifting their positions, so the spans are equal to the ones of
+// Highlight(src) at a fraction of the cost for large sources, even if src is
+// not a complete XML. The error however is only returned if it is found in the
+// region highlighted again, not if it follows that region in a source that was
+// already incomplete. The given spans are not modified.
+func Rehighlight(spans []Span, src []byte, edit Edit) ([]Span, error) {
+	// Restart from the token preceding the last token that begins before the
+	// edit, everything before it is not affected by the edit. The latter may
+	// no longer be a token, in which case the text preceding it is highlighted
+	// along with it as the text of an incomplete source.
+	k := sort.Search(len(spans), func(i int) bool { return spans[i].Begin.Offset >= edit.Offset })
+	for n := 0; n < 2; n++ {
+		for k > 0 && !isTokenStart(spans, k-1) {
+			k--
+		}
+		if k > 0 {
+			k--
+		}
+	}
+	h := highlighter{src: src, pos: Pos{Line: 1, Column: 1}}
+	if k > 0 {
+		h.pos = spans[k].Begin
+	}
+	h.spans = append(make([]Span, 0, len(spans)), spans[:k]...)
+
+	delta := edit.NewLen - edit.OldLen
+	var sync int // index of spans where the highlighting may be synchronized
+	for sync = k; sync < len(spans); sync++ {
+		if spans[sync].Begin.Offset >= edit.Offset+edit.OldLen && isTokenStart(spans, sync) {
+			break
+		}
+	}
+	err := h.run(func() bool {
+		if h.pos.Offset < edit.Offset+edit.NewLen {
+			return false
+		}
+		for sync < len(spans) && (spans[sync].Begin.Offset+delta < h.pos.Offset || !isTokenStart(spans, sync)) {
+			sync++
+		}
+		return sync < len(spans) && spans[sync].Begin.Offset+delta == h.pos.Offset
+	})
+	if err != nil || sync >= len(spans) || spans[sync].Begin.Offset+delta != h.pos.Offset {
+		return h.spans, err
+	}
+
+	// Synchronized, reuse the remaining spans.
+	oldPos, newPos := spans[sync].Begin, h.pos
+	shift := func(p Pos) Pos {
+		if p.Line == oldPos.Line {
+			p.Column += newPos.Column - oldPos.Column
+		}
+		p.Line += newPos.Line - oldPos.Line
+		p.Offset += delta
+		return p
+	}
+	for _, span := range spans[sync:] {
+		h.spans = append(h.spans, Span{Kind: span.Kind, Begin: shift(span.Begin), End: shift(span.End)})
+	}
+	return h.spans, nil
+}
+
+// isTokenStart reports whether spans[i] is the beginning of a token.
+func isTokenStart(spans []Span, i int) bool {
+	switch spans[i].Kind {
+	case SpanComment, SpanProcInst, SpanDirective:
+		return true
+	case SpanPunct:
+		return i+1 < len(spans) && spans[i+1].Kind == SpanElementName &&
+			spans[i+1].Begin.Offset-spans[i].End.Offset == 0
+	}
+	return false
+}
+
+// highlighter accumulates spans while walking through src.
+type highlighter struct {
+	src   []byte
+	pos   Pos // position of src[pos.Offset]
+	spans []Span
+}
+
+// run highlights src from the current position until the end of src or until
+// sync returns true. The sync is called on every token's beginning.
+func (h *highlighter) run(sync func() bool) error {
+	base := h.pos.Offset
+	tok := New(bytes.NewReader(h.src[base:]),
+		WithAutoGrowBufferMaxLimitSize(len(h.src)-base), // a token can not be larger than src
+	)
+	for {
+		raw, err := tok.RawToken()
+		if err != nil {
+			h.text(len(h.src))
+			if err == io.EOF {
+				err = nil
+			}
+			return err
+		}
+		h.text(base + tok.token.Begin.Offset)
+		if sync != nil && sync() {
+			return nil
+		}
+		h.token(raw)
+		h.advance(base + tok.token.End.Offset)
+	}
+}
+
+// advance moves the position to the given offset.
+func (h *highlighter) advance(offset int) {
+	h.pos.step(h.src[h.pos.Offset:offset])
+}
+
+// emit appends a span of the given kind from begin to end offset.
+func (h *highlighter) emit(kind SpanKind, begin, end int) {
+	if begin >= end {
+		return
+	}
+	h.advance(begin)
+	span := Span{Kind: kind, Begin: h.pos}
+	h.advance(end)
+	span.End = h.pos
+	h.spans = append(h.spans, span)
+}
+
+// text emits the text found between the current position and the given offset.
+func (h *highlighter) text(end int) {
+	b := h.src[h.pos.Offset:end]
+	begin := h.pos.Offset + len(b) - len(TrimLeftSpace(b))
+	h.emit(SpanText, begin, begin+len(TrimSpace(b)))
+	h.advance(end)
+}
+
+// token emits the spans of the given raw token that starts at the current position.
+func (h *highlighter) token(raw []byte) {
+	base := h.pos.Offset
+	switch {
+	case bytes.HasPrefix(raw, []byte("<!--")):
+		h.emit(SpanComment, base, base+len(raw))
+		return
+	case bytes.HasPrefix(raw, []byte("<?")):
+		h.emit(SpanProcInst, base, base+len(raw))
+		return
+	case bytes.HasPrefix(raw, []byte("<![CDATA[")):
+		h.charData(raw, base)
+		return
+	case bytes.HasPrefix(raw, []byte("<!")):
+		h.emit(SpanDirective, base, base+len(raw))
+		return
+	}
+
+	i := 1
+	if len(raw) > 1 && raw[1] == '/' {
+		i++
+	}
+	h.emit(SpanPunct, base, base+i)
+	j := i + indexAnyOrLen(raw[i:], " \t\r\n/>")
+	h.emit(SpanElementName, base+i, base+j)
+
+	for i = j; i < len(raw); {
+		i += len(raw[i:]) - len(TrimLeftSpace(raw[i:]))
+		if i >= len(raw) {
+			break
+		}
+		switch {
+		case raw[i] == '>':
+			h.emit(SpanPunct, base+i, base+i+1)
+			h.charData(raw[i+1:], base+i+1)
+			return
+		case raw[i] == '/' && i+1 < len(raw) && raw[i+1] == '>':
+			h.emit(SpanPunct, base+i, base+i+2)
+			h.charData(raw[i+2:], base+i+2)
+			return
+		case raw[i] == '=':
+			h.emit(SpanPunct, base+i, base+i+1)
+			i++
+		case raw[i] == '"' || raw[i] == '\'':
+			j = i + 1 + indexAnyOrLen(raw[i+1:], string(raw[i]))
+			j = min(j+1, len(raw))
+			h.emit(SpanAttrValue, base+i, base+j)
+			i = j
+		default:
+			j = i + max(1, indexAnyOrLen(raw[i:], " \t\r\n=/>"))
+			h.emit(SpanAttrName, base+i, base+j)
+			i = j
+		}
+	}
+}
+
+// charData emits the spans of the CharData and CDATA following a tag, or of a
+// standalone CDATA.
+func (h *highlighter) charData(b []byte, base int) {
+	const prefix = "<![CDATA["
+	p := bytes.Index(b, []byte(prefix))
+	if p == -1 {
+		h.emit(SpanText, base+len(b)-len(TrimLeftSpace(b)), base+len(TrimRightSpace(b)))
+		return
+	}
+	text := b[:p]
+	h.emit(SpanText, base+len(text)-len(TrimLeftSpace(text)), base+len(TrimRightSpace(text)))
+	h.emit(SpanCDATA, base+p, base+len(TrimRightSpace(b)))
+}
+
+// indexAnyOrLen returns the index of the first occurrence of any of chars in b,
+// or len(b) if none of chars is present.
+func indexAnyOrLen(b []byte, chars string) int {
+	if p := bytes.IndexAny(b, chars); p != -1 {
+		return p
+	}
+	return len(b)
+}
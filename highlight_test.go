@@ -0,0 +1,226 @@
+package xmltokenizer_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/muktihari/xmltokenizer"
+)
+
+func TestHighlight(t *testing.T) {
+	type span struct {
+		Kind xmltokenizer.SpanKind
+		Text string
+	}
+	tt := []struct {
+		name     string
+		xml      string
+		expected []span
+		err      error
+	}{
+		{
+			name: "all kinds",
+			xml: `<?xml version="1.0"?>
+<!DOCTYPE note>
+<note id='1' lang = "en"><!-- comment -->
+  <to>Tove</to>tail
+  <data> <![CDATA[x<y]]> </data>
+  <empty />
+</note>`,
+			expected: []span{
+				{xmltokenizer.SpanProcInst, `<?xml version="1.0"?>`},
+				{xmltokenizer.SpanDirective, `<!DOCTYPE note>`},
+				{xmltokenizer.SpanPunct, `<`},
+				{xmltokenizer.SpanElementName, `note`},
+				{xmltokenizer.SpanAttrName, `id`},
+				{xmltokenizer.SpanPunct, `=`},
+				{xmltokenizer.SpanAttrValue, `'1'`},
+				{xmltokenizer.SpanAttrName, `lang`},
+				{xmltokenizer.SpanPunct, `=`},
+				{xmltokenizer.SpanAttrValue, `"en"`},
+				{xmltokenizer.SpanPunct, `>`},
+				{xmltokenizer.SpanComment, `<!-- comment -->`},
+				{xmltokenizer.SpanPunct, `<`},
+				{xmltokenizer.SpanElementName, `to`},
+				{xmltokenizer.SpanPunct, `>`},
+				{xmltokenizer.SpanText, `Tove`},
+				{xmltokenizer.SpanPunct, `</`},
+				{xmltokenizer.SpanElementName, `to`},
+				{xmltokenizer.SpanPunct, `>`},
+				{xmltokenizer.SpanText, `tail`},
+				{xmltokenizer.SpanPunct, `<`},
+				{xmltokenizer.SpanElementName, `data`},
+				{xmltokenizer.SpanPunct, `>`},
+				{xmltokenizer.SpanCDATA, `<![CDATA[x<y]]>`},
+				{xmltokenizer.SpanPunct, `</`},
+				{xmltokenizer.SpanElementName, `data`},
+				{xmltokenizer.SpanPunct, `>`},
+				{xmltokenizer.SpanPunct, `<`},
+				{xmltokenizer.SpanElementName, `empty`},
+				{xmltokenizer.SpanPunct, `/>`},
+				{xmltokenizer.SpanPunct, `</`},
+				{xmltokenizer.SpanElementName, `note`},
+				{xmltokenizer.SpanPunct, `>`},
+			},
+		},
+		{
+			name: "text after self-closing",
+			xml:  `<a><b/>tail</a>`,
+			expected: []span{
+				{xmltokenizer.SpanPunct, `<`},
+				{xmltokenizer.SpanElementName, `a`},
+				{xmltokenizer.SpanPunct, `>`},
+				{xmltokenizer.SpanPunct, `<`},
+				{xmltokenizer.SpanElementName, `b`},
+				{xmltokenizer.SpanPunct, `/>`},
+				{xmltokenizer.SpanText, `tail`},
+				{xmltokenizer.SpanPunct, `</`},
+				{xmltokenizer.SpanElementName, `a`},
+				{xmltokenizer.SpanPunct, `>`},
+			},
+		},
+		{
+			name: "standalone cdata",
+			xml:  `<a><!--c--><![CDATA[x]]></a>`,
+			expected: []span{
+				{xmltokenizer.SpanPunct, `<`},
+				{xmltokenizer.SpanElementName, `a`},
+				{xmltokenizer.SpanPunct, `>`},
+				{xmltokenizer.SpanComment, `<!--c-->`},
+				{xmltokenizer.SpanCDATA, `<![CDATA[x]]>`},
+				{xmltokenizer.SpanPunct, `</`},
+				{xmltokenizer.SpanElementName, `a`},
+				{xmltokenizer.SpanPunct, `>`},
+			},
+		},
+		{
+			name: "incomplete",
+			xml:  `<a>text</a><b x="`,
+			expected: []span{
+				{xmltokenizer.SpanPunct, `<`},
+				{xmltokenizer.SpanElementName, `a`},
+				{xmltokenizer.SpanPunct, `>`},
+				{xmltokenizer.SpanText, `text`},
+				{xmltokenizer.SpanPunct, `</`},
+				{xmltokenizer.SpanElementName, `a`},
+				{xmltokenizer.SpanPunct, `>`},
+				{xmltokenizer.SpanText, `<b x="`},
+			},
+			err: io.ErrUnexpectedEOF,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			spans, err := xmltokenizer.Highlight([]byte(tc.xml))
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expected error: %v, got: %v", tc.err, err)
+			}
+			result := make([]span, 0, len(spans))
+			for _, s := range spans {
+				result = append(result, span{s.Kind, tc.xml[s.Begin.Offset:s.End.Offset]})
+			}
+			if diff := cmp.Diff(result, tc.expected); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
+
+func TestHighlightPos(t *testing.T) {
+	spans, err := xmltokenizer.Highlight([]byte("<a>\n  <b c=\"白\"/>\n</a>"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := xmltokenizer.Span{
+		Kind:  xmltokenizer.SpanAttrValue,
+		Begin: xmltokenizer.Pos{Line: 2, Column: 8, Offset: 11},
+		End:   xmltokenizer.Pos{Line: 2, Column: 11, Offset: 16},
+	}
+	if diff := cmp.Diff(spans[7], expected); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestRehighlight(t *testing.T) {
+	const src = `<?xml version="1.0"?>
+<gpx creator="me">
+  <trk>
+    <name>Ride</name>
+    <trkpt lat="1" lon="2"><ele>10</ele></trkpt>
+    <!-- comment -->
+    <trkpt lat="3" lon="4"><ele>20</ele></trkpt>
+  </trk>
+</gpx>`
+	tt := []struct {
+		name    string
+		offset  int
+		oldText string
+		newText string
+	}{
+		{name: "change attribute value", oldText: `lat="1"`, newText: `lat="1.5"`},
+		{name: "insert element with newlines", oldText: "<!-- comment -->", newText: "<a>\n\n</a>\n    <!-- comment -->"},
+		{name: "delete across tokens", oldText: "<ele>10</ele></trkpt>\n    <!--", newText: "<!--"},
+		{name: "open a comment", oldText: `<name>`, newText: `<!--<name>`},
+		{name: "edit the first token", oldText: `<?xml`, newText: `<?xml `},
+		{name: "edit the last token", oldText: `</gpx>`, newText: `</gpx`},
+		{name: "insert text between tokens", oldText: "</trk>\n</gpx>", newText: "</trk>\ntail</gpx>"},
+		{name: "insert text after self-closing", oldText: "<!-- comment -->", newText: "<b/>tail<!-- comment -->"},
+		{name: "insert standalone cdata", oldText: "<!-- comment -->", newText: "<!-- comment --><![CDATA[x]]>"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			spans, err := xmltokenizer.Highlight([]byte(src))
+			if err != nil {
+				t.Fatal(err)
+			}
+			offset := strings.Index(src, tc.oldText)
+			newSrc := []byte(src[:offset] + tc.newText + src[offset+len(tc.oldText):])
+			edit := xmltokenizer.Edit{Offset: offset, OldLen: len(tc.oldText), NewLen: len(tc.newText)}
+
+			expected, expectedErr := xmltokenizer.Highlight(newSrc)
+			result, err := xmltokenizer.Rehighlight(spans, newSrc, edit)
+			if !errors.Is(err, expectedErr) {
+				t.Fatalf("expected error: %v, got: %v", expectedErr, err)
+			}
+			if diff := cmp.Diff(result, expected); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
+
+func TestRehighlightIncomplete(t *testing.T) {
+	tt := []struct {
+		name string
+		src  string
+	}{
+		{name: "text before the first tag", src: `é<b x="1">`},
+		{name: "text between tags", src: `<a>é <b x="1">t</b> <!-- c --> u <c/>v<![CDATA[w]]></a>`},
+		{name: "document", src: "<?xml version=\"1.0\"?>\n<r a='1'>\n <x>1</x>\n</r>"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			spans, _ := xmltokenizer.Highlight([]byte(tc.src))
+			rehighlight := func(newSrc string, edit xmltokenizer.Edit) {
+				expected, _ := xmltokenizer.Highlight([]byte(newSrc))
+				result, _ := xmltokenizer.Rehighlight(spans, []byte(newSrc), edit)
+				if diff := cmp.Diff(result, expected); diff != "" {
+					t.Fatalf("%q: %s", newSrc, diff)
+				}
+			}
+			// Every byte deleted or preceded by an inserted tag punctuation.
+			for i := 0; i < len(tc.src); i++ {
+				rehighlight(tc.src[:i]+tc.src[i+1:], xmltokenizer.Edit{Offset: i, OldLen: 1})
+				for _, c := range []string{"<", ">", `"`} {
+					rehighlight(tc.src[:i]+c+tc.src[i:], xmltokenizer.Edit{Offset: i, NewLen: 1})
+				}
+			}
+		})
+	}
+}
@@ -26,7 +26,7 @@ func (t *TrackpointExtension) reset() {
 	t.Power = math.MaxUint16
 }
 
-func (t *TrackpointExtension) UnmarshalToken(tok *xmltokenizer.Tokenizer, se *xmltokenizer.Token) error {
+func (t *TrackpointExtension) UnmarshalToken(tok xmltokenizer.TokenSource, se *xmltokenizer.Token) error {
 	t.reset()
 
 	for {
@@ -15,7 +15,7 @@ type GPX struct {
 	Tracks   []Track  `xml:"trk,omitempty"`
 }
 
-func (g *GPX) UnmarshalToken(tok *xmltokenizer.Tokenizer, se *xmltokenizer.Token) error {
+func (g *GPX) UnmarshalToken(tok xmltokenizer.TokenSource, se *xmltokenizer.Token) error {
 	for i := range se.Attrs {
 		attr := &se.Attrs[i]
 		switch string(attr.Name.Local) {
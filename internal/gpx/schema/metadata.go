@@ -17,7 +17,7 @@ type Metadata struct {
 	Time   time.Time `xml:"time,omitempty"`
 }
 
-func (m *Metadata) UnmarshalToken(tok *xmltokenizer.Tokenizer, se *xmltokenizer.Token) error {
+func (m *Metadata) UnmarshalToken(tok xmltokenizer.TokenSource, se *xmltokenizer.Token) error {
 	for {
 		token, err := tok.Token()
 		if err != nil {
@@ -113,7 +113,7 @@ type Author struct {
 	Link *Link  `xml:"link"`
 }
 
-func (a *Author) UnmarshalToken(tok *xmltokenizer.Tokenizer, se *xmltokenizer.Token) error {
+func (a *Author) UnmarshalToken(tok xmltokenizer.TokenSource, se *xmltokenizer.Token) error {
 	for {
 		token, err := tok.Token()
 		if err != nil {
@@ -181,7 +181,7 @@ type Link struct {
 	Type string `xml:"type,omitempty"`
 }
 
-func (a *Link) UnmarshalToken(tok *xmltokenizer.Tokenizer, se *xmltokenizer.Token) error {
+func (a *Link) UnmarshalToken(tok xmltokenizer.TokenSource, se *xmltokenizer.Token) error {
 	for i := range se.Attrs {
 		attr := &se.Attrs[i]
 		switch string(attr.Name.Local) {
@@ -16,7 +16,7 @@ type Track struct {
 	TrackSegments []TrackSegment `xml:"trkseg,omitempty"`
 }
 
-func (t *Track) UnmarshalToken(tok *xmltokenizer.Tokenizer, se *xmltokenizer.Token) error {
+func (t *Track) UnmarshalToken(tok xmltokenizer.TokenSource, se *xmltokenizer.Token) error {
 	for {
 		token, err := tok.Token()
 		if err != nil {
@@ -88,7 +88,7 @@ type TrackSegment struct {
 	Trackpoints []Waypoint `xml:"trkpt,omitempty"`
 }
 
-func (t *TrackSegment) UnmarshalToken(tok *xmltokenizer.Tokenizer, se *xmltokenizer.Token) error {
+func (t *TrackSegment) UnmarshalToken(tok xmltokenizer.TokenSource, se *xmltokenizer.Token) error {
 	for {
 		token, err := tok.Token()
 		if err != nil {
@@ -157,7 +157,7 @@ func (w *Waypoint) reset() {
 	w.TrackpointExtension.reset()
 }
 
-func (w *Waypoint) UnmarshalToken(tok *xmltokenizer.Tokenizer, se *xmltokenizer.Token) error {
+func (w *Waypoint) UnmarshalToken(tok xmltokenizer.TokenSource, se *xmltokenizer.Token) error {
 	w.reset()
 
 	var err error
@@ -56,7 +56,7 @@ type Row struct {
 	Cells []Cell `xml:"c"`
 }
 
-func (r *Row) UnmarshalToken(tok *xmltokenizer.Tokenizer, se *xmltokenizer.Token) error {
+func (r *Row) UnmarshalToken(tok xmltokenizer.TokenSource, se *xmltokenizer.Token) error {
 	var err error
 	for i := range se.Attrs {
 		attr := &se.Attrs[i]
@@ -100,7 +100,7 @@ type Cell struct {
 	Value     string `xml:"v,omitempty"`
 }
 
-func (c *Cell) UnmarshalToken(tok *xmltokenizer.Tokenizer, se *xmltokenizer.Token) error {
+func (c *Cell) UnmarshalToken(tok xmltokenizer.TokenSource, se *xmltokenizer.Token) error {
 	for i := range se.Attrs {
 		attr := &se.Attrs[i]
 		switch string(attr.Name.Local) {
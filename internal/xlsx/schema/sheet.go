@@ -11,7 +11,7 @@ type SheetData struct {
 	Rows []Row `xml:"row,omitempty"`
 }
 
-func (s *SheetData) UnmarshalToken(tok *xmltokenizer.Tokenizer, se *xmltokenizer.Token) error {
+func (s *SheetData) UnmarshalToken(tok xmltokenizer.TokenSource, se *xmltokenizer.Token) error {
 	for {
 		token, err := tok.Token()
 		if err != nil {
@@ -45,7 +45,7 @@ type Row struct {
 	Cells []Cell `xml:"c"`
 }
 
-func (r *Row) UnmarshalToken(tok *xmltokenizer.Tokenizer, se *xmltokenizer.Token) error {
+func (r *Row) UnmarshalToken(tok xmltokenizer.TokenSource, se *xmltokenizer.Token) error {
 	var err error
 	for i := range se.Attrs {
 		attr := &se.Attrs[i]
@@ -95,7 +95,7 @@ type Cell struct {
 	InlineString string `xml:"is>t"`
 }
 
-func (c *Cell) UnmarshalToken(tok *xmltokenizer.Tokenizer, se *xmltokenizer.Token) error {
+func (c *Cell) UnmarshalToken(tok xmltokenizer.TokenSource, se *xmltokenizer.Token) error {
 	var err error
 	for i := range se.Attrs {
 		attr := &se.Attrs[i]
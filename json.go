@@ -0,0 +1,85 @@
+package xmltokenizer
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// MarshalJSON implements json.Marshaler, the Name is represented by its Full
+// name as a string, e.g. "gpxtpx:atemp", or by an object along with its
+// namespace URI if it is resolved, see WithNamespaceResolution, e.g.
+// {"full":"gpxtpx:atemp","space":"http://www.garmin.com/xmlschemas/TrackPointExtension/v1"}.
+func (n Name) MarshalJSON() ([]byte, error) {
+	if len(n.Space) == 0 {
+		return marshalJSON(string(n.Full))
+	}
+	return marshalJSON(struct {
+		Full  string `json:"full"`
+		Space string `json:"space"`
+	}{string(n.Full), string(n.Space)})
+}
+
+// MarshalJSON implements json.Marshaler, e.g. {"name":"lat","value":"47.1"}.
+func (a Attr) MarshalJSON() ([]byte, error) {
+	return marshalJSON(struct {
+		Name  Name   `json:"name"`
+		Value string `json:"value"`
+	}{a.Name, string(a.Value)})
+}
+
+// MarshalJSON implements json.Marshaler, e.g. {"line":3,"column":5,"offset":42}.
+func (p Pos) MarshalJSON() ([]byte, error) {
+	return marshalJSON(struct {
+		Line   int `json:"line"`
+		Column int `json:"column"`
+		Offset int `json:"offset"`
+	}{p.Line, p.Column, p.Offset})
+}
+
+// MarshalJSON implements json.Marshaler so tokens can be logged or
+// snapshotted in a readable form: byte slices are represented as strings and
+// empty fields are omitted, e.g.
+//
+//	{"name":"trkpt","attrs":[{"name":"lat","value":"47.1"}],"begin":{...},"end":{...}}
+func (t Token) MarshalJSON() ([]byte, error) {
+	return marshalJSON(struct {
+		Name         *Name  `json:"name,omitempty"`
+		Attrs        []Attr `json:"attrs,omitempty"`
+		Data         string `json:"data,omitempty"`
+		SelfClosing  bool   `json:"selfClosing,omitempty"`
+		IsEndElement bool   `json:"isEndElement,omitempty"`
+		Continued    bool   `json:"continued,omitempty"`
+		Begin        Pos    `json:"begin"`
+		End          Pos    `json:"end"`
+	}{
+		Name:         nonEmptyName(&t.Name),
+		Attrs:        t.Attrs,
+		Data:         string(t.Data),
+		SelfClosing:  t.SelfClosing,
+		IsEndElement: t.IsEndElement,
+		Continued:    t.Continued,
+		Begin:        t.Begin,
+		End:          t.End,
+	})
+}
+
+func nonEmptyName(n *Name) *Name {
+	if len(n.Full) == 0 {
+		return nil
+	}
+	return n
+}
+
+// marshalJSON is like json.Marshal without escaping '<', '>' and '&', which
+// are common in tokens, so the output is readable. json.Marshal still escapes
+// them when marshaling a value containing tokens, unlike a json.Encoder with
+// SetEscapeHTML(false).
+func marshalJSON(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
@@ -0,0 +1,65 @@
+package xmltokenizer_test
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/muktihari/xmltokenizer"
+)
+
+func TestTokenMarshalJSON(t *testing.T) {
+	const s = `<?xml version="1.0"?><gpx:trkpt lat="47.1">12</gpx:trkpt><br/>`
+	tok := xmltokenizer.New(strings.NewReader(s))
+	var result []string
+	for {
+		token, err := tok.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := json.Marshal(token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result = append(result, string(b))
+	}
+
+	expected := []string{
+		`{"data":"\u003c?xml version=\"1.0\"?\u003e","selfClosing":true,"begin":{"line":1,"column":1,"offset":0},"end":{"line":1,"column":22,"offset":21}}`,
+		`{"name":"gpx:trkpt","attrs":[{"name":"lat","value":"47.1"}],"data":"12","begin":{"line":1,"column":22,"offset":21},"end":{"line":1,"column":46,"offset":45}}`,
+		`{"name":"gpx:trkpt","isEndElement":true,"begin":{"line":1,"column":46,"offset":45},"end":{"line":1,"column":58,"offset":57}}`,
+		`{"name":"br","selfClosing":true,"begin":{"line":1,"column":58,"offset":57},"end":{"line":1,"column":63,"offset":62}}`,
+	}
+	if diff := cmp.Diff(result, expected); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestTokenMarshalJSONNamespace(t *testing.T) {
+	const s = `<gpx:trkpt xmlns:gpx="urn:gpx" gpx:lat="47.1" lon="8.5"/>`
+	tok := xmltokenizer.New(strings.NewReader(s), xmltokenizer.WithNamespaceResolution())
+	token, err := tok.Token()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := json.Marshal(struct {
+		Name  xmltokenizer.Name   `json:"name"`
+		Attrs []xmltokenizer.Attr `json:"attrs"`
+	}{token.Name, token.Attrs})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"name":{"full":"gpx:trkpt","space":"urn:gpx"},"attrs":[` +
+		`{"name":{"full":"xmlns:gpx","space":"http://www.w3.org/2000/xmlns/"},"value":"urn:gpx"},` +
+		`{"name":{"full":"gpx:lat","space":"urn:gpx"},"value":"47.1"},` +
+		`{"name":"lon","value":"8.5"}]}`
+	if diff := cmp.Diff(string(b), expected); diff != "" {
+		t.Fatal(diff)
+	}
+}
@@ -0,0 +1,337 @@
+// Package junit streams the test cases of JUnit XML reports, the de facto
+// format of test results written by JUnit, Surefire, pytest, go-junit-report
+// and most CI tools, using the xmltokenizer. Only the test case being read is
+// held in memory, so reports of any size can be aggregated.
+package junit
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/muktihari/xmltokenizer"
+)
+
+type errorString string
+
+func (e errorString) Error() string { return string(e) }
+
+const errInvalidAttr = errorString("invalid attribute")
+
+// Status is the outcome of a test case.
+type Status int
+
+const (
+	// Passed is the status of a test case with neither failure, error nor skipped.
+	Passed Status = iota
+	// Failed is the status of a test case with a <failure>, an assertion failed.
+	Failed
+	// Errored is the status of a test case with an <error>, e.g. an unexpected exception.
+	Errored
+	// Skipped is the status of a test case with a <skipped>.
+	Skipped
+)
+
+func (s Status) String() string {
+	switch s {
+	case Passed:
+		return "passed"
+	case Failed:
+		return "failed"
+	case Errored:
+		return "errored"
+	case Skipped:
+		return "skipped"
+	}
+	return "Status(" + strconv.Itoa(int(s)) + ")"
+}
+
+// TestSuite is a <testsuite> as described by its attributes, its counts are
+// the ones reported, they are not verified against its test cases.
+type TestSuite struct {
+	Name      string
+	Package   string
+	Hostname  string
+	Timestamp string
+	Tests     int
+	Failures  int
+	Errors    int
+	Skipped   int
+	Time      time.Duration
+}
+
+// TestCase is a <testcase>.
+type TestCase struct {
+	Suite     string // Name of the innermost enclosing test suite.
+	Name      string
+	Classname string
+	File      string
+	Time      time.Duration
+	Status    Status
+	Message   string // Message attribute of the failure, error or skipped element.
+	Type      string // Type attribute of the failure or error element, e.g. an exception class.
+	Text      string // Text of the failure, error or skipped element, e.g. a stack trace.
+	SystemOut string
+	SystemErr string
+}
+
+// Reader reads the test cases of a report.
+type Reader struct {
+	tok    *xmltokenizer.Tokenizer
+	suites []TestSuite
+	buf    []byte
+}
+
+// NewReader creates a Reader reading the report from r, the options are
+// passed to the underlying Tokenizer.
+func NewReader(r io.Reader, opts ...xmltokenizer.Option) *Reader {
+	return &Reader{tok: xmltokenizer.New(r, opts...)}
+}
+
+// Suite returns the innermost test suite enclosing the test case last
+// returned by Next, or nil if there is none, e.g. for a bare <testcase>.
+func (r *Reader) Suite() *TestSuite {
+	if len(r.suites) == 0 {
+		return nil
+	}
+	return &r.suites[len(r.suites)-1]
+}
+
+// Next returns the next test case, it returns io.EOF at the end of the report.
+// Elements other than test suites and test cases, such as <properties>, are
+// skipped.
+func (r *Reader) Next() (TestCase, error) {
+	for {
+		token, err := r.tok.Token()
+		if err != nil {
+			return TestCase{}, err
+		}
+		if len(token.Name.Full) == 0 {
+			continue
+		}
+		switch string(token.Name.Local) {
+		case "testsuite":
+			if token.IsEndElement {
+				if len(r.suites) > 0 {
+					r.suites = r.suites[:len(r.suites)-1]
+				}
+				continue
+			}
+			suite, err := parseSuite(&token)
+			if err != nil {
+				return TestCase{}, err
+			}
+			if !token.SelfClosing {
+				r.suites = append(r.suites, suite)
+			}
+		case "testcase":
+			if !token.IsEndElement {
+				return r.testCase(&token)
+			}
+		}
+	}
+}
+
+// testCase reads the test case started by se up to its end element.
+func (r *Reader) testCase(se *xmltokenizer.Token) (TestCase, error) {
+	tc := TestCase{
+		Name:      attrValue(se, "name"),
+		Classname: attrValue(se, "classname"),
+		File:      attrValue(se, "file"),
+	}
+	if suite := r.Suite(); suite != nil {
+		tc.Suite = suite.Name
+	}
+	var err error
+	if tc.Time, err = parseTime(se); err != nil {
+		return tc, err
+	}
+	for depth := 1; depth > 0 && !se.SelfClosing; {
+		token, err := r.tok.Token()
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return tc, err
+		}
+		if len(token.Name.Full) == 0 {
+			continue
+		}
+		if token.IsEndElement {
+			depth--
+			continue
+		}
+		level := depth
+		if !token.SelfClosing {
+			depth++
+		}
+		if level > 1 { // nested in a child of the test case
+			continue
+		}
+		var status Status
+		switch string(token.Name.Local) {
+		case "failure":
+			status = Failed
+		case "error":
+			status = Errored
+		case "skipped":
+			status = Skipped
+		case "system-out":
+			tc.SystemOut = r.text()
+			continue
+		case "system-err":
+			tc.SystemErr = r.text()
+			continue
+		default:
+			continue
+		}
+		if tc.Status != Passed { // keep the first outcome
+			continue
+		}
+		tc.Status = status
+		tc.Message = attrValue(&token, "message")
+		tc.Type = attrValue(&token, "type")
+		tc.Text = r.text()
+	}
+	return tc, nil
+}
+
+// text returns the text following the last start element returned by Token,
+// with the entities decoded and CDATA sections unwrapped.
+func (r *Reader) text() string {
+	const prefix, suffix = "<![CDATA[", "]]>"
+	b := bytes.TrimSpace(charDataOf(r.tok.Raw()))
+	r.buf = r.buf[:0]
+	for len(b) > 0 {
+		i := bytes.Index(b, []byte(prefix))
+		if i == -1 {
+			r.buf = xmltokenizer.Attr{Value: b}.ValueUnescaped(r.buf)
+			break
+		}
+		r.buf = xmltokenizer.Attr{Value: b[:i]}.ValueUnescaped(r.buf)
+		b = b[i+len(prefix):]
+		end := bytes.Index(b, []byte(suffix))
+		if end == -1 {
+			end = len(b)
+		}
+		r.buf = append(r.buf, b[:end]...)
+		b = b[min(end+len(suffix), len(b)):]
+	}
+	return string(r.buf)
+}
+
+// charDataOf returns the raw CharData or CDATA following the tag of a raw start element.
+func charDataOf(raw []byte) []byte {
+	var quote byte
+	for i, c := range raw {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '>':
+			return raw[i+1:]
+		}
+	}
+	return nil
+}
+
+func parseSuite(token *xmltokenizer.Token) (TestSuite, error) {
+	suite := TestSuite{
+		Name:      attrValue(token, "name"),
+		Package:   attrValue(token, "package"),
+		Hostname:  attrValue(token, "hostname"),
+		Timestamp: attrValue(token, "timestamp"),
+	}
+	for _, field := range []struct {
+		name string
+		dst  *int
+	}{
+		{"tests", &suite.Tests},
+		{"failures", &suite.Failures},
+		{"errors", &suite.Errors},
+		{"skipped", &suite.Skipped},
+	} {
+		s := attrValue(token, field.name)
+		if s == "" {
+			continue
+		}
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return suite, fmt.Errorf("%s at line %d: %w: %s=%q", token.Name.Full, token.Begin.Line, errInvalidAttr, field.name, s)
+		}
+		*field.dst = n
+	}
+	var err error
+	suite.Time, err = parseTime(token)
+	return suite, err
+}
+
+// parseTime parses the time attribute, in seconds, of the given token.
+// Thousands separators, written by some tools, are ignored.
+func parseTime(token *xmltokenizer.Token) (time.Duration, error) {
+	s := attrValue(token, "time")
+	if s == "" {
+		return 0, nil
+	}
+	f, err := strconv.ParseFloat(strings.ReplaceAll(s, ",", ""), 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s at line %d: %w: time=%q", token.Name.Full, token.Begin.Line, errInvalidAttr, s)
+	}
+	return time.Duration(f * float64(time.Second)), nil
+}
+
+// Totals are the counts of test cases by status and their total time.
+type Totals struct {
+	Tests    int
+	Failures int
+	Errors   int
+	Skipped  int
+	Time     time.Duration
+}
+
+// Add counts the test case.
+func (t *Totals) Add(tc *TestCase) {
+	t.Tests++
+	switch tc.Status {
+	case Failed:
+		t.Failures++
+	case Errored:
+		t.Errors++
+	case Skipped:
+		t.Skipped++
+	}
+	t.Time += tc.Time
+}
+
+// Summarize reads every test case of the report from r and returns their
+// Totals, regardless of the counts reported by the test suites.
+func Summarize(r io.Reader, opts ...xmltokenizer.Option) (Totals, error) {
+	var totals Totals
+	rd := NewReader(r, opts...)
+	for {
+		tc, err := rd.Next()
+		if err == io.EOF {
+			return totals, nil
+		}
+		if err != nil {
+			return totals, err
+		}
+		totals.Add(&tc)
+	}
+}
+
+// attrValue returns the unescaped value of the attribute with the given local name.
+func attrValue(token *xmltokenizer.Token, local string) string {
+	for i := range token.Attrs {
+		if string(token.Attrs[i].Name.Local) == local {
+			return string(token.Attrs[i].ValueUnescaped(nil))
+		}
+	}
+	return ""
+}
@@ -0,0 +1,115 @@
+package junit_test
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/muktihari/xmltokenizer/junit"
+)
+
+const report = `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites name="all" tests="5" failures="1" errors="1">
+  <testsuite name="pkg.MathTest" tests="4" failures="1" errors="1" skipped="1" time="1,001.5" timestamp="2024-03-01T10:20:30">
+    <properties>
+      <property name="java.version" value="21"/>
+    </properties>
+    <testcase name="testAdd" classname="pkg.MathTest" time="0.25"/>
+    <testcase name="testDiv" classname="pkg.MathTest" time="0.5">
+      <failure message="expected: &lt;2&gt; but was: &lt;3&gt;" type="AssertionError"><![CDATA[AssertionError: expected <2>
+	at pkg.MathTest.testDiv(MathTest.java:12)]]></failure>
+      <system-out>dividing &amp; conquering</system-out>
+    </testcase>
+    <testcase name="testMod" classname="pkg.MathTest">
+      <error type="ArithmeticException">/ by zero</error>
+      <rerunFailure message="ignored"/>
+    </testcase>
+    <testcase name="testPow" classname="pkg.MathTest">
+      <skipped message="not implemented"/>
+    </testcase>
+  </testsuite>
+  <testsuite name="other" tests="1">
+    <testcase name="TestOK" file="ok_test.go" time="1"></testcase>
+  </testsuite>
+</testsuites>`
+
+func TestReader(t *testing.T) {
+	r := junit.NewReader(strings.NewReader(report))
+	var cases []junit.TestCase
+	var suites []junit.TestSuite
+	for {
+		tc, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		cases = append(cases, tc)
+		suites = append(suites, *r.Suite())
+	}
+
+	expected := []junit.TestCase{
+		{Suite: "pkg.MathTest", Name: "testAdd", Classname: "pkg.MathTest", Time: 250 * time.Millisecond},
+		{
+			Suite: "pkg.MathTest", Name: "testDiv", Classname: "pkg.MathTest", Time: 500 * time.Millisecond,
+			Status: junit.Failed, Message: "expected: <2> but was: <3>", Type: "AssertionError",
+			Text:      "AssertionError: expected <2>\n\tat pkg.MathTest.testDiv(MathTest.java:12)",
+			SystemOut: "dividing & conquering",
+		},
+		{
+			Suite: "pkg.MathTest", Name: "testMod", Classname: "pkg.MathTest",
+			Status: junit.Errored, Type: "ArithmeticException", Text: "/ by zero",
+		},
+		{Suite: "pkg.MathTest", Name: "testPow", Classname: "pkg.MathTest", Status: junit.Skipped, Message: "not implemented"},
+		{Suite: "other", Name: "TestOK", File: "ok_test.go", Time: time.Second},
+	}
+	if diff := cmp.Diff(cases, expected); diff != "" {
+		t.Fatal(diff)
+	}
+
+	suite := junit.TestSuite{
+		Name: "pkg.MathTest", Timestamp: "2024-03-01T10:20:30",
+		Tests: 4, Failures: 1, Errors: 1, Skipped: 1, Time: 1001500 * time.Millisecond,
+	}
+	if diff := cmp.Diff(suites[0], suite); diff != "" {
+		t.Fatal(diff)
+	}
+	if name := suites[4].Name; name != "other" {
+		t.Fatalf("expected suite: other, got: %s", name)
+	}
+}
+
+func TestReaderErrors(t *testing.T) {
+	tt := []struct {
+		name string
+		in   string
+		err  string
+	}{
+		{name: "invalid count", in: `<testsuite tests="many"></testsuite>`, err: `testsuite at line 1: invalid attribute: tests="many"`},
+		{name: "invalid time", in: "<testsuite>\n<testcase time=\"1s\"/></testsuite>", err: `testcase at line 2: invalid attribute: time="1s"`},
+		{name: "unclosed test case", in: `<testcase><failure>`, err: io.ErrUnexpectedEOF.Error()},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := junit.NewReader(strings.NewReader(tc.in)).Next()
+			if err == nil || err.Error() != tc.err {
+				t.Fatalf("expected error: %s, got: %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	totals, err := junit.Summarize(strings.NewReader(report))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := junit.Totals{Tests: 5, Failures: 1, Errors: 1, Skipped: 1, Time: 1750 * time.Millisecond}
+	if diff := cmp.Diff(totals, expected); diff != "" {
+		t.Fatal(diff)
+	}
+}
@@ -0,0 +1,151 @@
+package xmltokenizer
+
+import (
+	"bytes"
+	"strconv"
+)
+
+// Kind is the kind of a token.
+type Kind uint8
+
+const (
+	StartElement Kind = iota + 1 // <name attr="value">, along with its CharData if any.
+	EndElement                   // </name>
+	SelfClosing                  // <name attr="value"/>
+	CharData                     // A chunk of CharData continued from the previous token, see WithChunkedCharData.
+	CDATA                        // <![CDATA[ CharData ]]>, or a chunk of it continued from the previous token.
+	Comment                      // <!-- a comment -->
+	ProcInst                     // <?target inst?>, such as the XML declaration.
+	Directive                    // <!DOCTYPE ...> or any other "<!" markup.
+)
+
+var kindNames = [...]string{
+	StartElement: "StartElement",
+	EndElement:   "EndElement",
+	SelfClosing:  "SelfClosing",
+	CharData:     "CharData",
+	CDATA:        "CDATA",
+	Comment:      "Comment",
+	ProcInst:     "ProcInst",
+	Directive:    "Directive",
+}
+
+func (k Kind) String() string {
+	if int(k) < len(kindNames) && kindNames[k] != "" {
+		return kindNames[k]
+	}
+	return "Kind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// TokenInfo is the kind, the name and the raw bytes of a token, see
+// RawTokenInfo.
+type TokenInfo struct {
+	Kind       Kind
+	Name       Name   // Name of an element, empty for any other kind. It refers to Raw.
+	Raw        []byte // Raw bytes of the token, as returned by RawToken.
+	Begin, End Pos    // Begin and end of this token within the stream.
+}
+
+// RawTokenInfo returns the next raw token along with its kind and its name,
+// for consumers such as splitters and indexers that only need to know which
+// element a raw token is: unlike Token, it neither parses the attributes nor
+// trims the CharData. Like RawToken, it does not keep track of the elements
+// and namespaces, and the names are returned as they are read regardless of
+// WithCaseFold and WithNamespaceAliases. The returned TokenInfo is only
+// valid before next Token or RawToken method invocation.
+func (t *Tokenizer) RawTokenInfo() (TokenInfo, error) {
+	b, err := t.RawToken()
+	if err != nil {
+		return TokenInfo{}, err
+	}
+	info := TokenInfo{Raw: b, Begin: t.token.Begin, End: t.token.End}
+	if t.continued {
+		info.Kind = CharData
+		if t.lastChunk == chunkCDATA {
+			info.Kind = CDATA
+		}
+		return info, nil
+	}
+	info.Kind, info.Name = classify(b)
+	return info, nil
+}
+
+// Kind returns the kind of the token inferred from its fields, or 0 for the
+// zero Token. A Continued token is CharData, even when it is a chunk of a
+// CDATA section, see Tokenizer.Kind.
+func (t *Token) Kind() Kind {
+	switch {
+	case t.Continued:
+		return CharData
+	case t.IsEndElement:
+		return EndElement
+	case len(t.Name.Full) > 0 && t.SelfClosing:
+		return SelfClosing
+	case len(t.Name.Full) > 0:
+		return StartElement
+	}
+	return markupKind(t.Data)
+}
+
+// Kind returns the kind of the last token returned by Token, as classified
+// while parsing it. Unlike Token.Kind, it tells a continued chunk of a CDATA
+// section apart from CharData.
+func (t *Tokenizer) Kind() Kind { return t.kind }
+
+// markupKind returns the kind of the "<?" or "<!" tag b, or 0 if b is not one.
+func markupKind(b []byte) Kind {
+	switch {
+	case bytes.HasPrefix(b, []byte("<!--")):
+		return Comment
+	case bytes.HasPrefix(b, []byte("<![CDATA[")):
+		return CDATA
+	case bytes.HasPrefix(b, []byte("<!")):
+		return Directive
+	case bytes.HasPrefix(b, []byte("<?")):
+		return ProcInst
+	}
+	return 0
+}
+
+// classify returns the kind of the raw token b and its name if it is an
+// element, splitting it the same way as consumeTagName.
+func classify(b []byte) (kind Kind, name Name) {
+	if kind = markupKind(b); kind != 0 {
+		return kind, name
+	}
+	switch {
+	case bytes.HasPrefix(b, []byte("</")):
+		kind, b = EndElement, b[2:]
+	default:
+		kind, b = StartElement, b[1:]
+	}
+	n := bytes.IndexAny(b, "/> \t\r\n")
+	if n == -1 {
+		n = len(b)
+	}
+	name.Full = TrimSpace(b[:n])
+	name.Prefix, name.Local = SplitQName(name.Full)
+	if kind == StartElement && isSelfClosing(b[n:]) {
+		kind = SelfClosing
+	}
+	return kind, name
+}
+
+// isSelfClosing reports whether the tag whose bytes following its name are b
+// ends with "/>", skipping the quoted attribute values.
+func isSelfClosing(b []byte) bool {
+	for {
+		i := bytes.IndexAny(b, `>"'`)
+		if i == -1 {
+			return false
+		}
+		if b[i] == '>' {
+			return i > 0 && b[i-1] == '/'
+		}
+		j := bytes.IndexByte(b[i+1:], b[i])
+		if j == -1 {
+			return false
+		}
+		b = b[i+j+2:]
+	}
+}
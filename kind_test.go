@@ -0,0 +1,145 @@
+package xmltokenizer_test
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/muktihari/xmltokenizer"
+)
+
+func TestRawTokenInfo(t *testing.T) {
+	const doc = `<![CDATA[ data ]]><?xml version="1.0"?>
+<!DOCTYPE a>
+<!-- comment -->
+<ns:a x="/>" y='>'>text
+	<b/>
+	<c
+		z="1" />
+</ns:a>`
+
+	type info struct {
+		Kind   xmltokenizer.Kind
+		Prefix string
+		Local  string
+		Full   string
+		Raw    string
+	}
+	tok := xmltokenizer.New(strings.NewReader(doc))
+	var infos []info
+	for {
+		ti, err := tok.RawTokenInfo()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(ti.Raw) != string(tok.Raw()) {
+			t.Fatalf("expected raw: %q, got: %q", tok.Raw(), ti.Raw)
+		}
+		infos = append(infos, info{ti.Kind, string(ti.Name.Prefix), string(ti.Name.Local), string(ti.Name.Full), string(ti.Raw)})
+	}
+
+	expected := []info{
+		{Kind: xmltokenizer.CDATA, Raw: "<![CDATA[ data ]]>"},
+		{Kind: xmltokenizer.ProcInst, Raw: `<?xml version="1.0"?>`},
+		{Kind: xmltokenizer.Directive, Raw: `<!DOCTYPE a>`},
+		{Kind: xmltokenizer.Comment, Raw: `<!-- comment -->`},
+		{xmltokenizer.StartElement, "ns", "a", "ns:a", "<ns:a x=\"/>\" y='>'>text"},
+		{xmltokenizer.SelfClosing, "", "b", "b", "<b/>"},
+		{xmltokenizer.SelfClosing, "", "c", "c", "<c\n\t\tz=\"1\" />"},
+		{xmltokenizer.EndElement, "ns", "a", "ns:a", "</ns:a>"},
+	}
+	if diff := cmp.Diff(infos, expected); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestRawTokenInfoContinued(t *testing.T) {
+	doc := "<a><![CDATA[" + strings.Repeat("x", 20000) + "]]></a>"
+	tok := xmltokenizer.New(strings.NewReader(doc),
+		xmltokenizer.WithAutoGrowBufferMaxLimitSize(4096),
+		xmltokenizer.WithChunkedCharData(),
+	)
+	var kinds []xmltokenizer.Kind
+	for {
+		ti, err := tok.RawTokenInfo()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(kinds) == 0 || kinds[len(kinds)-1] != ti.Kind {
+			kinds = append(kinds, ti.Kind)
+		}
+	}
+	expected := []xmltokenizer.Kind{xmltokenizer.StartElement, xmltokenizer.CDATA, xmltokenizer.EndElement}
+	if diff := cmp.Diff(kinds, expected); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestKindString(t *testing.T) {
+	if s := xmltokenizer.SelfClosing.String(); s != "SelfClosing" {
+		t.Fatalf("expected: %q, got: %q", "SelfClosing", s)
+	}
+	if s := xmltokenizer.Kind(0).String(); s != "Kind(0)" {
+		t.Fatalf("expected: %q, got: %q", "Kind(0)", s)
+	}
+}
+
+func TestTokenKind(t *testing.T) {
+	doc := `<![CDATA[ data ]]><?xml version="1.0"?>
+<!DOCTYPE a>
+<!-- comment -->
+<ns:a x="/>">text<b/><c></c>` +
+		"<d><![CDATA[" + strings.Repeat("x", 10000) + "]]></d>" +
+		"<e>" + strings.Repeat("y", 10000) + "</e></ns:a>"
+	tok := xmltokenizer.New(strings.NewReader(doc),
+		xmltokenizer.WithAutoGrowBufferMaxLimitSize(4096),
+		xmltokenizer.WithChunkedCharData(),
+	)
+
+	type kinds struct{ Token, Tokenizer xmltokenizer.Kind }
+	var got []kinds
+	for {
+		token, err := tok.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		k := kinds{token.Kind(), tok.Kind()}
+		if len(got) == 0 || got[len(got)-1] != k {
+			got = append(got, k)
+		}
+	}
+
+	expected := []kinds{
+		{xmltokenizer.CDATA, xmltokenizer.CDATA},
+		{xmltokenizer.ProcInst, xmltokenizer.ProcInst},
+		{xmltokenizer.Directive, xmltokenizer.Directive},
+		{xmltokenizer.Comment, xmltokenizer.Comment},
+		{xmltokenizer.StartElement, xmltokenizer.StartElement},
+		{xmltokenizer.SelfClosing, xmltokenizer.SelfClosing},
+		{xmltokenizer.StartElement, xmltokenizer.StartElement},
+		{xmltokenizer.EndElement, xmltokenizer.EndElement},
+		{xmltokenizer.StartElement, xmltokenizer.StartElement},
+		{xmltokenizer.CharData, xmltokenizer.CDATA}, // continued chunks
+		{xmltokenizer.EndElement, xmltokenizer.EndElement},
+		{xmltokenizer.StartElement, xmltokenizer.StartElement},
+		{xmltokenizer.CharData, xmltokenizer.CharData},
+		{xmltokenizer.EndElement, xmltokenizer.EndElement},
+	}
+	if diff := cmp.Diff(got, expected); diff != "" {
+		t.Fatal(diff)
+	}
+
+	if kind := new(xmltokenizer.Token).Kind(); kind != 0 {
+		t.Fatalf("expected zero Token kind: 0, got: %v", kind)
+	}
+}
@@ -0,0 +1,221 @@
+package xmltokenizer
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+)
+
+// maxEntityLen is the length of the longest entity decoded by
+// appendUnescaped, such as "&#1114111;".
+const maxEntityLen = len("&#1114111;")
+
+// LongAttrReader is an io.Reader of the XML read from another io.Reader where
+// the attribute values longer than a threshold, such as the data URIs of images
+// inlined in SVG, are streamed to a function and emptied, so the start tags
+// fit in the Tokenizer's buffer whatever the size of the values, rather than
+// exceeding the auto grow buffer max limit or WithMaxAttrValueSize. The rest
+// of the XML is copied byte-exact, hence the positions of the tokens are
+// shifted by the length of the emptied values.
+type LongAttrReader struct {
+	br        *bufio.Reader
+	threshold int
+	fn        func(element, attr Name, value io.Reader) error
+	out       bytes.Buffer
+	err       error
+	element   []byte
+	attr      []byte
+}
+
+// NewLongAttrReader returns a LongAttrReader reading the XML from r and passing
+// every attribute value longer than threshold bytes to fn, along with the names
+// of its element and attribute, as an io.Reader decoding its entities, see
+// DataUnescaped, while it is being read from r. The names are only valid during
+// the invocation. The rest of the value is discarded if fn does not read it
+// all. The error returned by fn, if any, is returned by Read.
+func NewLongAttrReader(r io.Reader, threshold int, fn func(element, attr Name, value io.Reader) error) *LongAttrReader {
+	return &LongAttrReader{
+		br:        bufio.NewReaderSize(r, max(threshold+1, defaultReadBufferSize)),
+		threshold: threshold,
+		fn:        fn,
+	}
+}
+
+// Read reads the XML with the long attribute values emptied into p. It returns
+// io.EOF once the whole document is read, or the first error encountered.
+func (lr *LongAttrReader) Read(p []byte) (n int, err error) {
+	for lr.out.Len() == 0 && lr.err == nil {
+		lr.err = lr.step()
+	}
+	if lr.out.Len() > 0 {
+		return lr.out.Read(p)
+	}
+	return 0, lr.err
+}
+
+// step copies the bytes up to the next "<" and the markup it starts.
+func (lr *LongAttrReader) step() error {
+	text, err := lr.br.ReadSlice('<')
+	lr.out.Write(text)
+	if err == bufio.ErrBufferFull {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	head, _ := lr.br.Peek(len("![CDATA["))
+	switch {
+	case bytes.HasPrefix(head, []byte("!--")):
+		return lr.copyUntil("-->")
+	case bytes.HasPrefix(head, []byte("![CDATA[")):
+		return lr.copyUntil("]]>")
+	case bytes.HasPrefix(head, []byte("?")):
+		return lr.copyUntil("?>")
+	case bytes.HasPrefix(head, []byte("!")), bytes.HasPrefix(head, []byte("/")):
+		return lr.copyUntil(">")
+	}
+	return lr.startTag()
+}
+
+// copyUntil copies the bytes up to and including delim.
+func (lr *LongAttrReader) copyUntil(delim string) error {
+	for {
+		b, err := lr.br.ReadSlice(delim[len(delim)-1])
+		lr.out.Write(b)
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if bytes.HasSuffix(lr.out.Bytes(), []byte(delim)) {
+			return nil
+		}
+	}
+}
+
+// startTag copies the start tag following "<", streaming its long values.
+func (lr *LongAttrReader) startTag() (err error) {
+	if lr.element, err = lr.name(lr.element[:0]); err != nil {
+		return err
+	}
+	for {
+		c, err := lr.br.ReadByte()
+		if err != nil {
+			return err
+		}
+		switch c {
+		case '>':
+			lr.out.WriteByte(c)
+			return nil
+		case '"', '\'':
+			lr.out.WriteByte(c)
+			if err = lr.value(c); err != nil {
+				return err
+			}
+		case ' ', '\t', '\r', '\n', '/', '=':
+			lr.out.WriteByte(c)
+		default:
+			lr.br.UnreadByte()
+			if lr.attr, err = lr.name(lr.attr[:0]); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// name copies the name of an element or an attribute, appending it to dst.
+func (lr *LongAttrReader) name(dst []byte) ([]byte, error) {
+	for {
+		c, err := lr.br.ReadByte()
+		if err != nil {
+			return dst, err
+		}
+		switch c {
+		case ' ', '\t', '\r', '\n', '/', '=', '>', '"', '\'':
+			lr.br.UnreadByte()
+			return dst, nil
+		}
+		lr.out.WriteByte(c)
+		dst = append(dst, c)
+	}
+}
+
+// value copies the value following the opening quote, along with the closing
+// quote, unless it is longer than the threshold: it is then passed to fn.
+func (lr *LongAttrReader) value(quote byte) error {
+	b, _ := lr.br.Peek(lr.threshold + 1)
+	if len(b) <= lr.threshold || bytes.IndexByte(b, quote) != -1 {
+		v, err := lr.br.ReadSlice(quote)
+		lr.out.Write(v)
+		return err
+	}
+
+	vr := &attrValueReader{br: lr.br, quote: quote}
+	prefix, local := SplitQName(lr.element)
+	element := Name{Prefix: prefix, Local: local, Full: lr.element}
+	prefix, local = SplitQName(lr.attr)
+	attr := Name{Prefix: prefix, Local: local, Full: lr.attr}
+	err := lr.fn(element, attr, vr)
+	if err == nil {
+		_, err = io.Copy(io.Discard, vr)
+	}
+	if err != nil {
+		return err
+	}
+	lr.out.WriteByte(quote)
+	return nil
+}
+
+// attrValueReader decodes an attribute value read from br up to the closing
+// quote, which is consumed.
+type attrValueReader struct {
+	br      *bufio.Reader
+	quote   byte
+	out     []byte // decoded bytes not returned yet
+	pending []byte // trailing bytes of an entity continued in the next chunk
+	scratch []byte
+	done    bool
+	err     error
+}
+
+func (vr *attrValueReader) Read(p []byte) (int, error) {
+	for len(vr.out) == 0 {
+		switch {
+		case vr.err != nil:
+			return 0, vr.err
+		case vr.done:
+			return 0, io.EOF
+		}
+		vr.fill()
+	}
+	n := copy(p, vr.out)
+	vr.out = vr.out[n:]
+	return n, nil
+}
+
+// fill decodes the next chunk of the value.
+func (vr *attrValueReader) fill() {
+	chunk, err := vr.br.ReadSlice(vr.quote)
+	switch err {
+	case nil:
+		chunk, vr.done = chunk[:len(chunk)-1], true
+	case bufio.ErrBufferFull:
+	case io.EOF:
+		vr.err = io.ErrUnexpectedEOF
+	default:
+		vr.err = err
+	}
+	data := append(append(vr.scratch[:0], vr.pending...), chunk...)
+	vr.scratch = data
+	cut := len(data)
+	if !vr.done {
+		// An entity split across chunks is decoded along with the next chunk.
+		if i := bytes.LastIndexByte(data, '&'); i != -1 && len(data)-i < maxEntityLen &&
+			bytes.IndexByte(data[i:], ';') == -1 {
+			cut = i
+		}
+	}
+	vr.out = appendUnescaped(vr.out[:0], data[:cut])
+	vr.pending = append(vr.pending[:0], data[cut:]...)
+}
@@ -0,0 +1,111 @@
+package xmltokenizer_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/muktihari/xmltokenizer"
+)
+
+func TestLongAttrReader(t *testing.T) {
+	long := strings.Repeat("data:image/png;base64,AAAA&amp;&#65;", 1000)
+	decoded := strings.Repeat("data:image/png;base64,AAAA&A", 1000)
+	doc := `<?xml version="1.0"?>` +
+		`<!-- <image href="` + long + `"/> -->` +
+		`<svg:svg xmlns:svg="http://www.w3.org/2000/svg">` +
+		`<![CDATA[<image href="` + long + `"/>]]>` +
+		`<svg:image id='a' svg:href='` + long + `' width="10"/>` +
+		`<image href="short" alt="` + long + `"></image>` +
+		`</svg:svg>`
+	expected := strings.NewReplacer(
+		`svg:href='`+long+`'`, `svg:href=''`,
+		`alt="`+long+`"`, `alt=""`,
+	).Replace(doc)
+
+	type value struct{ Element, Attr, Value string }
+	var values []value
+	lr := xmltokenizer.NewLongAttrReader(iotest.OneByteReader(strings.NewReader(doc)), 64,
+		func(element, attr xmltokenizer.Name, r io.Reader) error {
+			b, err := io.ReadAll(iotest.OneByteReader(r))
+			values = append(values, value{string(element.Full), string(attr.Local), string(b)})
+			return err
+		})
+	got, err := io.ReadAll(lr)
+	if err != nil {
+		t.Fatalf("expected nil, got: %v", err)
+	}
+	if diff := cmp.Diff(expected, string(got)); diff != "" {
+		t.Fatal(diff)
+	}
+	if diff := cmp.Diff([]value{
+		{Element: "svg:image", Attr: "href", Value: decoded},
+		{Element: "image", Attr: "alt", Value: decoded},
+	}, values); diff != "" {
+		t.Fatal(diff)
+	}
+
+	tok := xmltokenizer.New(xmltokenizer.NewLongAttrReader(strings.NewReader(doc), 64,
+		func(element, attr xmltokenizer.Name, r io.Reader) error { return nil }),
+		xmltokenizer.WithMaxAttrValueSize(64))
+	for {
+		_, err := tok.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("expected nil, got: %v", err)
+		}
+	}
+}
+
+func TestLongAttrReaderErrors(t *testing.T) {
+	errFn := errors.New("fn error")
+	long := strings.Repeat("x", 100)
+
+	tt := []struct {
+		name string
+		in   string
+		fn   func(element, attr xmltokenizer.Name, r io.Reader) error
+		err  error
+	}{
+		{
+			name: "fn error",
+			in:   `<a b="` + long + `"/>`,
+			fn:   func(element, attr xmltokenizer.Name, r io.Reader) error { return errFn },
+			err:  errFn,
+		},
+		{
+			name: "unterminated long value",
+			in:   `<a b="` + long,
+			fn: func(element, attr xmltokenizer.Name, r io.Reader) error {
+				_, err := io.ReadAll(r)
+				return err
+			},
+			err: io.ErrUnexpectedEOF,
+		},
+		{
+			name: "unterminated long value discarded",
+			in:   `<a b="` + long,
+			fn:   func(element, attr xmltokenizer.Name, r io.Reader) error { return nil },
+			err:  io.ErrUnexpectedEOF,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			lr := xmltokenizer.NewLongAttrReader(strings.NewReader(tc.in), 10, tc.fn)
+			got, err := io.ReadAll(lr)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expected error: %v, got: %v", tc.err, err)
+			}
+			if !bytes.HasPrefix([]byte(tc.in), got) {
+				t.Fatalf("expected a prefix of the input, got: %q", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+// Package lsp converts the tokenizer's positions and errors into the
+// Language Server Protocol's representation, where lines are zero-based
+// and characters are counted in UTF-16 code units.
+package lsp
+
+import (
+	"bytes"
+	"errors"
+	"unicode/utf8"
+
+	"github.com/muktihari/xmltokenizer"
+)
+
+// Severity is the severity of a Diagnostic.
+type Severity int
+
+const (
+	SeverityError       Severity = 1
+	SeverityWarning     Severity = 2
+	SeverityInformation Severity = 3
+	SeverityHint        Severity = 4
+)
+
+// Position is a zero-based line and UTF-16 character offset in a document.
+type Position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// Range is a range in a document, End is exclusive.
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// Diagnostic represents a problem in a document.
+type Diagnostic struct {
+	Range    Range    `json:"range"`
+	Severity Severity `json:"severity"`
+	Source   string   `json:"source,omitempty"`
+	Message  string   `json:"message"`
+}
+
+// PositionOf returns the Position of the given byte offset in src.
+// The offset is clamped into src's bounds.
+func PositionOf(src []byte, offset int) Position {
+	offset = max(0, min(offset, len(src)))
+	lineStart := bytes.LastIndexByte(src[:offset], '\n') + 1
+	return Position{
+		Line:      bytes.Count(src[:lineStart], []byte{'\n'}),
+		Character: utf16Len(src[lineStart:offset]),
+	}
+}
+
+// RangeOf returns the Range of the given begin and end
+// positions reported by the tokenizer within src.
+func RangeOf(src []byte, begin, end xmltokenizer.Pos) Range {
+	return Range{Start: PositionOf(src, begin.Offset), End: PositionOf(src, end.Offset)}
+}
+
+// FromError converts an error returned by the tokenizer while tokenizing src
+// into a Diagnostic. The diagnostic's range covers the character at the error
+// position, or it is empty if the error occurs at the end of src. It returns
+// false if err does not carry any position, e.g. io.EOF.
+func FromError(src []byte, err error) (Diagnostic, bool) {
+	var syntaxErr *xmltokenizer.SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		return Diagnostic{}, false
+	}
+	begin := syntaxErr.Pos.Offset
+	end := begin
+	if begin >= 0 && begin < len(src) {
+		_, size := utf8.DecodeRune(src[begin:])
+		end += size
+	}
+	return Diagnostic{
+		Range:    Range{Start: PositionOf(src, begin), End: PositionOf(src, end)},
+		Severity: SeverityError,
+		Source:   "xmltokenizer",
+		Message:  syntaxErr.Err.Error(),
+	}, true
+}
+
+// utf16Len returns the number of UTF-16 code units needed to encode b.
+func utf16Len(b []byte) int {
+	var n int
+	for len(b) > 0 {
+		r, size := utf8.DecodeRune(b)
+		if r >= 0x10000 {
+			n += 2
+		} else {
+			n++
+		}
+		b = b[size:]
+	}
+	return n
+}
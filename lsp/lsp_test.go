@@ -0,0 +1,64 @@
+package lsp_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/muktihari/xmltokenizer"
+	"github.com/muktihari/xmltokenizer/lsp"
+)
+
+func TestPositionOf(t *testing.T) {
+	src := []byte("<a>\n  <b>白😀x</b>\n</a>")
+	tt := []struct {
+		name     string
+		offset   int
+		expected lsp.Position
+	}{
+		{name: "beginning", offset: 0, expected: lsp.Position{Line: 0, Character: 0}},
+		{name: "after newline", offset: 4, expected: lsp.Position{Line: 1, Character: 0}},
+		{name: "after BMP rune", offset: 12, expected: lsp.Position{Line: 1, Character: 6}},
+		{name: "after surrogate pair", offset: 16, expected: lsp.Position{Line: 1, Character: 8}},
+		{name: "out of bounds", offset: 100, expected: lsp.Position{Line: 2, Character: 4}},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(lsp.PositionOf(src, tc.offset), tc.expected); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
+
+func TestFromError(t *testing.T) {
+	src := []byte("<a>\n  <b 😀=\"1\"")
+	tok := xmltokenizer.New(bytes.NewReader(src))
+	var err error
+	for err == nil {
+		_, err = tok.Token()
+	}
+
+	diag, ok := lsp.FromError(src, err)
+	if !ok {
+		t.Fatalf("expected ok, got: %v", err)
+	}
+	expected := lsp.Diagnostic{
+		Range: lsp.Range{
+			Start: lsp.Position{Line: 1, Character: 11},
+			End:   lsp.Position{Line: 1, Character: 11},
+		},
+		Severity: lsp.SeverityError,
+		Source:   "xmltokenizer",
+		Message:  io.ErrUnexpectedEOF.Error(),
+	}
+	if diff := cmp.Diff(diag, expected); diff != "" {
+		t.Fatal(diff)
+	}
+
+	if _, ok := lsp.FromError(src, io.EOF); ok {
+		t.Fatalf("expected not ok for io.EOF")
+	}
+}
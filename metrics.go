@@ -0,0 +1,74 @@
+package xmltokenizer
+
+import (
+	"expvar"
+	"strconv"
+)
+
+// Metrics receives the measurements of a Tokenizer, see WithMetrics. It is
+// meant to be implemented by an adapter to a metrics system such as
+// Prometheus: the methods map to counters, and the token sizes to a
+// histogram. A Metrics shared by Tokenizers used concurrently must be safe
+// for concurrent use.
+type Metrics interface {
+	// Token is invoked for every token returned with its size in bytes,
+	// including its CharData.
+	Token(size int)
+	// Read is invoked with the number of bytes read from the io.Reader.
+	Read(n int)
+	// Error is invoked once with the first error returned, except io.EOF.
+	Error(err error)
+	// Grow is invoked with the new size of the buffer whenever it is
+	// reallocated to hold a larger token.
+	Grow(size int)
+}
+
+// WithMetrics directs XML Tokenizer to report its measurements to m, so
+// services embedding the Tokenizer get parse observability, see Metrics.
+func WithMetrics(m Metrics) Option {
+	return func(o *options) { o.metrics = m }
+}
+
+// expvarSizeBuckets are the upper bounds of the token size buckets of ExpvarMetrics.
+var expvarSizeBuckets = [...]int{64, 256, 1 << 10, 4 << 10, 16 << 10, 64 << 10}
+
+// ExpvarMetrics is a Metrics recording into an expvar.Map, e.g.
+//
+//	xmltokenizer.WithMetrics(xmltokenizer.ExpvarMetrics{Map: expvar.NewMap("xmltokenizer")})
+//
+// It records the counters "tokens", "bytes", "errors" and "grows", and the
+// histogram of token sizes as the counters "token_size_le_N" of the tokens
+// up to N bytes, where N is 64, 256, 1024, 4096, 16384 or 65536, and
+// "token_size_le_inf" of any size; like Prometheus histograms, the buckets
+// are cumulative. It is safe for concurrent use.
+type ExpvarMetrics struct {
+	Map *expvar.Map
+}
+
+var expvarSizeKeys = func() (keys [len(expvarSizeBuckets) + 1]string) {
+	for i, le := range expvarSizeBuckets {
+		keys[i] = "token_size_le_" + strconv.Itoa(le)
+	}
+	keys[len(expvarSizeBuckets)] = "token_size_le_inf"
+	return keys
+}()
+
+// Token implements Metrics.
+func (m ExpvarMetrics) Token(size int) {
+	m.Map.Add("tokens", 1)
+	for i, le := range expvarSizeBuckets {
+		if size <= le {
+			m.Map.Add(expvarSizeKeys[i], 1)
+		}
+	}
+	m.Map.Add(expvarSizeKeys[len(expvarSizeBuckets)], 1)
+}
+
+// Read implements Metrics.
+func (m ExpvarMetrics) Read(n int) { m.Map.Add("bytes", int64(n)) }
+
+// Error implements Metrics.
+func (m ExpvarMetrics) Error(err error) { m.Map.Add("errors", 1) }
+
+// Grow implements Metrics.
+func (m ExpvarMetrics) Grow(size int) { m.Map.Add("grows", 1) }
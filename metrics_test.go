@@ -0,0 +1,86 @@
+package xmltokenizer_test
+
+import (
+	"errors"
+	"expvar"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/muktihari/xmltokenizer"
+)
+
+type metrics struct {
+	sizes []int
+	bytes int
+	errs  []error
+	grows []int
+}
+
+func (m *metrics) Token(size int)  { m.sizes = append(m.sizes, size) }
+func (m *metrics) Read(n int)      { m.bytes += n }
+func (m *metrics) Error(err error) { m.errs = append(m.errs, err) }
+func (m *metrics) Grow(size int)   { m.grows = append(m.grows, size) }
+
+func TestMetrics(t *testing.T) {
+	xml := `<a><b>` + strings.Repeat("0", 5000) + `</b></a><c`
+	var m metrics
+	tok := xmltokenizer.New(strings.NewReader(xml),
+		xmltokenizer.WithMetrics(&m),
+		xmltokenizer.WithReadBufferSize(8),
+	)
+	var err error
+	for i := 0; i < 6 && err == nil; i++ {
+		_, err = tok.Token()
+	}
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("expected error: %v, got: %v", io.ErrUnexpectedEOF, err)
+	}
+	if _, err = tok.RawToken(); err == nil {
+		t.Fatalf("expected error, got nil")
+	}
+
+	if diff := cmp.Diff(m.sizes, []int{3, 5003, 4, 4}); diff != "" {
+		t.Fatal(diff)
+	}
+	if m.bytes != len(xml) {
+		t.Fatalf("expected bytes: %d, got: %d", len(xml), m.bytes)
+	}
+	if len(m.errs) != 1 || !errors.Is(m.errs[0], io.ErrUnexpectedEOF) {
+		t.Fatalf("expected one error: %v, got: %v", io.ErrUnexpectedEOF, m.errs)
+	}
+	if len(m.grows) == 0 {
+		t.Fatalf("expected grows, got none")
+	}
+}
+
+func TestExpvarMetrics(t *testing.T) {
+	vars := new(expvar.Map).Init()
+	m := xmltokenizer.ExpvarMetrics{Map: vars}
+	m.Token(10)
+	m.Token(100)
+	m.Token(100000)
+	m.Read(42)
+	m.Error(io.ErrUnexpectedEOF)
+	m.Grow(8192)
+
+	expected := map[string]string{
+		"tokens":              "3",
+		"bytes":               "42",
+		"errors":              "1",
+		"grows":               "1",
+		"token_size_le_64":    "1",
+		"token_size_le_256":   "2",
+		"token_size_le_1024":  "2",
+		"token_size_le_4096":  "2",
+		"token_size_le_16384": "2",
+		"token_size_le_65536": "2",
+		"token_size_le_inf":   "3",
+	}
+	result := make(map[string]string)
+	vars.Do(func(kv expvar.KeyValue) { result[kv.Key] = kv.Value.String() })
+	if diff := cmp.Diff(result, expected); diff != "" {
+		t.Fatal(diff)
+	}
+}
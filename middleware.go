@@ -0,0 +1,194 @@
+package xmltokenizer
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+)
+
+// ErrMaxTokensExceeded is returned when a TokenSource returns more tokens
+// than the limit set by MaxTokens.
+const ErrMaxTokensExceeded = errorString("max tokens exceeded")
+
+// ErrMaxDepthExceeded is returned when the elements of a TokenSource are
+// nested deeper than the limit set by MaxDepth.
+const ErrMaxDepthExceeded = errorString("max depth exceeded")
+
+// TokenSourceFunc is a function used as a TokenSource.
+type TokenSourceFunc func() (Token, error)
+
+// Token returns f().
+func (f TokenSourceFunc) Token() (Token, error) { return f() }
+
+// Middleware wraps a TokenSource into another one adding a layer of
+// processing, such as enforcing limits or resolving namespaces, so a pipeline
+// is made of exactly the layers it needs, see Chain.
+type Middleware func(src TokenSource) TokenSource
+
+// Chain wraps src with the middlewares in order: the tokens of src go through
+// the first middleware first and come out of the last one.
+func Chain(src TokenSource, middlewares ...Middleware) TokenSource {
+	for _, m := range middlewares {
+		src = m(src)
+	}
+	return src
+}
+
+// latched returns a TokenSource returning the tokens of src passed through
+// fn, the first error of either is returned by every subsequent invocation.
+func latched(src TokenSource, fn func(token *Token) error) TokenSource {
+	var err error
+	return TokenSourceFunc(func() (token Token, _ error) {
+		if err != nil {
+			return token, err
+		}
+		if token, err = src.Token(); err == nil {
+			err = fn(&token)
+		}
+		if err != nil {
+			return Token{}, err
+		}
+		return token, nil
+	})
+}
+
+// MaxTokens returns a Middleware returning ErrMaxTokensExceeded once the
+// source returns more than n tokens.
+func MaxTokens(n int64) Middleware {
+	return func(src TokenSource) TokenSource {
+		var count int64
+		return latched(src, func(*Token) error {
+			if count++; count > n {
+				return fmt.Errorf("could not read more than %d tokens: %w", n, ErrMaxTokensExceeded)
+			}
+			return nil
+		})
+	}
+}
+
+// MaxDepth returns a Middleware returning ErrMaxDepthExceeded once the
+// source returns an element nested in n other elements, so the elements are
+// nested at most n deep.
+func MaxDepth(n int) Middleware {
+	return func(src TokenSource) TokenSource {
+		var depth int
+		return latched(src, func(token *Token) error {
+			switch {
+			case len(token.Name.Full) == 0 || token.Continued:
+			case token.IsEndElement:
+				depth = max(depth-1, 0)
+			case depth >= n:
+				return fmt.Errorf("could not nest more than %d elements: %w", n, ErrMaxDepthExceeded)
+			case !token.SelfClosing:
+				depth++
+			}
+			return nil
+		})
+	}
+}
+
+// NamespaceAliases returns a Middleware doing what WithNamespaceAliases does
+// for a Tokenizer for any source, such as a Replayer.
+func NamespaceAliases(aliases map[string]string) Middleware {
+	return namespaceMiddleware(aliases, false, false)
+}
+
+// NamespaceCheck returns a Middleware doing what WithNamespaceCheck does for
+// a Tokenizer for any source, the SyntaxError is positioned at the beginning
+// of the offending token.
+func NamespaceCheck() Middleware {
+	return namespaceMiddleware(nil, true, false)
+}
+
+// NamespaceResolution returns a Middleware doing what WithNamespaceResolution
+// does for a Tokenizer for any source.
+func NamespaceResolution() Middleware {
+	return namespaceMiddleware(nil, false, true)
+}
+
+func namespaceMiddleware(aliases map[string]string, check, spaces bool) Middleware {
+	return func(src TokenSource) TokenSource {
+		var ns namespaces
+		var attrs []Attr
+		ns.reset(aliases, check, spaces)
+		return latched(src, func(token *Token) error {
+			if len(token.Attrs) > 0 { // the names are modified in place
+				attrs = append(attrs[:0], token.Attrs...)
+				token.Attrs = attrs
+			}
+			if err := ns.resolve(token); err != nil {
+				return newSyntaxError(fmt.Errorf("%s: %w", err, ErrNamespace), token.Begin, nil, 0)
+			}
+			return nil
+		})
+	}
+}
+
+// UnescapeEntities returns a Middleware decoding the entities of the Data
+// and the attribute values of the tokens of the source, see DataUnescaped.
+// The Data of "<?" and "<!" tags, including a CDATA section standing alone,
+// is left as it is, while the Data of a CDATA section following a start
+// element, which can not be told apart from CharData once tokenized, is
+// decoded too.
+func UnescapeEntities() Middleware {
+	return func(src TokenSource) TokenSource {
+		var buf []byte
+		var attrs []Attr
+		return latched(src, func(token *Token) error {
+			n := len(token.Data)
+			for i := range token.Attrs {
+				n += len(token.Attrs[i].Value)
+			}
+			if cap(buf) < n {
+				buf = make([]byte, 0, n) // values must not be moved by append, decoding never grows
+			}
+			buf = buf[:0]
+			if len(token.Name.Full) > 0 || token.Continued {
+				token.Data, buf = unescapeInto(buf, token.Data)
+			}
+			if len(token.Attrs) > 0 {
+				attrs = append(attrs[:0], token.Attrs...)
+				for i := range attrs {
+					attrs[i].Value, buf = unescapeInto(buf, attrs[i].Value)
+				}
+				token.Attrs = attrs
+			}
+			return nil
+		})
+	}
+}
+
+// unescapeInto appends b decoded to buf, returning the decoded b and buf.
+func unescapeInto(buf, b []byte) ([]byte, []byte) {
+	if b == nil {
+		return nil, buf
+	}
+	start := len(buf)
+	buf = appendUnescaped(buf, b)
+	return buf[start:len(buf):len(buf)], buf
+}
+
+// LogTokens returns a Middleware logging every token of the source to logger
+// at debug level, with its kind, name and position, and the first error other
+// than io.EOF at error level.
+func LogTokens(logger *slog.Logger) Middleware {
+	return func(src TokenSource) TokenSource {
+		var logged bool
+		return TokenSourceFunc(func() (Token, error) {
+			token, err := src.Token()
+			switch {
+			case err == nil:
+				logger.Debug("xmltokenizer.token",
+					slog.String("kind", token.Kind().String()),
+					slog.String("name", string(token.Name.Full)),
+					slog.Int("line", token.Begin.Line),
+					slog.Int("column", token.Begin.Column),
+					slog.Int("offset", token.Begin.Offset))
+			case err != io.EOF && !logged:
+				logged = true
+				logger.Error("xmltokenizer.error", slog.Any("error", err))
+			}
+			return token, err
+		})
+	}
+}
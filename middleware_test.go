@@ -0,0 +1,168 @@
+package xmltokenizer_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/muktihari/xmltokenizer"
+)
+
+// collect reads src until an error and returns the string form of the
+// tokens, the error is nil on io.EOF.
+func collect(src xmltokenizer.TokenSource) ([]string, error) {
+	var tokens []string
+	for {
+		token, err := src.Token()
+		if err == io.EOF {
+			return tokens, nil
+		}
+		if err != nil {
+			return tokens, err
+		}
+		tokens = append(tokens, token.String())
+	}
+}
+
+func TestMiddlewares(t *testing.T) {
+	tt := []struct {
+		name        string
+		in          string
+		middlewares []xmltokenizer.Middleware
+		expected    []string
+		err         error
+	}{
+		{
+			name:        "max tokens",
+			in:          `<a><b/><c/></a>`,
+			middlewares: []xmltokenizer.Middleware{xmltokenizer.MaxTokens(2)},
+			expected:    []string{"<a @1:1>", "<b @1:4/>"},
+			err:         xmltokenizer.ErrMaxTokensExceeded,
+		},
+		{
+			name:        "max depth",
+			in:          `<a><b/><c><d/></c></a>`,
+			middlewares: []xmltokenizer.Middleware{xmltokenizer.MaxDepth(2)},
+			expected:    []string{"<a @1:1>", "<b @1:4/>", "<c @1:8>"},
+			err:         xmltokenizer.ErrMaxDepthExceeded,
+		},
+		{
+			name:        "max depth not exceeded",
+			in:          `<a><b/><c></c></a>`,
+			middlewares: []xmltokenizer.Middleware{xmltokenizer.MaxDepth(2)},
+			expected:    []string{"<a @1:1>", "<b @1:4/>", "<c @1:8>", "</c @1:11>", "</a @1:15>"},
+		},
+		{
+			name: "namespace aliases",
+			in:   `<ns3:a xmlns:ns3="urn:x" ns3:k="v"><b xmlns="urn:x"/></ns3:a>`,
+			middlewares: []xmltokenizer.Middleware{
+				xmltokenizer.NamespaceAliases(map[string]string{"urn:x": "x"}),
+			},
+			expected: []string{`<x:a xmlns:ns3="urn:x" x:k="v" @1:1>`, `<x:b xmlns="urn:x" @1:36/>`, `</x:a @1:54>`},
+		},
+		{
+			name:        "namespace check",
+			in:          `<a><p:b/></a>`,
+			middlewares: []xmltokenizer.Middleware{xmltokenizer.NamespaceCheck()},
+			expected:    []string{"<a @1:1>"},
+			err:         xmltokenizer.ErrNamespace,
+		},
+		{
+			name:        "unescape entities",
+			in:          `<a k="&lt;&#65;&gt;">x &amp; y<!-- &amp; --></a>`,
+			middlewares: []xmltokenizer.Middleware{xmltokenizer.UnescapeEntities()},
+			expected:    []string{`<a k="<A>" @1:1>"x & y"`, `<!-- &amp; --> @1:31`, `</a @1:45>`},
+		},
+		{
+			name: "chain in order",
+			in:   `<a><b/><c/></a>`,
+			middlewares: []xmltokenizer.Middleware{
+				func(src xmltokenizer.TokenSource) xmltokenizer.TokenSource { // drop <b/>
+					return xmltokenizer.TokenSourceFunc(func() (xmltokenizer.Token, error) {
+						token, err := src.Token()
+						if err == nil && string(token.Name.Full) == "b" {
+							return src.Token()
+						}
+						return token, err
+					})
+				},
+				xmltokenizer.MaxTokens(3),
+			},
+			expected: []string{"<a @1:1>", "<c @1:8/>", "</a @1:12>"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			src := xmltokenizer.Chain(xmltokenizer.New(strings.NewReader(tc.in)), tc.middlewares...)
+			tokens, err := collect(src)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expected error: %v, got: %v", tc.err, err)
+			}
+			if diff := cmp.Diff(tc.expected, tokens); diff != "" {
+				t.Fatal(diff)
+			}
+			if _, err2 := src.Token(); tc.err != nil && err2 != err {
+				t.Fatalf("expected latched error: %v, got: %v", err, err2)
+			}
+		})
+	}
+}
+
+func TestLogTokens(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+	src := xmltokenizer.Chain(xmltokenizer.New(strings.NewReader(`<a>x</a><b`)), xmltokenizer.LogTokens(logger))
+	if _, err := collect(src); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("expected error: %v, got: %v", io.ErrUnexpectedEOF, err)
+	}
+	src.Token() // the error is logged once
+
+	expected := "level=DEBUG msg=xmltokenizer.token kind=StartElement name=a line=1 column=1 offset=0\n" +
+		"level=DEBUG msg=xmltokenizer.token kind=EndElement name=a line=1 column=5 offset=4\n" +
+		"level=ERROR msg=xmltokenizer.error error=\"line: 1 column: 11 byte offset 10: unexpected EOF\"\n"
+	if diff := cmp.Diff(expected, buf.String()); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestNamespaceResolution(t *testing.T) {
+	src := xmltokenizer.Chain(
+		xmltokenizer.New(strings.NewReader(`<a xmlns="urn:a" xmlns:p="urn:p"><p:b k="1" p:k="2"/></a>`)),
+		xmltokenizer.NamespaceResolution(),
+	)
+	var spaces []string
+	for {
+		token, err := src.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("expected nil, got: %v", err)
+		}
+		spaces = append(spaces, string(token.Name.Space))
+		for _, attr := range token.Attrs {
+			spaces = append(spaces, string(attr.Name.Space))
+		}
+	}
+	expected := []string{
+		"urn:a", "http://www.w3.org/2000/xmlns/", "http://www.w3.org/2000/xmlns/",
+		"urn:p", "", "urn:p",
+		"urn:a",
+	}
+	if diff := cmp.Diff(expected, spaces); diff != "" {
+		t.Fatal(diff)
+	}
+}
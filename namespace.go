@@ -0,0 +1,325 @@
+package xmltokenizer
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	xmlNamespace   = "http://www.w3.org/XML/1998/namespace"
+	xmlnsNamespace = "http://www.w3.org/2000/xmlns/"
+)
+
+// ErrNamespace is returned when a document violates a namespace constraint,
+// see WithNamespaceCheck.
+const ErrNamespace = errorString("namespace well-formedness error")
+
+// WithNamespaceAliases directs XML Tokenizer to resolve the namespace of the
+// names of elements and prefixed attributes using the xmlns declarations in
+// scope, and to replace their prefix by the alias of the namespace URI given
+// in aliases, e.g. with
+//
+//	map[string]string{"http://www.garmin.com/xmlschemas/TrackPointExtension/v1": "gpxtpx"}
+//
+// <ns3:hr> is returned as <gpxtpx:hr> when ns3 is bound to that URI, and an
+// unprefixed element in that default namespace is returned with the gpxtpx
+// prefix too. An empty alias removes the prefix. Matchers, such as the ones
+// of the xmlpath package, and decoding code can therefore compare names
+// without depending on the prefixes chosen by the document authors. Names
+// in other namespaces, and the xmlns declarations themselves, are left
+// untouched, as are RawToken and Raw.
+//
+// The declarations in scope are tracked by Token, so every token must be read
+// with Token, and Restore does not restore them.
+func WithNamespaceAliases(aliases map[string]string) Option {
+	return func(o *options) { o.namespaceAliases = aliases }
+}
+
+// WithNamespaceCheck directs XML Tokenizer to abort with ErrNamespace, as a
+// SyntaxError positioned at the beginning of the offending token, when it
+// violates the constraints of Namespaces in XML 1.0:
+//   - the xml prefix is bound to another namespace, or its namespace is bound
+//     to another prefix;
+//   - the xmlns prefix is declared, or its namespace is bound to a prefix;
+//   - a prefix is bound to an empty namespace URI, e.g. xmlns:p="";
+//   - a prefix is declared twice on the same element;
+//   - the name of an element or an attribute uses an undeclared prefix.
+//
+// Like WithNamespaceAliases, the declarations in scope are tracked by Token.
+// The offending token can be skipped with Recover.
+func WithNamespaceCheck() Option {
+	return func(o *options) { o.namespaceCheck = true }
+}
+
+// WithNamespaceResolution directs XML Tokenizer to set the Space of the names
+// of elements and attributes to the namespace URI bound to their prefix by the
+// xmlns declarations in scope, as encoding/xml does for xml.Name.Space, so
+// callers do not have to track the declarations themselves. An unprefixed
+// element is in the default namespace, if any, while an unprefixed attribute
+// is in no namespace. The xml prefix is bound to its namespace, and the xmlns
+// declarations are in the xmlns namespace. The Space of a name whose prefix is
+// not declared is nil, see WithNamespaceCheck to reject them.
+//
+// Like WithNamespaceAliases, the declarations in scope are tracked by Token,
+// and the Space is only valid until the next Token invocation, unless
+// WithPersistentTokens is specified.
+func WithNamespaceResolution() Option {
+	return func(o *options) { o.namespaceResolution = true }
+}
+
+var (
+	xmlSpace   = []byte(xmlNamespace)[:len(xmlNamespace):len(xmlNamespace)]
+	xmlnsSpace = []byte(xmlnsNamespace)[:len(xmlnsNamespace):len(xmlnsNamespace)]
+)
+
+// nsBinding is a namespace prefix declaration in scope.
+type nsBinding struct {
+	prefix  string
+	alias   string
+	aliased bool   // whether the namespace URI has an alias
+	depth   int    // depth of the element declaring it
+	uri     []byte // namespace URI within namespaces.uris, if spaces is set
+}
+
+// namespaces tracks the namespace declarations in scope of a stream of tokens,
+// see WithNamespaceAliases, WithNamespaceCheck and WithNamespaceResolution.
+type namespaces struct {
+	aliases  map[string]string
+	check    bool
+	spaces   bool
+	bindings []nsBinding // declarations in scope
+	depth    int         // number of open elements
+	alias    []byte      // aliased names of the last token
+	maxAlias int         // length of the longest alias
+	uris     []byte      // namespace URIs of the bindings
+}
+
+// reset resets the tracking, keeping the memory of n.
+func (n *namespaces) reset(aliases map[string]string, check, spaces bool) {
+	n.aliases, n.check, n.spaces = aliases, check, spaces
+	n.bindings, n.depth, n.uris = n.bindings[:0], 0, n.uris[:0]
+	n.maxAlias = 0
+	for _, alias := range aliases {
+		n.maxAlias = max(n.maxAlias, len(alias))
+	}
+}
+
+// resolveNamespaces tracks the namespace declarations in scope, checking the
+// token if WithNamespaceCheck is specified, setting the Space of its names if
+// WithNamespaceResolution is specified, and aliasing its names if
+// WithNamespaceAliases is specified.
+func (t *Tokenizer) resolveNamespaces() error {
+	if err := t.ns.resolve(&t.token); err != nil {
+		syntaxErr := newSyntaxError(fmt.Errorf("%s: %w", err, ErrNamespace), t.token.Begin, t.Raw(), 0)
+		return t.annotate(syntaxErr)
+	}
+	return nil
+}
+
+// resolve tracks the namespace declarations of token, returning the violation
+// of a namespace constraint if check is set, sets the Space of its names if
+// spaces is set, and aliases its names if aliases are set. The Attrs of token
+// are modified in place.
+func (n *namespaces) resolve(token *Token) (err error) {
+	if len(token.Name.Full) == 0 {
+		return nil
+	}
+	if !token.IsEndElement {
+		n.depth++
+		first := len(n.bindings)
+		for i := range token.Attrs {
+			n.bind(&token.Attrs[i])
+		}
+		if n.check {
+			err = n.checkNames(token, first)
+		}
+	}
+	if n.spaces {
+		n.setSpaces(token)
+	}
+	if n.aliases != nil {
+		n.aliasNames(token)
+	}
+	if token.IsEndElement || token.SelfClosing {
+		for len(n.bindings) > 0 && n.bindings[len(n.bindings)-1].depth == n.depth {
+			n.uris = n.uris[:len(n.uris)-len(n.bindings[len(n.bindings)-1].uri)]
+			n.bindings = n.bindings[:len(n.bindings)-1]
+		}
+		if n.depth > 0 {
+			n.depth--
+		}
+	}
+	return err
+}
+
+// checkNames checks the declarations of the token, which are
+// n.bindings[first:], and the prefixes of its names, see WithNamespaceCheck.
+func (n *namespaces) checkNames(token *Token, first int) error {
+	for i := range token.Attrs {
+		attr := &token.Attrs[i]
+		if !isNamespaceDecl(attr.Name) {
+			continue
+		}
+		prefix, uri := string(attr.Name.Local), string(attr.Value)
+		if attr.Name.Prefix == nil {
+			prefix = ""
+		}
+		switch {
+		case prefix == "xml" && uri != xmlNamespace:
+			return fmt.Errorf("prefix xml bound to %q", uri)
+		case prefix == "xmlns":
+			return errors.New("prefix xmlns declared")
+		case prefix != "xml" && (uri == xmlNamespace || uri == xmlnsNamespace):
+			return fmt.Errorf("namespace %q bound to prefix %q", uri, prefix)
+		case prefix != "" && uri == "":
+			return fmt.Errorf("prefix %q bound to an empty namespace", prefix)
+		}
+	}
+	for i := first; i < len(n.bindings); i++ {
+		for j := first; j < i; j++ {
+			if n.bindings[i].prefix == n.bindings[j].prefix {
+				return fmt.Errorf("prefix %q declared twice", n.bindings[i].prefix)
+			}
+		}
+	}
+	if !n.declared(token.Name.Prefix) {
+		return fmt.Errorf("undeclared prefix %q", token.Name.Prefix)
+	}
+	for i := range token.Attrs {
+		name := token.Attrs[i].Name
+		if name.Prefix != nil && !isNamespaceDecl(name) && !n.declared(name.Prefix) {
+			return fmt.Errorf("undeclared prefix %q", name.Prefix)
+		}
+	}
+	return nil
+}
+
+// declared reports whether prefix, if any, is declared in scope.
+func (n *namespaces) declared(prefix []byte) bool {
+	if prefix == nil || string(prefix) == "xml" {
+		return true
+	}
+	for i := len(n.bindings) - 1; i >= 0; i-- {
+		if n.bindings[i].prefix == string(prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// setSpaces sets the Space of the names of the token, see
+// WithNamespaceResolution.
+func (n *namespaces) setSpaces(token *Token) {
+	token.Name.Space = n.lookupSpace(token.Name.Prefix, true)
+	for i := range token.Attrs {
+		name := &token.Attrs[i].Name
+		if isNamespaceDecl(*name) {
+			name.Space = xmlnsSpace
+		} else {
+			name.Space = n.lookupSpace(name.Prefix, false)
+		}
+	}
+}
+
+// lookupSpace returns the namespace URI bound to prefix, or nil if there is
+// none. An unprefixed name is in the default namespace only if it is an
+// element's.
+func (n *namespaces) lookupSpace(prefix []byte, element bool) []byte {
+	if prefix == nil && !element {
+		return nil
+	}
+	for i := len(n.bindings) - 1; i >= 0; i-- {
+		if n.bindings[i].prefix == string(prefix) {
+			return n.bindings[i].uri
+		}
+	}
+	if string(prefix) == "xml" {
+		return xmlSpace
+	}
+	return nil
+}
+
+// aliasNames replaces the prefixes of the names of the token by the aliases of
+// their namespace into n.alias, see WithNamespaceAliases.
+func (n *namespaces) aliasNames(token *Token) {
+	size := 0
+	for i := range token.Attrs {
+		size += len(token.Attrs[i].Name.Full)
+	}
+	size = (len(token.Attrs)+1)*(n.maxAlias+1) + size + len(token.Name.Full)
+	if cap(n.alias) < size {
+		n.alias = make([]byte, 0, size) // names must not be moved by append
+	}
+	n.alias = n.alias[:0]
+	token.Name = n.aliasName(token.Name, true)
+	for i := range token.Attrs {
+		if !isNamespaceDecl(token.Attrs[i].Name) {
+			token.Attrs[i].Name = n.aliasName(token.Attrs[i].Name, false)
+		}
+	}
+}
+
+// bind pushes the declaration if attr is a xmlns or xmlns:prefix.
+func (n *namespaces) bind(attr *Attr) {
+	var prefix string
+	switch {
+	case string(attr.Name.Full) == "xmlns":
+	case string(attr.Name.Prefix) == "xmlns":
+		prefix = string(attr.Name.Local)
+	default:
+		return
+	}
+	alias, ok := n.aliases[string(attr.Value)]
+	var uri []byte
+	if n.spaces && len(attr.Value) > 0 {
+		n.uris = append(n.uris, attr.Value...)
+		uri = n.uris[len(n.uris)-len(attr.Value) : len(n.uris) : len(n.uris)]
+	}
+	n.bindings = append(n.bindings, nsBinding{prefix: prefix, alias: alias, aliased: ok, depth: n.depth, uri: uri})
+}
+
+// aliasName returns name with the alias of its namespace as prefix. An
+// unprefixed name is in the default namespace only if it is an element's.
+func (n *namespaces) aliasName(name Name, element bool) Name {
+	if name.Prefix == nil && !element {
+		return name
+	}
+	alias, ok := n.lookupAlias(name.Prefix)
+	if !ok || alias == string(name.Prefix) {
+		return name
+	}
+	start := len(n.alias)
+	if alias != "" {
+		n.alias = append(n.alias, alias...)
+		n.alias = append(n.alias, ':')
+	}
+	n.alias = append(n.alias, name.Local...)
+	full := n.alias[start:len(n.alias):len(n.alias)]
+	aliased := Name{Local: full[len(full)-len(name.Local):], Full: full, Space: name.Space}
+	if alias != "" {
+		aliased.Prefix = full[:len(alias)]
+	}
+	return aliased
+}
+
+// lookupAlias returns the alias of the namespace bound to prefix, ok is false
+// if the namespace has no alias or the prefix is not declared.
+func (n *namespaces) lookupAlias(prefix []byte) (alias string, ok bool) {
+	for i := len(n.bindings) - 1; i >= 0; i-- {
+		if n.bindings[i].prefix == string(prefix) {
+			return n.bindings[i].alias, n.bindings[i].aliased
+		}
+	}
+	if string(prefix) == "xml" {
+		alias, ok = n.aliases[xmlNamespace]
+	}
+	return alias, ok
+}
+
+// isNamespaceDecl reports whether n is either xmlns or xmlns:prefix.
+func isNamespaceDecl(n Name) bool {
+	if n.Prefix == nil {
+		return string(n.Full) == "xmlns"
+	}
+	return string(n.Prefix) == "xmlns"
+}
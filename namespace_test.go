@@ -0,0 +1,217 @@
+package xmltokenizer_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/muktihari/xmltokenizer"
+)
+
+func TestWithNamespaceAliases(t *testing.T) {
+	const doc = `<gpx xmlns="http://www.topografix.com/GPX/1/1" xmlns:ns3="http://www.garmin.com/xmlschemas/TrackPointExtension/v1">
+	<trkpt lat="1" ns3:src="x" xml:lang="en">
+		<ns3:hr>70</ns3:hr>
+		<ext xmlns="" xmlns:ns3="urn:other"><ns3:hr/><a/></ext>
+		<TrackPointExtension xmlns="http://www.garmin.com/xmlschemas/TrackPointExtension/v1"/>
+	</trkpt>
+</gpx>
+<ns3:hr/>`
+
+	tok := xmltokenizer.New(strings.NewReader(doc), xmltokenizer.WithNamespaceAliases(map[string]string{
+		"http://www.topografix.com/GPX/1/1":                       "",
+		"http://www.garmin.com/xmlschemas/TrackPointExtension/v1": "gpxtpx",
+	}))
+	type name struct{ Prefix, Local, Full string }
+	var names [][]name
+	for {
+		token, err := tok.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		n := []name{{string(token.Name.Prefix), string(token.Name.Local), string(token.Name.Full)}}
+		for _, attr := range token.Attrs {
+			if string(attr.Name.Local) != "lat" && string(attr.Name.Prefix) != "xmlns" && string(attr.Name.Full) != "xmlns" {
+				n = append(n, name{string(attr.Name.Prefix), string(attr.Name.Local), string(attr.Name.Full)})
+			}
+		}
+		names = append(names, n)
+	}
+
+	expected := [][]name{
+		{{"", "gpx", "gpx"}},
+		{{"", "trkpt", "trkpt"}, {"gpxtpx", "src", "gpxtpx:src"}, {"xml", "lang", "xml:lang"}},
+		{{"gpxtpx", "hr", "gpxtpx:hr"}},
+		{{"gpxtpx", "hr", "gpxtpx:hr"}},
+		{{"", "ext", "ext"}},
+		{{"ns3", "hr", "ns3:hr"}}, // bound to another namespace
+		{{"", "a", "a"}},          // no default namespace
+		{{"", "ext", "ext"}},
+		{{"gpxtpx", "TrackPointExtension", "gpxtpx:TrackPointExtension"}},
+		{{"", "trkpt", "trkpt"}},
+		{{"", "gpx", "gpx"}},
+		{{"ns3", "hr", "ns3:hr"}}, // out of scope
+	}
+	if diff := cmp.Diff(names, expected); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestWithNamespaceCheck(t *testing.T) {
+	tt := []struct {
+		name     string
+		xml      string
+		expected string
+	}{
+		{
+			name:     "valid",
+			xml:      `<a xmlns="urn:a" xmlns:xml="http://www.w3.org/XML/1998/namespace" xmlns:b="urn:b"><b:c b:d="1" xml:lang="en"/><e xmlns=""/></a>`,
+			expected: "",
+		},
+		{
+			name:     "xml bound to another namespace",
+			xml:      `<a><b xmlns:xml="urn:xml"/></a>`,
+			expected: `line: 1 column: 4 byte offset 3: prefix xml bound to "urn:xml": namespace well-formedness error, inside <a> started at line 1, col 1`,
+		},
+		{
+			name:     "xmlns declared",
+			xml:      `<a xmlns:xmlns="http://www.w3.org/2000/xmlns/"/>`,
+			expected: `line: 1 column: 1 byte offset 0: prefix xmlns declared: namespace well-formedness error`,
+		},
+		{
+			name:     "xml namespace bound to another prefix",
+			xml:      `<a xmlns:x="http://www.w3.org/XML/1998/namespace"/>`,
+			expected: `line: 1 column: 1 byte offset 0: namespace "http://www.w3.org/XML/1998/namespace" bound to prefix "x": namespace well-formedness error`,
+		},
+		{
+			name:     "empty namespace",
+			xml:      `<a xmlns:p=""/>`,
+			expected: `line: 1 column: 1 byte offset 0: prefix "p" bound to an empty namespace: namespace well-formedness error`,
+		},
+		{
+			name:     "prefix declared twice",
+			xml:      `<a xmlns:p="urn:a" xmlns:p="urn:b"/>`,
+			expected: `line: 1 column: 1 byte offset 0: prefix "p" declared twice: namespace well-formedness error`,
+		},
+		{
+			name:     "undeclared element prefix",
+			xml:      "<a>\n  <p:b xmlns:q=\"urn:q\"/>\n</a>",
+			expected: `line: 2 column: 3 byte offset 6: undeclared prefix "p": namespace well-formedness error, inside <a> started at line 1, col 1`,
+		},
+		{
+			name:     "undeclared attribute prefix out of scope",
+			xml:      `<a><b xmlns:p="urn:p"/><c p:d="1"/></a>`,
+			expected: `line: 1 column: 24 byte offset 23: undeclared prefix "p": namespace well-formedness error, inside <a> started at line 1, col 1`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tok := xmltokenizer.New(strings.NewReader(tc.xml), xmltokenizer.WithNamespaceCheck())
+			var err error
+			for err == nil {
+				_, err = tok.Token()
+			}
+			if err == io.EOF {
+				err = nil
+			}
+			if tc.expected == "" {
+				if err != nil {
+					t.Fatalf("expected nil, got: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, xmltokenizer.ErrNamespace) || err.Error() != tc.expected {
+				t.Fatalf("expected error: %s, got: %v", tc.expected, err)
+			}
+			if err = tok.Recover(); err != nil {
+				t.Fatalf("expected nil, got: %v", err)
+			}
+			for err == nil {
+				_, err = tok.Token()
+			}
+			if err != io.EOF {
+				t.Fatalf("expected error: %v, got: %v", io.EOF, err)
+			}
+		})
+	}
+}
+
+func TestWithNamespaceResolution(t *testing.T) {
+	const doc = `<gpx xmlns="http://www.topografix.com/GPX/1/1" xmlns:ns3="http://www.garmin.com/xmlschemas/TrackPointExtension/v1">
+	<trkpt lat="1" ns3:src="x" xml:lang="en">
+		<ns3:hr>70</ns3:hr><!-- comment -->
+		<ext xmlns="" xmlns:ns3="urn:other"><ns3:hr/><a/></ext>
+	</trkpt>
+</gpx>
+<ns3:hr/>`
+
+	const (
+		gpx    = "http://www.topografix.com/GPX/1/1"
+		gpxtpx = "http://www.garmin.com/xmlschemas/TrackPointExtension/v1"
+		xml    = "http://www.w3.org/XML/1998/namespace"
+		xmlns  = "http://www.w3.org/2000/xmlns/"
+	)
+	expected := [][]string{
+		{"gpx", gpx, "xmlns", xmlns, "xmlns:ns3", xmlns},
+		{"trkpt", gpx, "lat", "", "ns3:src", gpxtpx, "xml:lang", xml},
+		{"ns3:hr", gpxtpx},
+		{"ns3:hr", gpxtpx},
+		{"ext", "", "xmlns", xmlns, "xmlns:ns3", xmlns},
+		{"ns3:hr", "urn:other"},
+		{"a", ""},
+		{"ext", ""},
+		{"trkpt", gpx},
+		{"gpx", gpx},
+		{"ns3:hr", ""}, // out of scope
+	}
+
+	for _, opts := range [][]xmltokenizer.Option{
+		{xmltokenizer.WithNamespaceResolution()},
+		{xmltokenizer.WithNamespaceResolution(), xmltokenizer.WithPersistentTokens()},
+		{xmltokenizer.WithNamespaceResolution(), xmltokenizer.WithNamespaceAliases(map[string]string{gpxtpx: "gpxtpx"})},
+	} {
+		tok := xmltokenizer.New(strings.NewReader(doc), opts...)
+		var tokens []xmltokenizer.Token
+		for {
+			token, err := tok.Token()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(token.Name.Full) == 0 {
+				if token.Name.Space != nil {
+					t.Fatalf("expected no namespace, got: %q", token.Name.Space)
+				}
+				continue
+			}
+			tokens = append(tokens, token.DeepCopy())
+		}
+
+		var spaces [][]string
+		for _, token := range tokens {
+			s := []string{string(token.Name.Local), string(token.Name.Space)}
+			if token.Name.Prefix != nil {
+				s[0] = "ns3:" + s[0] // the prefix may be aliased
+			}
+			for _, attr := range token.Attrs {
+				full := string(attr.Name.Full)
+				if string(attr.Name.Prefix) == "gpxtpx" {
+					full = "ns3:" + string(attr.Name.Local)
+				}
+				s = append(s, full, string(attr.Name.Space))
+			}
+			spaces = append(spaces, s)
+		}
+		if diff := cmp.Diff(spaces, expected); diff != "" {
+			t.Fatal(diff)
+		}
+	}
+}
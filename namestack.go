@@ -0,0 +1,33 @@
+package xmltokenizer
+
+// NameStack is a stack of element names, such as the ancestors of the current
+// element, whose names are copied so they remain valid across Token
+// invocations. The zero value is ready to use.
+type NameStack struct {
+	names []Name
+	buf   []byte
+}
+
+// Push pushes a copy of name onto the stack.
+func (s *NameStack) Push(name Name) {
+	if n := nameSize(name); n > cap(s.buf)-len(s.buf) {
+		// The names already pushed keep referring to the previous buffer.
+		s.buf = append(make([]byte, 0, 2*cap(s.buf)+n), s.buf...)
+	}
+	var copied Name
+	copied, s.buf = copyName(s.buf, name)
+	s.names = append(s.names, copied)
+}
+
+// Pop removes the last pushed name, if any.
+func (s *NameStack) Pop() {
+	if len(s.names) == 0 {
+		return
+	}
+	s.buf = s.buf[:len(s.buf)-nameSize(s.names[len(s.names)-1])]
+	s.names = s.names[:len(s.names)-1]
+}
+
+// Names returns the names from the bottom to the top of the stack. The
+// returned slice is only valid before the next Push or Pop invocation.
+func (s *NameStack) Names() []Name { return s.names }
@@ -0,0 +1,49 @@
+package xmltokenizer_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/muktihari/xmltokenizer"
+)
+
+func TestNameStack(t *testing.T) {
+	name := func(prefix, local string) xmltokenizer.Name {
+		full := local
+		if prefix != "" {
+			full = prefix + ":" + local
+		}
+		n := xmltokenizer.Name{Local: []byte(local), Full: []byte(full)}
+		if prefix != "" {
+			n.Prefix = []byte(prefix)
+		}
+		return n
+	}
+
+	var s xmltokenizer.NameStack
+	s.Pop() // no-op on an empty stack
+	a, b := name("", "gpx"), name("gpxtpx", "TrackPointExtension")
+	s.Push(a)
+	s.Push(b)
+	b.Full[0] = '!' // the stack holds copies
+	expected := []xmltokenizer.Name{name("", "gpx"), name("gpxtpx", "TrackPointExtension")}
+	if diff := cmp.Diff(s.Names(), expected); diff != "" {
+		t.Fatal(diff)
+	}
+
+	names := s.Names()
+	first := names[0]
+	s.Pop()
+	for i := 0; i < 100; i++ { // grow the buffer
+		s.Push(name("p", "c"))
+	}
+	for i := 0; i < 100; i++ {
+		s.Pop()
+	}
+	if diff := cmp.Diff(s.Names(), expected[:1]); diff != "" {
+		t.Fatal(diff)
+	}
+	if diff := cmp.Diff(first, expected[0]); diff != "" {
+		t.Fatalf("expected a name pushed before growing to remain valid: %s", diff)
+	}
+}
@@ -0,0 +1,159 @@
+// Package opml decodes OPML documents, the format feed readers use to import
+// and export subscription lists, using the xmltokenizer.
+package opml
+
+import (
+	"io"
+	"strings"
+
+	"github.com/muktihari/xmltokenizer"
+)
+
+// Document is an <opml> document.
+type Document struct {
+	Version  string
+	Head     Head
+	Outlines []Outline // Outlines of the <body>.
+}
+
+// Head is the <head> of a document, only the commonly used elements are kept.
+type Head struct {
+	Title        string
+	DateCreated  string
+	DateModified string
+	OwnerName    string
+	OwnerEmail   string
+}
+
+// Outline is an <outline>, either a subscription, which has an XMLURL, or a
+// folder of nested outlines.
+type Outline struct {
+	Text       string
+	Title      string
+	Type       string // e.g. "rss".
+	XMLURL     string // URL of the feed.
+	HTMLURL    string // URL of the website.
+	Categories []string
+	Outlines   []Outline
+}
+
+// Decode decodes the OPML document read from r, the options are passed to
+// the underlying Tokenizer.
+func Decode(r io.Reader, opts ...xmltokenizer.Option) (*Document, error) {
+	tok := xmltokenizer.New(r, opts...)
+	doc := new(Document)
+	var (
+		stack  []*Outline // open outlines
+		inHead bool
+	)
+	for {
+		token, err := tok.Token()
+		if err == io.EOF {
+			if len(stack) > 0 {
+				return doc, io.ErrUnexpectedEOF
+			}
+			return doc, nil
+		}
+		if err != nil {
+			return doc, err
+		}
+		if len(token.Name.Full) == 0 {
+			continue
+		}
+		local := string(token.Name.Local)
+		if token.IsEndElement {
+			switch local {
+			case "outline":
+				if len(stack) > 0 {
+					stack = stack[:len(stack)-1]
+				}
+			case "head":
+				inHead = false
+			}
+			continue
+		}
+		switch local {
+		case "opml":
+			doc.Version = attrValue(&token, "version")
+		case "head":
+			inHead = !token.SelfClosing
+		case "outline":
+			o := newOutline(&token)
+			if len(stack) == 0 {
+				doc.Outlines = append(doc.Outlines, o)
+				stack = append(stack, &doc.Outlines[len(doc.Outlines)-1])
+			} else {
+				parent := stack[len(stack)-1]
+				parent.Outlines = append(parent.Outlines, o)
+				stack = append(stack, &parent.Outlines[len(parent.Outlines)-1])
+			}
+			if token.SelfClosing {
+				stack = stack[:len(stack)-1]
+			}
+		default:
+			if !inHead {
+				continue
+			}
+			value := string(token.DataUnescaped(nil))
+			switch local {
+			case "title":
+				doc.Head.Title = value
+			case "dateCreated":
+				doc.Head.DateCreated = value
+			case "dateModified":
+				doc.Head.DateModified = value
+			case "ownerName":
+				doc.Head.OwnerName = value
+			case "ownerEmail":
+				doc.Head.OwnerEmail = value
+			}
+		}
+	}
+}
+
+// Feeds returns the outlines having an XMLURL, at any depth, in document
+// order, without their nested outlines.
+func (d *Document) Feeds() []Outline {
+	var feeds []Outline
+	var walk func(outlines []Outline)
+	walk = func(outlines []Outline) {
+		for _, o := range outlines {
+			if o.XMLURL != "" {
+				nested := o.Outlines
+				o.Outlines = nil
+				feeds = append(feeds, o)
+				walk(nested)
+				continue
+			}
+			walk(o.Outlines)
+		}
+	}
+	walk(d.Outlines)
+	return feeds
+}
+
+func newOutline(token *xmltokenizer.Token) Outline {
+	o := Outline{
+		Text:    attrValue(token, "text"),
+		Title:   attrValue(token, "title"),
+		Type:    attrValue(token, "type"),
+		XMLURL:  attrValue(token, "xmlUrl"),
+		HTMLURL: attrValue(token, "htmlUrl"),
+	}
+	for _, c := range strings.Split(attrValue(token, "category"), ",") {
+		if c = strings.TrimSpace(c); c != "" {
+			o.Categories = append(o.Categories, c)
+		}
+	}
+	return o
+}
+
+// attrValue returns the unescaped value of the attribute with the given local name.
+func attrValue(token *xmltokenizer.Token, local string) string {
+	for i := range token.Attrs {
+		if string(token.Attrs[i].Name.Local) == local {
+			return string(token.Attrs[i].ValueUnescaped(nil))
+		}
+	}
+	return ""
+}
@@ -0,0 +1,64 @@
+package opml_test
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/muktihari/xmltokenizer/opml"
+)
+
+const subscriptions = `<?xml version="1.0" encoding="UTF-8"?>
+<opml version="2.0">
+  <head>
+    <title>My &amp; subscriptions</title>
+    <dateCreated>Mon, 01 Jan 2024 00:00:00 GMT</dateCreated>
+    <ownerName>Jane</ownerName>
+  </head>
+  <body>
+    <outline text="Go" title="Go">
+      <outline type="rss" text="The Go Blog" xmlUrl="https://go.dev/blog/feed.atom" htmlUrl="https://go.dev/blog" category="/lang/go, news"/>
+      <outline text="Empty folder"></outline>
+    </outline>
+    <outline type="rss" text="XKCD" xmlUrl="https://xkcd.com/rss.xml?a=1&amp;b=2"/>
+  </body>
+</opml>`
+
+func TestDecode(t *testing.T) {
+	doc, err := opml.Decode(strings.NewReader(subscriptions))
+	if err != nil {
+		t.Fatal(err)
+	}
+	goBlog := opml.Outline{
+		Text: "The Go Blog", Type: "rss",
+		XMLURL: "https://go.dev/blog/feed.atom", HTMLURL: "https://go.dev/blog",
+		Categories: []string{"/lang/go", "news"},
+	}
+	xkcd := opml.Outline{Text: "XKCD", Type: "rss", XMLURL: "https://xkcd.com/rss.xml?a=1&b=2"}
+	expected := &opml.Document{
+		Version: "2.0",
+		Head: opml.Head{
+			Title:       "My & subscriptions",
+			DateCreated: "Mon, 01 Jan 2024 00:00:00 GMT",
+			OwnerName:   "Jane",
+		},
+		Outlines: []opml.Outline{
+			{Text: "Go", Title: "Go", Outlines: []opml.Outline{goBlog, {Text: "Empty folder"}}},
+			xkcd,
+		},
+	}
+	if diff := cmp.Diff(doc, expected); diff != "" {
+		t.Fatal(diff)
+	}
+	if diff := cmp.Diff(doc.Feeds(), []opml.Outline{goBlog, xkcd}); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestDecodeUnclosed(t *testing.T) {
+	_, err := opml.Decode(strings.NewReader(`<opml><body><outline text="a">`))
+	if err != io.ErrUnexpectedEOF {
+		t.Fatalf("expected error: %v, got: %v", io.ErrUnexpectedEOF, err)
+	}
+}
@@ -0,0 +1,26 @@
+package xmltokenizer
+
+import "io"
+
+// ParseUntil tokenizes the XML from r, invoking fn with every token until fn
+// returns false, so head-only tasks such as reading the metadata of a huge
+// document stop early without reading the rest of it. The token is only valid
+// during the invocation. It returns the byte offset where the last token passed
+// to fn ends, which is where tokenizing would continue, and the error that
+// stops it before fn returns false, if any other than io.EOF.
+func ParseUntil(r io.Reader, fn func(token Token) bool, opts ...Option) (offset int64, err error) {
+	t := New(r, opts...)
+	defer t.Close()
+	for {
+		token, err := t.Token()
+		if err != nil {
+			if err == io.EOF {
+				err = nil
+			}
+			return int64(t.token.End.Offset), err
+		}
+		if !fn(token) {
+			return int64(token.End.Offset), nil
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package xmltokenizer_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/muktihari/xmltokenizer"
+)
+
+func TestParseUntil(t *testing.T) {
+	t.Run("read metadata of a huge gpx", func(t *testing.T) {
+		f, err := os.Open("testdata/hike_mt_prau.gpx")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+
+		var time string
+		r := &readCounter{r: f}
+		offset, err := xmltokenizer.ParseUntil(r, func(token xmltokenizer.Token) bool {
+			if string(token.Name.Local) == "metadata" && token.IsEndElement {
+				return false
+			}
+			if string(token.Name.Local) == "time" && !token.IsEndElement {
+				time = string(token.Data)
+			}
+			return true
+		})
+		if err != nil {
+			t.Fatalf("expected nil, got: %v", err)
+		}
+		if expected := "2023-10-22T02:55:22Z"; time != expected {
+			t.Fatalf("expected: %q, got: %q", expected, time)
+		}
+
+		b, err := os.ReadFile("testdata/hike_mt_prau.gpx")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected := int64(bytes.Index(b, []byte("</metadata>")) + len("</metadata>")); offset != expected {
+			t.Fatalf("expected offset: %d, got: %d", expected, offset)
+		}
+		if r.reads != 1 { // out of the many needed to read the whole file
+			t.Fatalf("expected 1 read, got: %d", r.reads)
+		}
+	})
+
+	t.Run("until the end", func(t *testing.T) {
+		const doc = "<a><b/></a>\n"
+		var n int
+		offset, err := xmltokenizer.ParseUntil(strings.NewReader(doc), func(token xmltokenizer.Token) bool {
+			n++
+			return true
+		})
+		if err != nil {
+			t.Fatalf("expected nil, got: %v", err)
+		}
+		if n != 3 || offset != int64(len(doc)-1) {
+			t.Fatalf("expected 3 tokens and offset %d, got: %d tokens and offset %d", len(doc)-1, n, offset)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		offset, err := xmltokenizer.ParseUntil(strings.NewReader("<a><b>x</b><c"), func(token xmltokenizer.Token) bool {
+			return true
+		})
+		if !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Fatalf("expected error: %v, got: %v", io.ErrUnexpectedEOF, err)
+		}
+		if offset != 11 {
+			t.Fatalf("expected offset: %d, got: %d", 11, offset)
+		}
+	})
+}
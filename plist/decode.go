@@ -0,0 +1,276 @@
+// Package plist decodes and encodes Apple property lists in the XML format
+// using the xmltokenizer, mapping their values to Go values:
+//
+//	<dict>     map[string]any
+//	<array>    []any
+//	<string>   string
+//	<integer>  int64
+//	<real>     float64
+//	<true/>    bool
+//	<false/>   bool
+//	<date>     time.Time
+//	<data>     []byte
+package plist
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/muktihari/xmltokenizer"
+)
+
+type errorString string
+
+func (e errorString) Error() string { return string(e) }
+
+const (
+	errUnexpectedElement = errorString("unexpected element")
+	errMissingValue      = errorString("missing value")
+	errUnsupportedType   = errorString("unsupported type")
+)
+
+// dateLayout is the layout of the values of <date> elements.
+const dateLayout = "2006-01-02T15:04:05Z07:00"
+
+// Decode decodes the property list read from r, the options are passed to the
+// underlying Tokenizer, e.g. to allow the huge <data> values of provisioning
+// profiles with xmltokenizer.WithAutoGrowBufferMaxLimitSize.
+func Decode(r io.Reader, opts ...xmltokenizer.Option) (any, error) {
+	opts = append(opts[:len(opts):len(opts)], xmltokenizer.WithSpace())
+	d := decoder{tok: xmltokenizer.New(r, opts...)}
+	for {
+		token, err := d.tok.Token()
+		if err == io.EOF {
+			return nil, fmt.Errorf("plist: %w", errMissingValue)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("plist: %w", err)
+		}
+		if len(token.Name.Full) == 0 {
+			continue
+		}
+		if string(token.Name.Local) == "plist" && !token.IsEndElement {
+			if token.SelfClosing {
+				return nil, fmt.Errorf("plist: %w", errMissingValue)
+			}
+			continue
+		}
+		v, err := d.value(&token)
+		if err != nil {
+			return nil, fmt.Errorf("plist: %w", err)
+		}
+		return v, nil
+	}
+}
+
+type decoder struct {
+	tok *xmltokenizer.Tokenizer
+	buf []byte
+	raw []byte // content of the element being decoded by text
+}
+
+// value decodes the value started by the given token.
+func (d *decoder) value(token *xmltokenizer.Token) (any, error) {
+	if token.IsEndElement {
+		return nil, fmt.Errorf("%s at line %d: %w", token.Name.Full, token.Begin.Line, errUnexpectedElement)
+	}
+	line := token.Begin.Line
+	switch name := string(token.Name.Local); name {
+	case "dict":
+		if token.SelfClosing {
+			return map[string]any{}, nil
+		}
+		return d.dict()
+	case "array":
+		if token.SelfClosing {
+			return []any{}, nil
+		}
+		return d.array()
+	case "true", "false":
+		if err := d.end(token); err != nil {
+			return nil, err
+		}
+		return name == "true", nil
+	case "string":
+		b, err := d.text(token)
+		if err != nil {
+			return nil, err
+		}
+		return string(b), nil
+	case "integer", "real", "date", "data":
+		d.buf = token.DataUnescaped(d.buf[:0])
+		var v any
+		var err error
+		switch name {
+		case "integer":
+			v, err = strconv.ParseInt(string(d.buf), 0, 64)
+		case "real":
+			v, err = strconv.ParseFloat(string(d.buf), 64)
+		case "date":
+			v, err = time.Parse(dateLayout, string(d.buf))
+		case "data":
+			v, err = decodeData(d.buf)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s at line %d: %w", name, line, err)
+		}
+		if err = d.end(token); err != nil {
+			return nil, err
+		}
+		return v, nil
+	}
+	return nil, fmt.Errorf("%s at line %d: %w", token.Name.Full, line, errUnexpectedElement)
+}
+
+// dict decodes the content of a <dict> up to its end element.
+func (d *decoder) dict() (map[string]any, error) {
+	m := make(map[string]any)
+	for {
+		token, err := d.next()
+		if err != nil {
+			return nil, err
+		}
+		if token.IsEndElement && string(token.Name.Local) == "dict" {
+			return m, nil
+		}
+		if token.IsEndElement || string(token.Name.Local) != "key" {
+			return nil, fmt.Errorf("%s at line %d, expected key: %w", token.Name.Full, token.Begin.Line, errUnexpectedElement)
+		}
+		b, err := d.text(&token)
+		if err != nil {
+			return nil, err
+		}
+		key := string(b)
+		if token, err = d.next(); err != nil {
+			return nil, err
+		}
+		if m[key], err = d.value(&token); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// array decodes the content of an <array> up to its end element.
+func (d *decoder) array() ([]any, error) {
+	a := []any{}
+	for {
+		token, err := d.next()
+		if err != nil {
+			return nil, err
+		}
+		if token.IsEndElement && string(token.Name.Local) == "array" {
+			return a, nil
+		}
+		v, err := d.value(&token)
+		if err != nil {
+			return nil, err
+		}
+		a = append(a, v)
+	}
+}
+
+// next returns the next element, skipping comments.
+func (d *decoder) next() (xmltokenizer.Token, error) {
+	for {
+		token, err := d.tok.Token()
+		if err == io.EOF {
+			return token, io.ErrUnexpectedEOF
+		}
+		if err != nil || len(token.Name.Full) > 0 {
+			return token, err
+		}
+	}
+}
+
+// end consumes the end element of the given start element.
+func (d *decoder) end(se *xmltokenizer.Token) error {
+	if se.SelfClosing {
+		return nil
+	}
+	name := string(se.Name.Local) // se is invalidated by next
+	token, err := d.next()
+	if err != nil {
+		return err
+	}
+	if !token.IsEndElement || string(token.Name.Local) != name {
+		return fmt.Errorf("%s at line %d, expected end of %s: %w", token.Name.Full, token.Begin.Line, name, errUnexpectedElement)
+	}
+	return nil
+}
+
+// text consumes the element started by se up to its end element and returns
+// its content decoded into d.buf. Unlike Data, the content keeps its leading
+// and trailing whitespace, which is significant in <string> and <key>: it is
+// read from the Raw of se followed by the Space of the end element.
+func (d *decoder) text(se *xmltokenizer.Token) ([]byte, error) {
+	selfClosing := se.SelfClosing // se is invalidated by end
+	d.raw = d.raw[:0]
+	if !selfClosing {
+		raw := d.tok.Raw()
+		d.raw = append(d.raw, raw[tagEnd(raw):]...)
+	}
+	if err := d.end(se); err != nil {
+		return nil, err
+	}
+	if !selfClosing {
+		d.raw = append(d.raw, d.tok.Space()...)
+	}
+	d.buf = appendText(d.buf[:0], d.raw)
+	return d.buf, nil
+}
+
+// tagEnd returns the index following the ">" ending the tag that starts raw.
+func tagEnd(raw []byte) int {
+	var quote byte
+	for i, c := range raw {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '>':
+			return i + 1
+		}
+	}
+	return len(raw)
+}
+
+// appendText appends the CharData b to dst, unescaping the entities outside
+// of its CDATA sections.
+func appendText(dst, b []byte) []byte {
+	for {
+		i := bytes.Index(b, []byte("<![CDATA["))
+		if i == -1 {
+			return xmltokenizer.Attr{Value: b}.ValueUnescaped(dst)
+		}
+		dst = xmltokenizer.Attr{Value: b[:i]}.ValueUnescaped(dst)
+		b = b[i+len("<![CDATA["):]
+		j := bytes.Index(b, []byte("]]>"))
+		if j == -1 {
+			return append(dst, b...)
+		}
+		dst = append(dst, b[:j]...)
+		b = b[j+len("]]>"):]
+	}
+}
+
+// decodeData decodes base64 ignoring the whitespace, which is common in
+// <data> values.
+func decodeData(b []byte) ([]byte, error) {
+	n := 0
+	for _, c := range b {
+		if c != ' ' && c != '\t' && c != '\r' && c != '\n' {
+			b[n] = c
+			n++
+		}
+	}
+	dst := make([]byte, base64.StdEncoding.DecodedLen(n))
+	n, err := base64.StdEncoding.Decode(dst, b[:n])
+	return dst[:n], err
+}
@@ -0,0 +1,138 @@
+package plist
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const header = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+`
+
+// Encode writes v as a property list to w, indented with tabs as Apple's tools
+// do. The keys of dicts are sorted so the output is deterministic. v may be
+// any of the values returned by Decode, along with the other integer types and
+// float32; any other type results in an error.
+func Encode(w io.Writer, v any) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString(header)
+	if err := encodeValue(bw, v, 0); err != nil {
+		return fmt.Errorf("plist: %w", err)
+	}
+	bw.WriteString("</plist>\n")
+	return bw.Flush()
+}
+
+func encodeValue(w *bufio.Writer, v any, depth int) error {
+	indent(w, depth)
+	switch v := v.(type) {
+	case map[string]any:
+		if len(v) == 0 {
+			w.WriteString("<dict/>\n")
+			return nil
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		w.WriteString("<dict>\n")
+		for _, k := range keys {
+			indent(w, depth+1)
+			writeElement(w, "key", k)
+			if err := encodeValue(w, v[k], depth+1); err != nil {
+				return err
+			}
+		}
+		indent(w, depth)
+		w.WriteString("</dict>\n")
+	case []any:
+		if len(v) == 0 {
+			w.WriteString("<array/>\n")
+			return nil
+		}
+		w.WriteString("<array>\n")
+		for _, e := range v {
+			if err := encodeValue(w, e, depth+1); err != nil {
+				return err
+			}
+		}
+		indent(w, depth)
+		w.WriteString("</array>\n")
+	case string:
+		writeElement(w, "string", v)
+	case bool:
+		if v {
+			w.WriteString("<true/>\n")
+		} else {
+			w.WriteString("<false/>\n")
+		}
+	case int:
+		writeElement(w, "integer", strconv.FormatInt(int64(v), 10))
+	case int8:
+		writeElement(w, "integer", strconv.FormatInt(int64(v), 10))
+	case int16:
+		writeElement(w, "integer", strconv.FormatInt(int64(v), 10))
+	case int32:
+		writeElement(w, "integer", strconv.FormatInt(int64(v), 10))
+	case int64:
+		writeElement(w, "integer", strconv.FormatInt(v, 10))
+	case uint:
+		writeElement(w, "integer", strconv.FormatUint(uint64(v), 10))
+	case uint8:
+		writeElement(w, "integer", strconv.FormatUint(uint64(v), 10))
+	case uint16:
+		writeElement(w, "integer", strconv.FormatUint(uint64(v), 10))
+	case uint32:
+		writeElement(w, "integer", strconv.FormatUint(uint64(v), 10))
+	case uint64:
+		writeElement(w, "integer", strconv.FormatUint(v, 10))
+	case float32:
+		writeElement(w, "real", strconv.FormatFloat(float64(v), 'g', -1, 32))
+	case float64:
+		writeElement(w, "real", strconv.FormatFloat(v, 'g', -1, 64))
+	case time.Time:
+		writeElement(w, "date", v.UTC().Format(dateLayout))
+	case []byte:
+		writeElement(w, "data", base64.StdEncoding.EncodeToString(v))
+	default:
+		return fmt.Errorf("%T: %w", v, errUnsupportedType)
+	}
+	return nil
+}
+
+func indent(w *bufio.Writer, depth int) {
+	for i := 0; i < depth; i++ {
+		w.WriteByte('\t')
+	}
+}
+
+// writeElement writes <name>s</name> escaping s.
+func writeElement(w *bufio.Writer, name, s string) {
+	w.WriteString("<" + name + ">")
+	last := 0
+	for i := 0; i < len(s); i++ {
+		var esc string
+		switch s[i] {
+		case '&':
+			esc = "&amp;"
+		case '<':
+			esc = "&lt;"
+		case '>':
+			esc = "&gt;"
+		default:
+			continue
+		}
+		w.WriteString(s[last:i])
+		w.WriteString(esc)
+		last = i + 1
+	}
+	w.WriteString(s[last:])
+	w.WriteString("</" + name + ">\n")
+}
@@ -0,0 +1,183 @@
+package plist_test
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/muktihari/xmltokenizer/plist"
+)
+
+const profile = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>AppIDName</key>
+	<string>Fish &amp; Chips</string>
+	<key>CreationDate</key>
+	<date>2024-03-01T10:20:30Z</date>
+	<key>DeveloperCertificates</key>
+	<array>
+		<data>
+		aGVsbG8g
+		d29ybGQ=
+		</data>
+	</array>
+	<!-- entitlements -->
+	<key>Entitlements</key>
+	<dict>
+		<key>get-task-allow</key>
+		<false/>
+		<key>keychain-access-groups</key>
+		<array/>
+	</dict>
+	<key>IsXcodeManaged</key>
+	<true/>
+	<key>Ratio</key>
+	<real>0.5</real>
+	<key>TimeToLive</key>
+	<integer>365</integer>
+	<key>Empty</key>
+	<dict/>
+</dict>
+</plist>
+`
+
+func TestDecode(t *testing.T) {
+	v, err := plist.Decode(strings.NewReader(profile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]any{
+		"AppIDName":             "Fish & Chips",
+		"CreationDate":          time.Date(2024, 3, 1, 10, 20, 30, 0, time.UTC),
+		"DeveloperCertificates": []any{[]byte("hello world")},
+		"Entitlements": map[string]any{
+			"get-task-allow":         false,
+			"keychain-access-groups": []any{},
+		},
+		"IsXcodeManaged": true,
+		"Ratio":          0.5,
+		"TimeToLive":     int64(365),
+		"Empty":          map[string]any{},
+	}
+	if diff := cmp.Diff(v, expected); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tt := []struct {
+		name string
+		in   string
+		err  string
+	}{
+		{name: "empty", in: `<?xml version="1.0"?>`, err: "plist: missing value"},
+		{name: "empty plist", in: `<plist version="1.0"/>`, err: "plist: missing value"},
+		{name: "unknown element", in: `<plist><set/></plist>`, err: "plist: set at line 1: unexpected element"},
+		{name: "value without key", in: "<dict>\n<string>a</string></dict>", err: "plist: string at line 2, expected key: unexpected element"},
+		{name: "bad integer", in: `<integer>x</integer>`, err: `plist: integer at line 1: strconv.ParseInt: parsing "x": invalid syntax`},
+		{name: "mismatched end", in: `<string>a</integer>`, err: "plist: integer at line 1, expected end of string: unexpected element"},
+		{name: "unclosed", in: `<array><true/>`, err: "plist: " + io.ErrUnexpectedEOF.Error()},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := plist.Decode(strings.NewReader(tc.in))
+			if err == nil || err.Error() != tc.err {
+				t.Fatalf("expected error: %s, got: %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestEncode(t *testing.T) {
+	v := map[string]any{
+		"a": []any{"x < y", int64(-1), uint8(2), 1.5, true, false},
+		"b": map[string]any{},
+		"c": time.Date(2024, 3, 1, 10, 20, 30, 0, time.UTC),
+		"d": []byte("hi"),
+	}
+	var buf bytes.Buffer
+	if err := plist.Encode(&buf, v); err != nil {
+		t.Fatal(err)
+	}
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>a</key>
+	<array>
+		<string>x &lt; y</string>
+		<integer>-1</integer>
+		<integer>2</integer>
+		<real>1.5</real>
+		<true/>
+		<false/>
+	</array>
+	<key>b</key>
+	<dict/>
+	<key>c</key>
+	<date>2024-03-01T10:20:30Z</date>
+	<key>d</key>
+	<data>aGk=</data>
+</dict>
+</plist>
+`
+	if diff := cmp.Diff(buf.String(), expected); diff != "" {
+		t.Fatal(diff)
+	}
+
+	if err := plist.Encode(io.Discard, []any{math.Pi, struct{}{}}); err == nil || !strings.Contains(err.Error(), "unsupported type") {
+		t.Fatalf("expected error: unsupported type, got: %v", err)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	v, err := plist.Decode(strings.NewReader(profile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err = plist.Encode(&buf, v); err != nil {
+		t.Fatal(err)
+	}
+	got, err := plist.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(got, v); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestRoundTripWhitespace(t *testing.T) {
+	v := map[string]any{
+		"  padded key\n": []any{"  padded  ", "line\n", "\n\tindented\n", "", " ", "a & <b>"},
+	}
+	var buf bytes.Buffer
+	if err := plist.Encode(&buf, v); err != nil {
+		t.Fatal(err)
+	}
+	got, err := plist.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(got, any(v)); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestDecodeStringCDATA(t *testing.T) {
+	got, err := plist.Decode(strings.NewReader(`<plist><array><string> a &amp; <![CDATA[ <b> ]]></string><string/></array></plist>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(got, any([]any{" a &  <b> ", ""})); diff != "" {
+		t.Fatal(diff)
+	}
+}
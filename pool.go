@@ -0,0 +1,50 @@
+package xmltokenizer
+
+import (
+	"io"
+	"sync"
+)
+
+// Pool is a pool of Tokenizers sharing the same options, so servers parsing
+// many small documents reuse the Tokenizers and their buffers:
+//
+//	tok := p.Get(r)
+//	defer p.Put(tok)
+//
+// Combine it with WithMaxRetainedBuffer to bound the memory retained by the
+// pool. WithFixedBuffer can not be used since the buffer would be shared by
+// Tokenizers used concurrently. The zero value is ready to use with the default options.
+type Pool struct {
+	opts []Option
+	pool sync.Pool
+}
+
+// NewPool creates a Pool of Tokenizers created with the given options. It
+// panics if WithFixedBuffer is given.
+func NewPool(opts ...Option) *Pool {
+	o := defaultOptions()
+	for i := range opts {
+		opts[i](&o)
+	}
+	if o.fixedBuffer != nil {
+		panic("xmltokenizer: NewPool: WithFixedBuffer can not be shared by the Tokenizers of a Pool")
+	}
+	return &Pool{opts: opts}
+}
+
+// Get gets a Tokenizer reading from r from the pool, don't forget to put it back.
+func (p *Pool) Get(r io.Reader) *Tokenizer {
+	if t, ok := p.pool.Get().(*Tokenizer); ok {
+		t.Reset(r, p.opts...)
+		return t
+	}
+	return New(r, p.opts...)
+}
+
+// Put puts the Tokenizer back to the pool. It drops the reference to the
+// io.Reader without closing it. The Tokenizer, and any token or raw token
+// it returned, must not be used after Put.
+func (p *Pool) Put(t *Tokenizer) {
+	t.Reset(nil, p.opts...)
+	p.pool.Put(t)
+}
@@ -0,0 +1,66 @@
+package xmltokenizer_test
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/muktihari/xmltokenizer"
+)
+
+func TestPool(t *testing.T) {
+	p := xmltokenizer.NewPool(xmltokenizer.WithReadBufferSize(8))
+	for _, xml := range []string{`<a x="1">one</a>`, `<b>two</b>`, `<c/>`} {
+		tok := p.Get(strings.NewReader(xml))
+		var sb strings.Builder
+		for {
+			_, err := tok.Token()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			sb.Write(tok.Raw())
+		}
+		p.Put(tok)
+		if sb.String() != xml {
+			t.Fatalf("expected: %q, got: %q", xml, sb.String())
+		}
+	}
+
+	var zero xmltokenizer.Pool
+	tok := zero.Get(strings.NewReader("<a/>"))
+	if token, err := tok.Token(); err != nil || string(token.Name.Full) != "a" {
+		t.Fatalf("expected a, got: %s (%v)", token.Name.Full, err)
+	}
+	zero.Put(tok)
+}
+
+func TestNewPoolFixedBuffer(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatalf("expected panic")
+		}
+	}()
+	xmltokenizer.NewPool(xmltokenizer.WithFixedBuffer(make([]byte, 64)))
+}
+
+func BenchmarkPool(b *testing.B) {
+	const xml = `<row r="1"><c r="A1" t="s"><v>0</v></c></row>`
+	p := xmltokenizer.NewPool()
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		r := strings.NewReader(xml)
+		for pb.Next() {
+			r.Reset(xml)
+			tok := p.Get(r)
+			for {
+				if _, err := tok.Token(); err != nil {
+					break
+				}
+			}
+			p.Put(tok)
+		}
+	})
+}
@@ -0,0 +1,210 @@
+package xmltokenizer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"unicode/utf8"
+)
+
+// PreviewOptions configures Preview.
+type PreviewOptions struct {
+	// Indent is written once per level of nesting, "  " if empty.
+	Indent string
+	// MaxDepth is the depth, from 1 for the root element, of the deepest
+	// elements written: the content of an element at MaxDepth following its
+	// first child element is summarized in a comment counting its elements and
+	// bytes of CharData. Zero means no limit.
+	MaxDepth int
+	// MaxText is the number of bytes of a CharData, an attribute value or a
+	// "<?" or "<!" tag written before the rest is elided. Zero means no limit.
+	MaxText int
+}
+
+// Preview tokenizes r and writes a human-skimmable preview of the document to
+// w, one tag per line indented by its depth, e.g.
+//
+//	<gpx version="1.1">
+//	  <metadata>
+//	    <name>Morning Ride</name>
+//	  </metadata>
+//	  <trk>
+//	    <!-- 3601 elements and 52004 bytes of text elided -->
+//	  </trk>
+//	</gpx>
+//
+// where an element whose content is only CharData is written on one line.
+// The elements below po.MaxDepth are summarized and the texts longer than
+// po.MaxText are elided, so enormous documents can be previewed by log
+// tooling or support engineers in a bounded size. CharData, attribute values
+// and "<?" and "<!" tags are written as they are read, without escaping. It
+// stops on the first error other than io.EOF. The output is intended for
+// humans, its format is not guaranteed to be stable.
+func Preview(w io.Writer, r io.Reader, po PreviewOptions, opts ...Option) error {
+	if po.Indent == "" {
+		po.Indent = "  "
+	}
+	p := previewer{w: bufio.NewWriter(w), tok: New(r, opts...), po: po}
+	if err := p.run(); err != nil {
+		p.w.Flush()
+		return err
+	}
+	return p.w.Flush()
+}
+
+type previewer struct {
+	w       *bufio.Writer
+	tok     *Tokenizer
+	po      PreviewOptions
+	depth   int    // number of open elements
+	line    []byte // line being written
+	pending bool   // whether line is a start element that may be closed inline
+}
+
+func (p *previewer) run() error {
+	for {
+		token, err := p.tok.Token()
+		if err != nil {
+			p.flushLine()
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+
+		kind := p.tok.Kind()
+		if kind == EndElement && p.pending {
+			p.depth--
+			p.line = append(p.line, "</"...)
+			p.line = append(p.line, token.Name.Full...)
+			p.line = append(p.line, '>')
+			p.flushLine()
+			p.writeText(token.Data)
+			continue
+		}
+		p.flushLine()
+
+		switch kind {
+		case StartElement, SelfClosing:
+			if p.po.MaxDepth > 0 && p.depth >= p.po.MaxDepth {
+				if err := p.summarize(token); err != nil {
+					if err == io.EOF {
+						return nil
+					}
+					return err
+				}
+				continue
+			}
+			p.line = p.appendIndent(p.line[:0])
+			p.line = append(p.line, '<')
+			p.line = append(p.line, token.Name.Full...)
+			for i := range token.Attrs {
+				p.line = append(p.line, ' ')
+				p.line = append(p.line, token.Attrs[i].Name.Full...)
+				p.line = append(p.line, `="`...)
+				p.line = p.appendElided(p.line, token.Attrs[i].Value)
+				p.line = append(p.line, '"')
+			}
+			if kind == SelfClosing {
+				p.line = append(p.line, "/>"...)
+				p.flushLine()
+				p.writeText(token.Data)
+				continue
+			}
+			p.line = append(p.line, '>')
+			p.line = p.appendElided(p.line, token.Data)
+			p.depth++
+			p.pending = true
+		case EndElement:
+			p.depth = max(p.depth-1, 0)
+			p.writeEnd(token.Name.Full)
+			p.writeText(token.Data)
+		default: // CharData, CDATA, Comment, ProcInst and Directive
+			p.writeText(token.Data)
+		}
+	}
+}
+
+// summarize consumes the content of the element at MaxDepth, starting with
+// the given child element, and writes a comment counting what is elided, it
+// is written at the end of a truncated document too.
+func (p *previewer) summarize(token Token) error {
+	elements, text := 0, 0
+	for depth := p.depth; ; {
+		switch p.tok.Kind() {
+		case StartElement:
+			elements, depth = elements+1, depth+1
+		case SelfClosing:
+			elements++
+		case EndElement:
+			if depth--; depth < p.depth {
+				p.writeSummary(elements, text)
+				p.depth--
+				p.writeEnd(token.Name.Full)
+				p.writeText(token.Data)
+				return nil
+			}
+		}
+		text += len(token.Data)
+
+		var err error
+		if token, err = p.tok.Token(); err != nil {
+			p.writeSummary(elements, text)
+			return err
+		}
+	}
+}
+
+func (p *previewer) writeSummary(elements, text int) {
+	p.line = p.appendIndent(p.line[:0])
+	p.line = fmt.Appendf(p.line, "<!-- %d elements and %d bytes of text elided -->", elements, text)
+	p.flushLine()
+}
+
+// writeText writes the CharData or tag b elided on its own line, if not empty.
+func (p *previewer) writeText(b []byte) {
+	if len(b) == 0 {
+		return
+	}
+	p.line = p.appendIndent(p.line[:0])
+	p.line = p.appendElided(p.line, b)
+	p.flushLine()
+}
+
+// writeEnd writes the end element named name on its own line.
+func (p *previewer) writeEnd(name []byte) {
+	p.line = p.appendIndent(p.line[:0])
+	p.line = append(p.line, "</"...)
+	p.line = append(p.line, name...)
+	p.line = append(p.line, '>')
+	p.flushLine()
+}
+
+// flushLine writes the line, if any.
+func (p *previewer) flushLine() {
+	if len(p.line) == 0 {
+		return
+	}
+	p.w.Write(p.line)
+	p.w.WriteByte('\n')
+	p.line, p.pending = p.line[:0], false
+}
+
+func (p *previewer) appendIndent(dst []byte) []byte {
+	for i := 0; i < p.depth; i++ {
+		dst = append(dst, p.po.Indent...)
+	}
+	return dst
+}
+
+// appendElided appends b to dst, eliding the bytes beyond MaxText.
+func (p *previewer) appendElided(dst, b []byte) []byte {
+	if p.po.MaxText <= 0 || len(b) <= p.po.MaxText {
+		return append(dst, b...)
+	}
+	n := p.po.MaxText
+	for n > 0 && !utf8.RuneStart(b[n]) {
+		n--
+	}
+	return fmt.Appendf(append(dst, b[:n]...), "...(%d bytes elided)", len(b)-n)
+}
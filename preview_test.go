@@ -0,0 +1,97 @@
+package xmltokenizer_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/muktihari/xmltokenizer"
+)
+
+func TestPreview(t *testing.T) {
+	const doc = `<?xml version="1.0"?>
+<gpx version="1.1" creator="a very long creator name"><metadata><name>Morning Ride</name><desc/></metadata>
+<trk><name>Ride</name><trkseg><trkpt lat="1"><ele>1</ele></trkpt><trkpt lat="2"/></trkseg></trk>
+<!-- comment --><extensions>text <b>bold</b> tail</extensions></gpx>`
+
+	tt := []struct {
+		name     string
+		in       string
+		po       xmltokenizer.PreviewOptions
+		expected string
+	}{
+		{
+			name: "pretty print",
+			in:   doc,
+			expected: `<?xml version="1.0"?>
+<gpx version="1.1" creator="a very long creator name">
+  <metadata>
+    <name>Morning Ride</name>
+    <desc/>
+  </metadata>
+  <trk>
+    <name>Ride</name>
+    <trkseg>
+      <trkpt lat="1">
+        <ele>1</ele>
+      </trkpt>
+      <trkpt lat="2"/>
+    </trkseg>
+  </trk>
+  <!-- comment -->
+  <extensions>text
+    <b>bold</b>
+    tail
+  </extensions>
+</gpx>
+`,
+		},
+		{
+			name: "max depth and max text",
+			in:   doc,
+			po:   xmltokenizer.PreviewOptions{Indent: "\t", MaxDepth: 2, MaxText: 8},
+			expected: `<?xml ve...(13 bytes elided)
+<gpx version="1.1" creator="a very l...(16 bytes elided)">
+	<metadata>
+		<!-- 2 elements and 12 bytes of text elided -->
+	</metadata>
+	<trk>
+		<!-- 5 elements and 5 bytes of text elided -->
+	</trk>
+	<!-- com...(8 bytes elided)
+	<extensions>text
+		<!-- 1 elements and 8 bytes of text elided -->
+	</extensions>
+</gpx>
+`,
+		},
+		{
+			name: "truncated",
+			in:   `<a><b><c>1</c><c>2</c>`,
+			po:   xmltokenizer.PreviewOptions{MaxDepth: 2},
+			expected: `<a>
+  <b>
+    <!-- 2 elements and 2 bytes of text elided -->
+`,
+		},
+		{
+			name:     "utf-8 boundary",
+			in:       `<a>ééé</a>`,
+			po:       xmltokenizer.PreviewOptions{MaxText: 3},
+			expected: "<a>é...(4 bytes elided)</a>\n",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := xmltokenizer.Preview(&buf, strings.NewReader(tc.in), tc.po); err != nil {
+				t.Fatalf("expected nil, got: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, buf.String()); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package xmltokenizer
+
+import "io"
+
+// DocumentProfile is a summary of an XML document's structure, see Profile.
+type DocumentProfile struct {
+	Tokens         int            // Number of tokens.
+	Elements       map[string]int // Number of occurrences of each element by its full name.
+	Attrs          map[string]int // Number of occurrences of each attribute by its full name.
+	MaxDepth       int            // Maximum nesting depth of elements, the root element is at depth 1.
+	MaxAttrs       int            // Maximum number of attributes of a single element.
+	MaxTokenSize   int            // Size in bytes of the largest token, including its CharData.
+	MaxDataSize    int            // Size in bytes of the largest CharData or CDATA.
+	MaxDataElement string         // Full name of the element having the largest CharData or CDATA.
+}
+
+// Profile tokenizes r and returns its DocumentProfile, such as element and
+// attribute frequency, maximum depth and the largest token. It helps to size
+// the Tokenizer's options (e.g. WithAttrBufferSize and WithAutoGrowBufferMaxLimitSize)
+// and to design schemas for unfamiliar documents. It returns the profile of the
+// tokens found so far along with the error if any, except io.EOF.
+func Profile(r io.Reader, opts ...Option) (*DocumentProfile, error) {
+	p := &DocumentProfile{
+		Elements: make(map[string]int),
+		Attrs:    make(map[string]int),
+	}
+	tok := New(r, opts...)
+	var depth int
+	for {
+		token, err := tok.Token()
+		if err == io.EOF {
+			return p, nil
+		}
+		if err != nil {
+			return p, err
+		}
+		p.Tokens++
+		p.MaxTokenSize = max(p.MaxTokenSize, token.End.Offset-token.Begin.Offset)
+		if len(token.Name.Full) == 0 {
+			continue
+		}
+		if token.IsEndElement {
+			depth--
+			continue
+		}
+
+		p.Elements[string(token.Name.Full)]++
+		for i := range token.Attrs {
+			p.Attrs[string(token.Attrs[i].Name.Full)]++
+		}
+		p.MaxAttrs = max(p.MaxAttrs, len(token.Attrs))
+		if len(token.Data) > p.MaxDataSize {
+			p.MaxDataSize = len(token.Data)
+			p.MaxDataElement = string(token.Name.Full)
+		}
+		p.MaxDepth = max(p.MaxDepth, depth+1)
+		if !token.SelfClosing {
+			depth++
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package xmltokenizer_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/muktihari/xmltokenizer"
+)
+
+func TestProfile(t *testing.T) {
+	tt := []struct {
+		name     string
+		xml      string
+		expected *xmltokenizer.DocumentProfile
+		err      error
+	}{
+		{
+			name: "gpx",
+			xml: `<?xml version="1.0"?>
+<gpx version="1.1" creator="me">
+  <trk>
+    <name>Morning Ride</name>
+    <trkseg>
+      <trkpt lat="1" lon="2"><ele>10</ele></trkpt>
+      <trkpt lat="3" lon="4"/>
+    </trkseg>
+  </trk>
+</gpx>`,
+			expected: &xmltokenizer.DocumentProfile{
+				Tokens:         14,
+				Elements:       map[string]int{"gpx": 1, "trk": 1, "name": 1, "trkseg": 1, "trkpt": 2, "ele": 1},
+				Attrs:          map[string]int{"version": 1, "creator": 1, "lat": 2, "lon": 2},
+				MaxDepth:       5,
+				MaxAttrs:       2,
+				MaxTokenSize:   32,
+				MaxDataSize:    12,
+				MaxDataElement: "name",
+			},
+		},
+		{
+			name: "truncated",
+			xml:  `<a><b>text</b><c`,
+			expected: &xmltokenizer.DocumentProfile{
+				Tokens:         3,
+				Elements:       map[string]int{"a": 1, "b": 1},
+				Attrs:          map[string]int{},
+				MaxDepth:       2,
+				MaxTokenSize:   7,
+				MaxDataSize:    4,
+				MaxDataElement: "b",
+			},
+			err: io.ErrUnexpectedEOF,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := xmltokenizer.Profile(strings.NewReader(tc.xml))
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expected error: %v, got: %v", tc.err, err)
+			}
+			if diff := cmp.Diff(p, tc.expected); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
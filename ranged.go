@@ -0,0 +1,96 @@
+package xmltokenizer
+
+import (
+	"bytes"
+	"io"
+)
+
+// NewRanged creates a Tokenizer of the tokens beginning within the byte range
+// [begin, end) of the document of the given size read from ra, which is how
+// object stores expose an object through range requests, so a huge document
+// can be processed by workers each given a range without every worker reading
+// the whole document.
+//
+// Unless begin is 0, the bytes up to the first "<" plausibly starting a tag are
+// discarded, see SyncToNextTag, so tokenizing resynchronizes at a tag boundary.
+// The last token is the last one beginning before end, it is read in full even
+// if it extends beyond end, so adjacent ranges split the tokens of the document
+// between them without overlap, provided that begin is not within a comment, a
+// CDATA section or a processing instruction containing a "<".
+//
+// The Offset of the positions is within the document while the Line and
+// Column are relative to begin. The elements open at begin are unknown to the
+// Tokenizer, so the options relying on the ancestors of a token, such as
+// WithNamespaceCheck, are not suitable.
+func NewRanged(ra io.ReaderAt, size, begin, end int64, opts ...Option) *Tokenizer {
+	t := New(io.NewSectionReader(ra, 0, size), opts...)
+	begin, end = max(begin, 0), min(end, size)
+	if begin >= end {
+		t.err = io.EOF
+		return t
+	}
+	t.off, t.rangeEnd = begin, end
+	t.token.Begin = Pos{Line: 1, Column: 1, Offset: int(begin)}
+	t.token.End = t.token.Begin
+	if begin > 0 {
+		t.SyncToNextTag()
+	}
+	return t
+}
+
+// SyncToNextTag discards the bytes up to the next "<" plausibly starting a tag,
+// that is followed by a name, "/", "?" or "!", so tokenizing resumes at a tag
+// boundary when the Tokenizer starts at an arbitrary offset in the middle of a
+// document, such as a range of it, see NewRanged, or the offset a crashed
+// process saved before. The discarded bytes are not returned by Space. A "<"
+// within a comment, a CDATA section or a processing instruction may be
+// mistaken for a tag. It returns io.EOF if there is no tag left, or the last
+// error if one is latched, see Recover.
+func (t *Tokenizer) SyncToNextTag() error {
+	if t.err != nil {
+		return t.err
+	}
+	t.chunk, t.partial, t.continued = chunkNone, false, false
+	for {
+		for p := t.cur; ; p++ {
+			i := bytes.IndexByte(t.buf[p:], '<')
+			if i == -1 || p+i+1 == len(t.buf) {
+				break // the byte following '<' is not read yet
+			}
+			if p += i; isTagStart(t.buf[p+1]) {
+				t.discard(p - t.cur)
+				return nil
+			}
+		}
+		n := len(t.buf) - t.cur
+		if n > 0 && t.buf[len(t.buf)-1] == '<' {
+			n--
+		}
+		t.discard(n)
+		t.memmoveRemainingBytes(t.cur)
+		if t.err = t.manageBuffer(); t.err != nil {
+			if t.err == io.EOF {
+				t.discard(len(t.buf) - t.cur)
+			}
+			return t.err
+		}
+	}
+}
+
+// discard steps over the next n bytes without returning them in the space.
+func (t *Tokenizer) discard(n int) {
+	t.step(&t.token.End, t.buf[t.cur:t.cur+n])
+	t.advance(n)
+	t.token.Begin = t.token.End
+}
+
+// isTagStart reports whether c may follow the "<" starting a tag.
+func isTagStart(c byte) bool {
+	switch {
+	case c == '/', c == '?', c == '!', c == '_', c == ':':
+		return true
+	case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z':
+		return true
+	}
+	return c >= 0x80 // non-ASCII name start character
+}
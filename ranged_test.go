@@ -0,0 +1,133 @@
+package xmltokenizer_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/muktihari/xmltokenizer"
+)
+
+type rangedToken struct {
+	Raw    string
+	Offset int
+}
+
+func tokenizeRanged(t *testing.T, tok *xmltokenizer.Tokenizer) []rangedToken {
+	var tokens []rangedToken
+	for {
+		token, err := tok.Token()
+		if err == io.EOF {
+			return tokens
+		}
+		if err != nil {
+			t.Fatalf("expected nil, got: %v", err)
+		}
+		tokens = append(tokens, rangedToken{Raw: string(tok.Raw()), Offset: token.Begin.Offset})
+	}
+}
+
+func TestNewRanged(t *testing.T) {
+	var sb strings.Builder
+	sb.WriteString("<?xml version=\"1.0\"?>\n<items>\n")
+	for i := 0; i < 200; i++ {
+		fmt.Fprintf(&sb, "  <item id=\"%d\"><name>item %d</name><!-- note %d --><empty/></item>\n", i, i, i)
+	}
+	sb.WriteString("</items>\n")
+	doc := []byte(sb.String())
+	size := int64(len(doc))
+
+	expected := tokenizeRanged(t, xmltokenizer.NewRanged(bytes.NewReader(doc), size, 0, size))
+	if n := len(expected); n != 3+200*6 {
+		t.Fatalf("expected %d tokens, got: %d", 3+200*6, n)
+	}
+
+	for _, workers := range []int64{2, 3, 7, 64} {
+		t.Run(fmt.Sprintf("%d workers", workers), func(t *testing.T) {
+			var tokens []rangedToken
+			for i := int64(0); i < workers; i++ {
+				begin, end := size*i/workers, size*(i+1)/workers
+				tok := xmltokenizer.NewRanged(bytes.NewReader(doc), size, begin, end,
+					xmltokenizer.WithReadBufferSize(64))
+				tokens = append(tokens, tokenizeRanged(t, tok)...)
+			}
+			if diff := cmp.Diff(expected, tokens); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+
+	t.Run("positions", func(t *testing.T) {
+		doc := []byte("<a>\n  <b>x</b>\n  <c/>\n</a>")
+		tok := xmltokenizer.NewRanged(bytes.NewReader(doc), int64(len(doc)), 5, int64(len(doc)))
+		token, err := tok.Token()
+		if err != nil {
+			t.Fatalf("expected nil, got: %v", err)
+		}
+		expected := xmltokenizer.Pos{Line: 1, Column: 2, Offset: 6} // relative to begin
+		if diff := cmp.Diff(expected, token.Begin); diff != "" {
+			t.Fatal(diff)
+		}
+	})
+
+	t.Run("no tag in range", func(t *testing.T) {
+		doc := []byte("<a>some text < not a tag</a>")
+		tok := xmltokenizer.NewRanged(bytes.NewReader(doc), int64(len(doc)), 4, 20)
+		if _, err := tok.Token(); err != io.EOF {
+			t.Fatalf("expected error: %v, got: %v", io.EOF, err)
+		}
+	})
+
+	t.Run("empty range", func(t *testing.T) {
+		tok := xmltokenizer.NewRanged(bytes.NewReader(doc), size, 10, 10)
+		if _, err := tok.Token(); err != io.EOF {
+			t.Fatalf("expected error: %v, got: %v", io.EOF, err)
+		}
+	})
+}
+
+func TestSyncToNextTag(t *testing.T) {
+	const doc = `<a><b k="1">text</b><!-- c --><d/></a>`
+
+	tt := []struct {
+		name     string
+		offset   int
+		expected []string
+		err      error
+	}{
+		{name: "at a tag", offset: 3, expected: []string{`<b k="1">text`, `</b>`, `<!-- c -->`, `<d/>`, `</a>`}},
+		{name: "within a tag", offset: 5, expected: []string{`</b>`, `<!-- c -->`, `<d/>`, `</a>`}},
+		{name: "within chardata", offset: 14, expected: []string{`</b>`, `<!-- c -->`, `<d/>`, `</a>`}},
+		{name: "within the last tag", offset: len(doc) - 2, err: io.EOF},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tok := xmltokenizer.New(iotest.OneByteReader(strings.NewReader(doc[tc.offset:])), xmltokenizer.WithSpace())
+			if err := tok.SyncToNextTag(); err != tc.err {
+				t.Fatalf("expected error: %v, got: %v", tc.err, err)
+			}
+			var raws []string
+			for {
+				_, err := tok.Token()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatalf("expected nil, got: %v", err)
+				}
+				if len(tok.Space()) != 0 {
+					t.Fatalf("expected no space, got: %q", tok.Space())
+				}
+				raws = append(raws, string(tok.Raw()))
+			}
+			if diff := cmp.Diff(tc.expected, raws); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
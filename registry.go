@@ -0,0 +1,106 @@
+package xmltokenizer
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// ErrUnknownFormat is returned by Registry.Detect when no Format is
+// registered for the root element of the document.
+const ErrUnknownFormat = errorString("unknown format")
+
+// Format identifies a kind of XML document.
+type Format struct {
+	Name     string // Identifier of the format, e.g. "gpx".
+	MIMEType string // MIME type of the format, e.g. "application/gpx+xml".
+}
+
+// Registry maps the root element and its namespace URI to a Format, so
+// documents can be dispatched to the right decoder by their DocumentHead. It
+// is safe for concurrent use.
+type Registry struct {
+	mu      sync.RWMutex
+	formats map[formatKey]Format
+}
+
+type formatKey struct{ local, space string }
+
+// NewRegistry creates an empty Registry, see DefaultRegistry for the one
+// knowing the common formats.
+func NewRegistry() *Registry {
+	return &Registry{formats: make(map[formatKey]Format)}
+}
+
+// DefaultRegistry knows GPX, TCX, KML, Atom, RSS, SVG, XHTML, OPML, plist,
+// XLIFF and JUnit documents, more can be registered.
+var DefaultRegistry = newDefaultRegistry()
+
+func newDefaultRegistry() *Registry {
+	r := NewRegistry()
+	gpx := Format{Name: "gpx", MIMEType: "application/gpx+xml"}
+	r.Register("gpx", "http://www.topografix.com/GPX/1/1", gpx)
+	r.Register("gpx", "http://www.topografix.com/GPX/1/0", gpx)
+	r.Register("TrainingCenterDatabase", "http://www.garmin.com/xmlschemas/TrainingCenterDatabase/v2",
+		Format{Name: "tcx", MIMEType: "application/vnd.garmin.tcx+xml"})
+	kml := Format{Name: "kml", MIMEType: "application/vnd.google-earth.kml+xml"}
+	r.Register("kml", "http://www.opengis.net/kml/2.2", kml)
+	r.Register("kml", "http://earth.google.com/kml/2.2", kml)
+	r.Register("kml", "http://earth.google.com/kml/2.1", kml)
+	r.Register("feed", "http://www.w3.org/2005/Atom", Format{Name: "atom", MIMEType: "application/atom+xml"})
+	r.Register("rss", "", Format{Name: "rss", MIMEType: "application/rss+xml"})
+	r.Register("svg", "http://www.w3.org/2000/svg", Format{Name: "svg", MIMEType: "image/svg+xml"})
+	r.Register("html", "http://www.w3.org/1999/xhtml", Format{Name: "xhtml", MIMEType: "application/xhtml+xml"})
+	r.Register("opml", "", Format{Name: "opml", MIMEType: "text/x-opml"})
+	r.Register("plist", "", Format{Name: "plist", MIMEType: "application/x-plist"})
+	xliff := Format{Name: "xliff", MIMEType: "application/xliff+xml"}
+	r.Register("xliff", "urn:oasis:names:tc:xliff:document:1.2", xliff)
+	r.Register("xliff", "urn:oasis:names:tc:xliff:document:2.0", xliff)
+	junit := Format{Name: "junit", MIMEType: "application/junit+xml"}
+	r.Register("testsuites", "", junit)
+	r.Register("testsuite", "", junit)
+	return r
+}
+
+// Register maps the root element with the local name local in the namespace
+// URI space to f, replacing the previous Format if any. An empty space
+// matches root elements without namespace.
+func (r *Registry) Register(local, space string, f Format) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.formats[formatKey{local: local, space: space}] = f
+}
+
+// Lookup returns the Format registered for the root element of h, if any.
+func (r *Registry) Lookup(h *DocumentHead) (Format, bool) {
+	local := h.Root
+	if i := strings.IndexByte(local, ':'); i != -1 {
+		local = local[i+1:]
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	f, ok := r.formats[formatKey{local: local, space: h.RootSpace}]
+	return f, ok
+}
+
+// Detect sniffs the document from rd, see Sniff, and returns its Format
+// along with a reader producing the whole document again, the sniffed bytes
+// included, to be given to the decoder of the Format. An error wrapping
+// ErrUnknownFormat is returned if no Format is registered for the root
+// element, an error of Sniff is returned as it is; the reader is returned in
+// any case.
+func (r *Registry) Detect(rd io.Reader, limit int, opts ...Option) (Format, io.Reader, error) {
+	var head bytes.Buffer
+	h, err := Sniff(io.TeeReader(rd, &head), limit, opts...)
+	rd = io.MultiReader(&head, rd)
+	if err != nil {
+		return Format{}, rd, err
+	}
+	f, ok := r.Lookup(h)
+	if !ok {
+		return Format{}, rd, fmt.Errorf("root element %q in namespace %q: %w", h.Root, h.RootSpace, ErrUnknownFormat)
+	}
+	return f, rd, nil
+}
@@ -0,0 +1,103 @@
+package xmltokenizer_test
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/muktihari/xmltokenizer"
+)
+
+func TestRegistryDetect(t *testing.T) {
+	tt := []struct {
+		name     string
+		xml      string
+		expected xmltokenizer.Format
+		err      error
+	}{
+		{
+			name:     "gpx",
+			xml:      `<?xml version="1.0"?><gpx xmlns="http://www.topografix.com/GPX/1/1" version="1.1"><trk/></gpx>`,
+			expected: xmltokenizer.Format{Name: "gpx", MIMEType: "application/gpx+xml"},
+		},
+		{
+			name:     "prefixed kml",
+			xml:      `<k:kml xmlns:k="http://www.opengis.net/kml/2.2"/>`,
+			expected: xmltokenizer.Format{Name: "kml", MIMEType: "application/vnd.google-earth.kml+xml"},
+		},
+		{
+			name:     "rss without namespace",
+			xml:      `<rss version="2.0"><channel/></rss>`,
+			expected: xmltokenizer.Format{Name: "rss", MIMEType: "application/rss+xml"},
+		},
+		{
+			name: "gpx root in another namespace",
+			xml:  `<gpx xmlns="urn:other"/>`,
+			err:  xmltokenizer.ErrUnknownFormat,
+		},
+		{
+			name: "unknown",
+			xml:  `<a/>`,
+			err:  xmltokenizer.ErrUnknownFormat,
+		},
+		{
+			name: "truncated",
+			xml:  `<gpx`,
+			err:  io.ErrUnexpectedEOF,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			f, rd, err := xmltokenizer.DefaultRegistry.Detect(strings.NewReader(tc.xml), 0)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expected error: %v, got: %v", tc.err, err)
+			}
+			if f != tc.expected {
+				t.Fatalf("expected: %v, got: %v", tc.expected, f)
+			}
+			b, err := io.ReadAll(rd)
+			if err != nil {
+				t.Fatalf("expected nil, got: %v", err)
+			}
+			if string(b) != tc.xml {
+				t.Fatalf("expected document: %q, got: %q", tc.xml, b)
+			}
+		})
+	}
+}
+
+func TestRegistryRegister(t *testing.T) {
+	r := xmltokenizer.NewRegistry()
+	h := &xmltokenizer.DocumentHead{Root: "x:Invoice", RootSpace: "urn:invoice"}
+	if _, ok := r.Lookup(h); ok {
+		t.Fatalf("expected not found")
+	}
+
+	expected := xmltokenizer.Format{Name: "invoice", MIMEType: "application/vnd.example.invoice+xml"}
+	r.Register("Invoice", "urn:invoice", expected)
+	if f, ok := r.Lookup(h); !ok || f != expected {
+		t.Fatalf("expected: %v, got: %v (%t)", expected, f, ok)
+	}
+	if _, ok := r.Lookup(&xmltokenizer.DocumentHead{Root: "Invoice"}); ok {
+		t.Fatalf("expected not found without namespace")
+	}
+}
+
+func TestDefaultRegistryTestdata(t *testing.T) {
+	f, err := os.Open("testdata/ride_sembalun.gpx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	format, _, err := xmltokenizer.DefaultRegistry.Detect(f, 0)
+	if err != nil {
+		t.Fatalf("expected nil, got: %v", err)
+	}
+	if format.Name != "gpx" {
+		t.Fatalf("expected: %q, got: %q", "gpx", format.Name)
+	}
+}
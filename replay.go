@@ -0,0 +1,46 @@
+package xmltokenizer
+
+import "io"
+
+// Replayer replays a stream of tokens written by a TokenEncoder, such as by
+// DumpBinary, through the same Token, RawToken and Raw methods as a
+// Tokenizer, so the code consuming the tokens can be tested and benchmarked
+// independently of tokenizing the XML.
+type Replayer struct {
+	dec *TokenDecoder
+	raw []byte
+	err error
+}
+
+// NewReplayer creates a Replayer reading the stream of tokens from r.
+func NewReplayer(r io.Reader) *Replayer {
+	return &Replayer{dec: NewTokenDecoder(r)}
+}
+
+// Token returns either the next recorded token or an error, io.EOF once the
+// stream ends. The returned token is only valid before next Token or RawToken
+// method invocation.
+func (r *Replayer) Token() (token Token, err error) {
+	if r.err != nil {
+		return token, r.err
+	}
+	token, r.raw, r.err = r.dec.Decode()
+	return token, r.err
+}
+
+// RawToken returns the raw bytes of the next recorded token, see Token.
+func (r *Replayer) RawToken() ([]byte, error) {
+	if _, err := r.Token(); err != nil {
+		return nil, err
+	}
+	return r.raw, nil
+}
+
+// Raw returns the raw bytes of the last token returned by Token or RawToken,
+// or nil if there is none or they are not recorded.
+func (r *Replayer) Raw() []byte {
+	if r.err != nil {
+		return nil
+	}
+	return r.raw
+}
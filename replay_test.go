@@ -0,0 +1,78 @@
+package xmltokenizer_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/muktihari/xmltokenizer"
+)
+
+func TestReplayer(t *testing.T) {
+	const doc = `<?xml version="1.0"?><a x="1"><b>text</b><!-- c --><d/></a>`
+
+	var buf bytes.Buffer
+	if err := xmltokenizer.DumpBinary(&buf, strings.NewReader(doc)); err != nil {
+		t.Fatalf("expected nil, got: %v", err)
+	}
+	recorded := buf.Bytes()
+
+	t.Run("Token", func(t *testing.T) {
+		tok := xmltokenizer.New(strings.NewReader(doc))
+		rep := xmltokenizer.NewReplayer(bytes.NewReader(recorded))
+		for {
+			expected, err := tok.Token()
+			token, repErr := rep.Token()
+			if err != repErr {
+				t.Fatalf("expected error: %v, got: %v", err, repErr)
+			}
+			if err == io.EOF {
+				break
+			}
+			if diff := expected.Diff(token); diff != "" {
+				t.Fatal(diff)
+			}
+			if diff := cmp.Diff(string(tok.Raw()), string(rep.Raw())); diff != "" {
+				t.Fatal(diff)
+			}
+		}
+		if rep.Raw() != nil {
+			t.Fatalf("expected nil raw after io.EOF, got: %q", rep.Raw())
+		}
+	})
+
+	t.Run("RawToken", func(t *testing.T) {
+		rep := xmltokenizer.NewReplayer(bytes.NewReader(recorded))
+		var sb strings.Builder
+		for {
+			b, err := rep.RawToken()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("expected nil, got: %v", err)
+			}
+			sb.Write(b)
+		}
+		if diff := cmp.Diff(doc, sb.String()); diff != "" {
+			t.Fatal(diff)
+		}
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		rep := xmltokenizer.NewReplayer(bytes.NewReader(recorded[:len(recorded)-1]))
+		var err error
+		for err == nil {
+			_, err = rep.Token()
+		}
+		if !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Fatalf("expected error: %v, got: %v", io.ErrUnexpectedEOF, err)
+		}
+		if _, err2 := rep.RawToken(); err2 != err {
+			t.Fatalf("expected error: %v, got: %v", err, err2)
+		}
+	})
+}
@@ -0,0 +1,72 @@
+package xmltokenizer
+
+import (
+	"bytes"
+	"io"
+)
+
+// DocumentHead is what the head of an XML document tells about it, see Sniff.
+type DocumentHead struct {
+	Root       string            // Full name of the root element, empty if it is not found.
+	RootSpace  string            // Namespace URI of the root element, empty if it has none.
+	Namespaces map[string]string // Namespace URIs declared by the root element by prefix, "" for the default namespace.
+	Doctype    string            // Content of the DOCTYPE, e.g. "html", empty if there is none.
+	Encoding   string            // Lowercased encoding of the XML declaration, empty if it is not declared.
+}
+
+// Sniff reads at most limit bytes of the XML document from r, 1024 if limit
+// is not positive, and returns its DocumentHead, so routing services can
+// classify documents, e.g. GPX, TCX or KML, by their root element and
+// namespace before parsing them fully. UTF-16 documents are transcoded, other
+// encodings are read as they are. It returns the head found so far along
+// with the error if any, except io.EOF, e.g. io.ErrUnexpectedEOF when the
+// limit falls within a tag preceding the end of the root element's start tag.
+func Sniff(r io.Reader, limit int, opts ...Option) (*DocumentHead, error) {
+	if limit <= 0 {
+		limit = sniffLimit
+	}
+	h := &DocumentHead{Namespaces: make(map[string]string)}
+	rd, _, err := NewUTF8Reader(io.LimitReader(r, int64(limit)), func(_ string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	})
+	if err != nil {
+		return h, err
+	}
+
+	tok := New(rd, opts...)
+	for {
+		token, err := tok.Token()
+		if err == io.EOF {
+			return h, nil
+		}
+		if err != nil {
+			return h, err
+		}
+
+		switch {
+		case len(token.Name.Full) > 0:
+			h.setRoot(&token)
+			return h, nil
+		case bytes.HasPrefix(token.Data, []byte("<?xml")):
+			if end := len(token.Data) - len("?>"); end > len("<?xml") {
+				h.Encoding = string(bytes.ToLower(pseudoAttr(token.Data[len("<?xml"):end], "encoding")))
+			}
+		case bytes.HasPrefix(token.Data, []byte("<!DOCTYPE")):
+			h.Doctype = string(bytes.TrimSpace(token.Data[len("<!DOCTYPE") : len(token.Data)-len(">")]))
+		}
+	}
+}
+
+func (h *DocumentHead) setRoot(token *Token) {
+	h.Root = string(token.Name.Full)
+	for i := range token.Attrs {
+		attr := &token.Attrs[i]
+		switch {
+		case string(attr.Name.Full) == "xmlns":
+			h.Namespaces[""] = string(attr.Value)
+		case string(attr.Name.Prefix) == "xmlns":
+			h.Namespaces[string(attr.Name.Local)] = string(attr.Value)
+		}
+	}
+	h.RootSpace = h.Namespaces[string(token.Name.Prefix)]
+}
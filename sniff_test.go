@@ -0,0 +1,104 @@
+package xmltokenizer_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/muktihari/xmltokenizer"
+)
+
+func TestSniff(t *testing.T) {
+	utf16le := func(s string) string {
+		b := []byte{0xFF, 0xFE}
+		for _, u := range utf16.Encode([]rune(s)) {
+			b = append(b, byte(u), byte(u>>8))
+		}
+		return string(b)
+	}
+
+	tt := []struct {
+		name     string
+		xml      string
+		limit    int
+		expected *xmltokenizer.DocumentHead
+		err      error
+	}{
+		{
+			name: "gpx",
+			xml: `<?xml version="1.0" encoding="UTF-8"?>
+<!-- exported -->
+<gpx version="1.1" xmlns="http://www.topografix.com/GPX/1/1" xmlns:gpxtpx="http://www.garmin.com/xmlschemas/TrackPointExtension/v1">
+  <trk>` + strings.Repeat("<trkpt/>", 1000) + `</trk>
+</gpx>`,
+			expected: &xmltokenizer.DocumentHead{
+				Root:      "gpx",
+				RootSpace: "http://www.topografix.com/GPX/1/1",
+				Namespaces: map[string]string{
+					"":       "http://www.topografix.com/GPX/1/1",
+					"gpxtpx": "http://www.garmin.com/xmlschemas/TrackPointExtension/v1",
+				},
+				Encoding: "utf-8",
+			},
+		},
+		{
+			name:  "prefixed root and doctype",
+			xml:   `<!DOCTYPE kml SYSTEM "kml.dtd"><k:kml xmlns:k="http://www.opengis.net/kml/2.2"/>`,
+			limit: 4096,
+			expected: &xmltokenizer.DocumentHead{
+				Root:       "k:kml",
+				RootSpace:  "http://www.opengis.net/kml/2.2",
+				Namespaces: map[string]string{"k": "http://www.opengis.net/kml/2.2"},
+				Doctype:    `kml SYSTEM "kml.dtd"`,
+			},
+		},
+		{
+			name: "utf-16",
+			xml:  utf16le(`<?xml version="1.0" encoding="UTF-16"?><TrainingCenterDatabase xmlns="urn:tcx"/>`),
+			expected: &xmltokenizer.DocumentHead{
+				Root:       "TrainingCenterDatabase",
+				RootSpace:  "urn:tcx",
+				Namespaces: map[string]string{"": "urn:tcx"},
+				Encoding:   "utf-16",
+			},
+		},
+		{
+			name:     "no root",
+			xml:      `<?xml version="1.0"?><!-- empty -->`,
+			expected: &xmltokenizer.DocumentHead{Namespaces: map[string]string{}},
+		},
+		{
+			name:  "limit within root",
+			xml:   `<?xml version="1.0" encoding="ISO-8859-1"?><gpx xmlns="http://www.topografix.com/GPX/1/1">`,
+			limit: 50,
+			expected: &xmltokenizer.DocumentHead{
+				Namespaces: map[string]string{},
+				Encoding:   "iso-8859-1",
+			},
+			err: io.ErrUnexpectedEOF,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			r := strings.NewReader(tc.xml)
+			h, err := xmltokenizer.Sniff(r, tc.limit)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expected error: %v, got: %v", tc.err, err)
+			}
+			if diff := cmp.Diff(h, tc.expected); diff != "" {
+				t.Fatal(diff)
+			}
+			limit := tc.limit
+			if limit <= 0 {
+				limit = 1024
+			}
+			if read := len(tc.xml) - r.Len(); read > limit {
+				t.Fatalf("expected at most %d bytes read, got: %d", limit, read)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package xmltokenizer
+
+import "io"
+
+// TokenSource is a source of tokens, such as a Tokenizer, a Replayer or a
+// Subtree, so the code consuming tokens, e.g. the UnmarshalToken methods of a
+// schema, and the layers in between, such as filters, recorders and
+// validators, can be composed regardless of where the tokens come from.
+type TokenSource interface {
+	// Token returns either the next token or an error, io.EOF once there is
+	// none. The returned token is only valid before next Token invocation.
+	Token() (Token, error)
+}
+
+var (
+	_ TokenSource = (*Tokenizer)(nil)
+	_ TokenSource = (*Replayer)(nil)
+	_ TokenSource = (*Subtree)(nil)
+)
+
+// Subtree is a TokenSource of the tokens of a single element read from
+// another TokenSource: the descendants of the element up to and including its
+// end element, so the element can be handed to code reading until io.EOF.
+type Subtree struct {
+	src   TokenSource
+	depth int
+}
+
+// NewSubtree creates a Subtree of the element started by start, which must be
+// the last token returned by src. The Subtree is empty if start is not a start
+// element, or is self-closing. Once the Subtree returns io.EOF, the next token
+// of src is the one following the end element.
+func NewSubtree(src TokenSource, start *Token) *Subtree {
+	s := &Subtree{src: src}
+	if len(start.Name.Full) > 0 && !start.IsEndElement && !start.SelfClosing && !start.Continued {
+		s.depth = 1
+	}
+	return s
+}
+
+// Token returns the next token of the element, see TokenSource. An io.EOF
+// of src before the end element is returned as io.ErrUnexpectedEOF.
+func (s *Subtree) Token() (token Token, err error) {
+	if s.depth == 0 {
+		return token, io.EOF
+	}
+	if token, err = s.src.Token(); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return token, err
+	}
+	switch {
+	case len(token.Name.Full) == 0 || token.Continued || token.SelfClosing:
+	case token.IsEndElement:
+		s.depth--
+	default:
+		s.depth++
+	}
+	return token, nil
+}
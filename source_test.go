@@ -0,0 +1,96 @@
+package xmltokenizer_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/muktihari/xmltokenizer"
+)
+
+// names reads src until io.EOF and returns the full names of the tokens.
+func names(src xmltokenizer.TokenSource) ([]string, error) {
+	var names []string
+	for {
+		token, err := src.Token()
+		if err == io.EOF {
+			return names, nil
+		}
+		if err != nil {
+			return names, err
+		}
+		if token.IsEndElement {
+			names = append(names, "/"+string(token.Name.Full))
+		} else {
+			names = append(names, string(token.Name.Full))
+		}
+	}
+}
+
+func TestSubtree(t *testing.T) {
+	const doc = `<a><b><c/><b>x</b></b><d/></a>`
+
+	var buf bytes.Buffer
+	if err := xmltokenizer.DumpBinary(&buf, strings.NewReader(doc)); err != nil {
+		t.Fatalf("expected nil, got: %v", err)
+	}
+	recorded := buf.Bytes()
+
+	sources := map[string]func() xmltokenizer.TokenSource{
+		"Tokenizer": func() xmltokenizer.TokenSource { return xmltokenizer.New(strings.NewReader(doc)) },
+		"Replayer":  func() xmltokenizer.TokenSource { return xmltokenizer.NewReplayer(bytes.NewReader(recorded)) },
+	}
+
+	tt := []struct {
+		name     string
+		skip     int // tokens read before the start element
+		expected []string
+		next     string // name of the token following the subtree
+	}{
+		{name: "nested element", skip: 1, expected: []string{"c", "b", "/b", "/b"}, next: "d"},
+		{name: "self-closing element", skip: 2, expected: nil, next: "b"},
+		{name: "end element", skip: 4, expected: nil, next: "/b"},
+	}
+
+	for srcName, newSource := range sources {
+		for _, tc := range tt {
+			t.Run(srcName+"/"+tc.name, func(t *testing.T) {
+				src := newSource()
+				var start xmltokenizer.Token
+				for i := 0; i <= tc.skip; i++ {
+					token, err := src.Token()
+					if err != nil {
+						t.Fatalf("expected nil, got: %v", err)
+					}
+					start = token
+				}
+				got, err := names(xmltokenizer.NewSubtree(src, &start))
+				if err != nil {
+					t.Fatalf("expected nil, got: %v", err)
+				}
+				if diff := cmp.Diff(tc.expected, got); diff != "" {
+					t.Fatal(diff)
+				}
+				next, err := names(src)
+				if err != nil {
+					t.Fatalf("expected nil, got: %v", err)
+				}
+				if len(next) == 0 || next[0] != tc.next {
+					t.Fatalf("expected next: %q, got: %q", tc.next, next)
+				}
+			})
+		}
+	}
+
+	t.Run("unexpected EOF", func(t *testing.T) {
+		tok := xmltokenizer.New(strings.NewReader(`<a><b>`))
+		start, _ := tok.Token()
+		_, err := names(xmltokenizer.NewSubtree(tok, &start))
+		if !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Fatalf("expected error: %v, got: %v", io.ErrUnexpectedEOF, err)
+		}
+	})
+}
@@ -0,0 +1,86 @@
+package xmltokenizer
+
+import "io"
+
+// StanzaReader reads the top-level child elements of the root element of a
+// stream, called stanzas, such as the stanzas of an XMPP stream or the events
+// of a log, where the root element is only closed when the stream ends, if
+// ever. Unlike Token, it never reads beyond the ">" ending the root start
+// element or a stanza, so each one is returned as soon as it is complete,
+// regardless of idle periods of the stream.
+type StanzaReader struct {
+	tok     *Tokenizer
+	root    Token
+	started bool
+	err     error
+}
+
+// NewStanzaReader creates a StanzaReader reading the stream from r, the
+// options are passed to the underlying Tokenizer.
+func NewStanzaReader(r io.Reader, opts ...Option) *StanzaReader {
+	opts = append(opts[:len(opts):len(opts)], func(o *options) { o.framing = true })
+	return &StanzaReader{tok: New(r, opts...)}
+}
+
+// Root reads the stream up to the root start element, if it has not been
+// read yet, and returns it, e.g. to answer the stream header of an XMPP
+// stream before any stanza is sent. The prolog is skipped. The returned
+// token owns its memory and never has Data.
+func (s *StanzaReader) Root() (Token, error) {
+	for !s.started && s.err == nil {
+		token, err := s.tok.Token()
+		if err != nil {
+			s.err = err
+			break
+		}
+		if len(token.Name.Full) == 0 {
+			continue
+		}
+		if token.IsEndElement {
+			s.err = &SyntaxError{Pos: token.Begin, Err: errUnexpectedEndElement}
+			break
+		}
+		s.root, s.started = token.DeepCopy(), true
+		if token.SelfClosing {
+			s.err = io.EOF
+		}
+	}
+	if !s.started {
+		return Token{}, s.err
+	}
+	return s.root, nil
+}
+
+// Next returns the byte-exact form of the next stanza, from its "<" until its
+// ">" including anything in between. Anything in between the stanzas, such as
+// whitespace keep-alives, CharData and comments, is skipped. It returns io.EOF
+// once the root element is closed or the stream ends between stanzas. The
+// returned bytes are only valid before the next Next invocation.
+func (s *StanzaReader) Next() ([]byte, error) {
+	if _, err := s.Root(); err != nil {
+		return nil, err
+	}
+	for s.err == nil {
+		token, err := s.tok.Token()
+		if err != nil {
+			s.err = err
+			break
+		}
+		switch {
+		case len(token.Name.Full) == 0:
+		case token.IsEndElement:
+			s.err = io.EOF // the root element is closed
+		default:
+			raw, err := s.tok.Subtree(&token)
+			if err != nil {
+				s.err = err
+				break
+			}
+			return raw, nil
+		}
+	}
+	return nil, s.err
+}
+
+// Close closes the underlying Tokenizer, see Tokenizer.Close.
+func (s *StanzaReader) Close() error { return s.tok.Close() }
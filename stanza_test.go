@@ -0,0 +1,93 @@
+package xmltokenizer_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/muktihari/xmltokenizer"
+)
+
+func TestStanzaReader(t *testing.T) {
+	pr, pw := io.Pipe()
+	defer pr.Close()
+	// write writes the parts to the stream in the background, each step
+	// must complete without reading the parts written by the next one.
+	write := func(parts ...string) {
+		go func() {
+			for _, part := range parts {
+				pw.Write([]byte(part))
+			}
+		}()
+	}
+
+	s := xmltokenizer.NewStanzaReader(pr)
+
+	write(`<?xml version='1.0'?>` + "\n" + `<stream:stream xmlns="jabber:client" to="example.com">`)
+	root, err := s.Root()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root.String() != `<stream:stream xmlns="jabber:client" to="example.com" @2:1>` {
+		t.Fatalf("unexpected root: %s", root)
+	}
+
+	for _, stanza := range []string{
+		`<message to="a"><body>hi &amp; <b>bye</b> </body><x/></message>`,
+		`<presence/>`,
+		`<iq><![CDATA[</iq>]]></iq>`,
+	} {
+		parts := []string{" \n"}
+		for i := 0; i < len(stanza); i += 5 {
+			parts = append(parts, stanza[i:min(i+5, len(stanza))])
+		}
+		write(parts...)
+		raw, err := s.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(raw) != stanza {
+			t.Fatalf("expected: %q, got: %q", stanza, raw)
+		}
+	}
+
+	write("keep-alive </stream:stream>")
+	if _, err = s.Next(); err != io.EOF {
+		t.Fatalf("expected error: %v, got: %v", io.EOF, err)
+	}
+	if _, err = s.Next(); err != io.EOF {
+		t.Fatalf("expected error: %v, got: %v", io.EOF, err)
+	}
+}
+
+func TestStanzaReaderError(t *testing.T) {
+	tt := []struct {
+		name string
+		xml  string
+		err  error
+	}{
+		{name: "empty", xml: ``, err: io.EOF},
+		{name: "self-closing root", xml: `<stream/>`, err: io.EOF},
+		{name: "unclosed stream", xml: `<stream><a/>`, err: io.EOF},
+		{name: "truncated stanza", xml: `<stream><a><b/>`, err: io.ErrUnexpectedEOF},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			s := xmltokenizer.NewStanzaReader(strings.NewReader(tc.xml))
+			var err error
+			for err == nil {
+				_, err = s.Next()
+			}
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expected error: %v, got: %v", tc.err, err)
+			}
+		})
+	}
+
+	s := xmltokenizer.NewStanzaReader(strings.NewReader(`</stream>`))
+	if _, err := s.Root(); err == nil || err == io.EOF {
+		t.Fatalf("expected syntax error, got: %v", err)
+	}
+}
@@ -0,0 +1,71 @@
+package xmltokenizer
+
+import (
+	"bytes"
+	"encoding/xml"
+)
+
+// AppendXMLTokens appends the encoding/xml equivalents of token to dst, so
+// tokens read by the Tokenizer can be written using an xml.Encoder:
+//   - a start element becomes an xml.StartElement, followed by an xml.EndElement
+//     if it is self-closing and an xml.CharData if it has Data,
+//   - an end element becomes an xml.EndElement, followed by an xml.CharData if
+//     it has Data,
+//   - a continuation becomes an xml.CharData,
+//   - "<?", "<!--" and "<!" tags become xml.ProcInst, xml.Comment and xml.Directive.
+//
+// Names are passed as their Full name in xml.Name.Local, so the prefixes and
+// the xmlns declarations are written as they are read. Since the Encoder escapes
+// CharData and attribute values, their entities are decoded; this includes the
+// content of CDATA, which can not be told apart from CharData once tokenized.
+// The returned tokens own their memory.
+func AppendXMLTokens(dst []xml.Token, token Token) []xml.Token {
+	switch {
+	case token.Continued:
+		return append(dst, xml.CharData(appendUnescaped(nil, token.Data)))
+	case token.IsEndElement:
+		dst = append(dst, xml.EndElement{Name: xml.Name{Local: string(token.Name.Full)}})
+		if len(token.Data) > 0 {
+			dst = append(dst, xml.CharData(appendUnescaped(nil, token.Data)))
+		}
+		return dst
+	case len(token.Name.Full) == 0:
+		return append(dst, markupXMLToken(token.Data))
+	}
+
+	se := xml.StartElement{Name: xml.Name{Local: string(token.Name.Full)}}
+	if len(token.Attrs) > 0 {
+		se.Attr = make([]xml.Attr, len(token.Attrs))
+		for i := range token.Attrs {
+			se.Attr[i] = xml.Attr{
+				Name:  xml.Name{Local: string(token.Attrs[i].Name.Full)},
+				Value: string(appendUnescaped(nil, token.Attrs[i].Value)),
+			}
+		}
+	}
+	dst = append(dst, se)
+	if token.SelfClosing {
+		dst = append(dst, se.End())
+	}
+	if len(token.Data) > 0 {
+		dst = append(dst, xml.CharData(appendUnescaped(nil, token.Data)))
+	}
+	return dst
+}
+
+// markupXMLToken converts a "<?", "<!--" or "<!" tag into its encoding/xml equivalent.
+func markupXMLToken(b []byte) xml.Token {
+	switch {
+	case bytes.HasPrefix(b, []byte("<?")):
+		b = bytes.TrimSuffix(b[2:], []byte("?>"))
+		target, inst := b, []byte(nil)
+		if i := bytes.IndexAny(b, " \t\r\n"); i != -1 {
+			target, inst = b[:i], TrimLeftSpace(b[i:])
+		}
+		return xml.ProcInst{Target: string(target), Inst: bytes.Clone(inst)}
+	case bytes.HasPrefix(b, []byte("<!--")):
+		return xml.Comment(bytes.Clone(bytes.TrimSuffix(b[4:], []byte("-->"))))
+	default:
+		return xml.Directive(bytes.Clone(bytes.TrimSuffix(bytes.TrimPrefix(b, []byte("<!")), []byte(">"))))
+	}
+}
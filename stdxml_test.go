@@ -0,0 +1,76 @@
+package xmltokenizer_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/muktihari/xmltokenizer"
+)
+
+func TestAppendXMLTokens(t *testing.T) {
+	tt := []struct {
+		name     string
+		doc      string
+		expected string
+	}{
+		{
+			name: "document",
+			doc: `<?xml version="1.0" encoding="UTF-8"?>` +
+				`<!DOCTYPE note>` +
+				`<gpx:trk xmlns:gpx="urn:gpx" name="a &amp; b &#x767d;">` +
+				`<!-- comment -->` +
+				`<desc>1 &lt; 2 &#40300; &unknown;</desc>` +
+				`<pt lat="1"/>` +
+				`</gpx:trk>`,
+			expected: `<?xml version="1.0" encoding="UTF-8"?>` +
+				`<!DOCTYPE note>` +
+				`<gpx:trk xmlns:gpx="urn:gpx" name="a &amp; b 白">` +
+				`<!-- comment -->` +
+				`<desc>1 &lt; 2 鵬 &amp;unknown;</desc>` +
+				`<pt lat="1"></pt>` +
+				`</gpx:trk>`,
+		},
+		{
+			name:     "mixed content",
+			doc:      `<p>Name: <b>x</b> is the id <br/> &amp; more</p>`,
+			expected: `<p>Name:<b>x</b>is the id<br></br>&amp; more</p>`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := appendXMLTokens(t, tc.doc); got != tc.expected {
+				t.Fatalf("expected:\n%s\ngot:\n%s", tc.expected, got)
+			}
+		})
+	}
+}
+
+// appendXMLTokens encodes the tokens of doc converted by AppendXMLTokens.
+func appendXMLTokens(t *testing.T, doc string) string {
+	tok := xmltokenizer.New(strings.NewReader(doc))
+	var buf bytes.Buffer
+	enc := xml.NewEncoder(&buf)
+	var tokens []xml.Token
+	for {
+		token, err := tok.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, xt := range xmltokenizer.AppendXMLTokens(tokens[:0], token) {
+			if err := enc.EncodeToken(xt); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := enc.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
@@ -0,0 +1,111 @@
+package xmltokenizer
+
+import (
+	"fmt"
+	"strings"
+)
+
+// String returns the Full name.
+func (n Name) String() string { return string(n.Full) }
+
+// GoString returns n as a Go expression, used by the %#v verb.
+func (n Name) GoString() string {
+	if n.Space != nil {
+		return fmt.Sprintf("xmltokenizer.Name{Prefix: []byte(%q), Local: []byte(%q), Full: []byte(%q), Space: []byte(%q)}",
+			n.Prefix, n.Local, n.Full, n.Space)
+	}
+	return fmt.Sprintf("xmltokenizer.Name{Prefix: []byte(%q), Local: []byte(%q), Full: []byte(%q)}",
+		n.Prefix, n.Local, n.Full)
+}
+
+// String returns the attribute in the form name="value".
+func (a Attr) String() string { return fmt.Sprintf("%s=%q", a.Name.Full, a.Value) }
+
+// GoString returns a as a Go expression, used by the %#v verb.
+func (a Attr) GoString() string {
+	if a.Index != 0 {
+		return fmt.Sprintf("xmltokenizer.Attr{Name: %#v, Value: []byte(%q), Index: %d}", a.Name, a.Value, a.Index)
+	}
+	return fmt.Sprintf("xmltokenizer.Attr{Name: %#v, Value: []byte(%q)}", a.Name, a.Value)
+}
+
+// String returns the position in the form "line 7, col 63", for error reports.
+func (p Pos) String() string { return fmt.Sprintf("line %d, col %d", p.Line, p.Column) }
+
+// GoString returns p as a Go expression, used by the %#v verb.
+func (p Pos) GoString() string {
+	return fmt.Sprintf("xmltokenizer.Pos{Line: %d, Column: %d, Offset: %d}", p.Line, p.Column, p.Offset)
+}
+
+// String returns the range in the form "line 7, col 63 to line 8, col 1".
+func (r Range) String() string { return r.Begin.String() + " to " + r.End.String() }
+
+// String returns a compact representation of t for logs and test failures,
+// with the line and column of its Begin position after "@", e.g.
+//
+//	<trkpt lat="47.1" @3:5>
+//	<ele @4:7>"100"
+//	<br @5:1/>
+//	</trkpt @6:5>
+//	<!-- comment --> @7:1
+//
+// Data is quoted and truncated. The format is not guaranteed to be stable.
+func (t Token) String() string {
+	var b strings.Builder
+	switch {
+	case t.Continued:
+		fmt.Fprintf(&b, "%q @%d:%d", shorten(t.Data), t.Begin.Line, t.Begin.Column)
+		return b.String()
+	case len(t.Name.Full) == 0:
+		fmt.Fprintf(&b, "%s @%d:%d", shorten(t.Data), t.Begin.Line, t.Begin.Column)
+		return b.String()
+	case t.IsEndElement:
+		fmt.Fprintf(&b, "</%s @%d:%d>", t.Name.Full, t.Begin.Line, t.Begin.Column)
+		return b.String()
+	}
+	b.WriteByte('<')
+	b.Write(t.Name.Full)
+	for i := range t.Attrs {
+		b.WriteByte(' ')
+		b.WriteString(t.Attrs[i].String())
+	}
+	fmt.Fprintf(&b, " @%d:%d", t.Begin.Line, t.Begin.Column)
+	if t.SelfClosing {
+		b.WriteByte('/')
+	}
+	b.WriteByte('>')
+	if len(t.Data) > 0 {
+		fmt.Fprintf(&b, "%q", shorten(t.Data))
+	}
+	return b.String()
+}
+
+// GoString returns t as a Go expression, used by the %#v verb. Empty fields
+// are omitted.
+func (t Token) GoString() string {
+	var fields []string
+	if len(t.Name.Full) > 0 {
+		fields = append(fields, fmt.Sprintf("Name: %#v", t.Name))
+	}
+	if len(t.Attrs) > 0 {
+		attrs := make([]string, len(t.Attrs))
+		for i := range t.Attrs {
+			attrs[i] = t.Attrs[i].GoString()
+		}
+		fields = append(fields, fmt.Sprintf("Attrs: []xmltokenizer.Attr{%s}", strings.Join(attrs, ", ")))
+	}
+	if len(t.Data) > 0 {
+		fields = append(fields, fmt.Sprintf("Data: []byte(%q)", t.Data))
+	}
+	if t.SelfClosing {
+		fields = append(fields, "SelfClosing: true")
+	}
+	if t.IsEndElement {
+		fields = append(fields, "IsEndElement: true")
+	}
+	if t.Continued {
+		fields = append(fields, "Continued: true")
+	}
+	fields = append(fields, fmt.Sprintf("Begin: %#v", t.Begin), fmt.Sprintf("End: %#v", t.End))
+	return "xmltokenizer.Token{" + strings.Join(fields, ", ") + "}"
+}
@@ -0,0 +1,78 @@
+package xmltokenizer_test
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/muktihari/xmltokenizer"
+)
+
+func TestTokenString(t *testing.T) {
+	const s = `<?xml version="1.0"?>
+<trkpt lat="47.1" lon="8.5">
+  <ele>100</ele><br/>
+  <!-- comment -->
+</trkpt>`
+	tok := xmltokenizer.New(strings.NewReader(s))
+	var result []string
+	for {
+		token, err := tok.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		result = append(result, fmt.Sprint(token))
+	}
+
+	expected := []string{
+		`<?xml version="1.0"?> @1:1`,
+		`<trkpt lat="47.1" lon="8.5" @2:1>`,
+		`<ele @3:3>"100"`,
+		`</ele @3:11>`,
+		`<br @3:17/>`,
+		`<!-- comment --> @4:3`,
+		`</trkpt @5:1>`,
+	}
+	if diff := cmp.Diff(result, expected); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestTokenGoString(t *testing.T) {
+	token := xmltokenizer.Token{
+		Name: xmltokenizer.Name{Local: []byte("br"), Full: []byte("br")},
+		Attrs: []xmltokenizer.Attr{
+			{Name: xmltokenizer.Name{Local: []byte("id"), Full: []byte("id")}, Value: []byte("1")},
+		},
+		SelfClosing: true,
+		Begin:       xmltokenizer.Pos{Line: 1, Column: 1},
+		End:         xmltokenizer.Pos{Line: 1, Column: 14, Offset: 13},
+	}
+	expected := `xmltokenizer.Token{` +
+		`Name: xmltokenizer.Name{Prefix: []byte(""), Local: []byte("br"), Full: []byte("br")}, ` +
+		`Attrs: []xmltokenizer.Attr{xmltokenizer.Attr{Name: xmltokenizer.Name{Prefix: []byte(""), Local: []byte("id"), Full: []byte("id")}, Value: []byte("1")}}, ` +
+		`SelfClosing: true, ` +
+		`Begin: xmltokenizer.Pos{Line: 1, Column: 1, Offset: 0}, ` +
+		`End: xmltokenizer.Pos{Line: 1, Column: 14, Offset: 13}}`
+	if diff := cmp.Diff(fmt.Sprintf("%#v", token), expected); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestPosAndRangeString(t *testing.T) {
+	r := xmltokenizer.Range{
+		Begin: xmltokenizer.Pos{Line: 7, Column: 63, Offset: 200},
+		End:   xmltokenizer.Pos{Line: 8, Column: 1, Offset: 220},
+	}
+	if s := r.Begin.String(); s != "line 7, col 63" {
+		t.Fatalf("expected: %q, got: %q", "line 7, col 63", s)
+	}
+	if s := r.String(); s != "line 7, col 63 to line 8, col 1" {
+		t.Fatalf("expected: %q, got: %q", "line 7, col 63 to line 8, col 1", s)
+	}
+}
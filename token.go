@@ -2,18 +2,74 @@ package xmltokenizer
 
 import (
 	"bytes"
+	"cmp"
+	"fmt"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"unicode/utf8"
+	"unsafe"
 )
 
-var pool = sync.Pool{New: func() any { return new(Token) }}
+var pool TokenPool
 
 // GetToken gets token from the pool, don't forget to put it back.
-func GetToken() *Token { return pool.Get().(*Token) }
+func GetToken() *Token { return pool.Get() }
 
 // PutToken puts token back to the pool.
 func PutToken(t *Token) { pool.Put(t) }
 
+// SetTokenPoolMaxRetained bounds the memory retained by the package-level pool
+// used by GetToken and PutToken, see TokenPool.SetMaxRetained.
+func SetTokenPoolMaxRetained(n int) { pool.SetMaxRetained(n) }
+
+// TokenPool is a pool of Tokens, it can be used instead of GetToken and
+// PutToken to avoid sharing the retained memory, e.g. across tenants.
+// The zero value is ready to use and retains tokens of any size.
+type TokenPool struct {
+	pool        sync.Pool
+	maxRetained atomic.Int64
+}
+
+// NewTokenPool creates a TokenPool, see SetMaxRetained for maxRetained.
+func NewTokenPool(maxRetained int) *TokenPool {
+	p := new(TokenPool)
+	p.SetMaxRetained(maxRetained)
+	return p
+}
+
+// SetMaxRetained sets the maximum number of bytes a token may hold, counting
+// the capacity of its Name, Data and Attrs, to be retained by Put. Zero means
+// no limit and a negative number disables the pool: Put drops every token.
+// It is safe to call concurrently with Get and Put.
+func (p *TokenPool) SetMaxRetained(n int) { p.maxRetained.Store(int64(n)) }
+
+// Get gets token from the pool, don't forget to put it back.
+func (p *TokenPool) Get() *Token {
+	if t, ok := p.pool.Get().(*Token); ok {
+		return t
+	}
+	return new(Token)
+}
+
+// Put puts token back to the pool, unless it holds more memory than allowed.
+// The references of its Attrs are cleared so the pool does not retain the
+// memory they refer to.
+func (p *TokenPool) Put(t *Token) {
+	max := p.maxRetained.Load()
+	if max < 0 || (max > 0 && int64(t.retainedSize()) > max) {
+		return
+	}
+	clear(t.Attrs[:cap(t.Attrs)])
+	p.pool.Put(t)
+}
+
+// retainedSize returns the number of bytes held by t's own slices.
+func (t *Token) retainedSize() int {
+	return cap(t.Name.Prefix) + cap(t.Name.Local) + cap(t.Name.Full) +
+		cap(t.Data) + cap(t.Attrs)*int(unsafe.Sizeof(Attr{}))
+}
+
 // Token represent a single token, one of these following:
 //   - <?xml version="1.0" encoding="UTF-8"?>
 //   - <name attr="value" attr="value">
@@ -30,10 +86,11 @@ func PutToken(t *Token) { pool.Put(t) }
 // Token includes CharData or CDATA in Data field when it appears right after the start element.
 type Token struct {
 	Name         Name   // Name is an XML name, empty when a tag starts with "<?" or "<!".
-	Attrs        []Attr // Attrs exist when len(Attrs) > 0.
+	Attrs        []Attr // Attrs exist when len(Attrs) > 0, in document order.
 	Data         []byte // Data could be a CharData or a CDATA, or maybe a RawToken if a tag starts with "<?" or "<!" (except "<![CDATA").
 	SelfClosing  bool   // True when a tag ends with "/>" e.g. <c r="E3" s="1" />. Also true when a tag starts with "<?" or "<!" (except "<![CDATA").
 	IsEndElement bool   // True when a tag start with "</" e.g. </gpx> or </gpxtpx:atemp>.
+	Continued    bool   // True when Data is a continuation of previous token's Data, see WithChunkedCharData.
 	Begin, End   Pos    // Begin and end of this token within the stream.
 }
 
@@ -43,6 +100,45 @@ type Pos struct {
 	Offset int // Byte offset (from 0)
 }
 
+// Compare returns -1 if p is before other, 1 if p is after other, or 0 if
+// they are the same position. Positions are ordered by Line, then Column,
+// then Offset.
+func (p Pos) Compare(other Pos) int {
+	switch {
+	case p.Line != other.Line:
+		return cmp.Compare(p.Line, other.Line)
+	case p.Column != other.Column:
+		return cmp.Compare(p.Column, other.Column)
+	}
+	return cmp.Compare(p.Offset, other.Offset)
+}
+
+// IsZero reports whether p is the zero Pos, which is never the position of
+// a token since lines and columns start from 1.
+func (p Pos) IsZero() bool { return p == Pos{} }
+
+// Range is the range of positions from Begin up to End, exclusive, such as
+// the Begin and End of a token.
+type Range struct {
+	Begin, End Pos
+}
+
+// IsZero reports whether r is the zero Range.
+func (r Range) IsZero() bool { return r.Begin.IsZero() && r.End.IsZero() }
+
+// Contains reports whether p is within r.
+func (r Range) Contains(p Pos) bool {
+	return r.Begin.Compare(p) <= 0 && p.Compare(r.End) < 0
+}
+
+// Overlaps reports whether r and other share at least one position.
+func (r Range) Overlaps(other Range) bool {
+	return r.Begin.Compare(other.End) < 0 && other.Begin.Compare(r.End) < 0
+}
+
+// Range returns the range of t, from Begin to End.
+func (t *Token) Range() Range { return Range{Begin: t.Begin, End: t.End} }
+
 func (p *Pos) step(b []byte) {
 	p.Offset += len(b)
 	if nl := bytes.LastIndexByte(b, '\n'); nl == -1 {
@@ -63,29 +159,172 @@ func (t *Token) IsEndElementOf(se *Token) bool {
 	return false
 }
 
-// Copy copies src Token into t, returning t. Attrs should be
-// consumed immediately since it's only being shallow copied.
+// Copy copies src Token into t, returning t, reusing the memory of t:
+//   - Name and Data are copied into t's own slices.
+//   - Attrs are shallow copied: t gets its own slice of Attr, but the Name and
+//     Value of each Attr still refer to the memory of src, which is the
+//     Tokenizer's internal buffer for a token returned by Token.
+//   - SelfClosing, IsEndElement and Continued are copied.
+//   - Begin and End are copied too, so t reports the position of src rather
+//     than the one of the token it previously held.
+//
+// Hence Attrs should be consumed before the next invocation of Token,
+// use DeepCopy or CopyInto to get a token that aliases nothing.
 func (t *Token) Copy(src Token) *Token {
 	t.Name.Prefix = append(t.Name.Prefix[:0], src.Name.Prefix...)
 	t.Name.Local = append(t.Name.Local[:0], src.Name.Local...)
 	t.Name.Full = append(t.Name.Full[:0], src.Name.Full...)
+	t.Name.Space = append(t.Name.Space[:0], src.Name.Space...)
 	t.Attrs = append(t.Attrs[:0], src.Attrs...) // shallow copy
 	t.Data = append(t.Data[:0], src.Data...)
 	t.SelfClosing = src.SelfClosing
 	t.IsEndElement = src.IsEndElement
+	t.Continued = src.Continued
+	t.Begin = src.Begin
+	t.End = src.End
 	return t
 }
 
+// DeepCopy returns a copy of t that aliases nothing: Name, Data, Attrs and
+// each Attr's Name and Value refer to newly allocated memory, so it remains
+// valid after the next invocation of Token and may be handed to another
+// goroutine. The bytes are allocated at once, see CopyInto to amortize the
+// allocations of many tokens.
+func (t *Token) DeepCopy() Token {
+	a := Arena{blockSize: 1} // allocate exactly what is needed
+	return a.copyToken(*t)
+}
+
+// CopyInto returns a deep copy of t, including its Attrs, whose memory
+// is drawn from the given Arena.
+func (t *Token) CopyInto(a *Arena) Token {
+	return a.copyToken(*t)
+}
+
+// Equal reports whether t and other have the same content: Name, Attrs
+// regardless of their order, Data and flags. Names are compared by their Full
+// name and their namespace URI. Positions are not compared.
+func (t *Token) Equal(other Token) bool {
+	if string(t.Name.Full) != string(other.Name.Full) ||
+		string(t.Name.Space) != string(other.Name.Space) ||
+		string(t.Data) != string(other.Data) ||
+		t.SelfClosing != other.SelfClosing ||
+		t.IsEndElement != other.IsEndElement ||
+		t.Continued != other.Continued ||
+		len(t.Attrs) != len(other.Attrs) {
+		return false
+	}
+	for i := range t.Attrs {
+		attr := other.attr(t.Attrs[i].Name.Full)
+		if attr == nil || string(attr.Value) != string(t.Attrs[i].Value) ||
+			string(attr.Name.Space) != string(t.Attrs[i].Name.Space) {
+			return false
+		}
+	}
+	return true
+}
+
+// Diff returns a compact description of how other differs from t, e.g.
+// "name=trkpt lat attr differs: 47.1 vs 47.2 @ line 10", or an empty string
+// if they are Equal. It is intended for test failure messages.
+func (t *Token) Diff(other Token) string {
+	var diffs []string
+	if string(t.Name.Full) != string(other.Name.Full) {
+		diffs = append(diffs, fmt.Sprintf("name differs: %s vs %s", t.Name.Full, other.Name.Full))
+	}
+	if string(t.Name.Space) != string(other.Name.Space) {
+		diffs = append(diffs, fmt.Sprintf("namespace differs: %s vs %s", t.Name.Space, other.Name.Space))
+	}
+	for i := range t.Attrs {
+		a := &t.Attrs[i]
+		switch b := other.attr(a.Name.Full); {
+		case b == nil:
+			diffs = append(diffs, fmt.Sprintf("%s attr missing in other", a.Name.Full))
+		case string(a.Value) != string(b.Value):
+			diffs = append(diffs, fmt.Sprintf("%s attr differs: %s vs %s", a.Name.Full, a.Value, b.Value))
+		case string(a.Name.Space) != string(b.Name.Space):
+			diffs = append(diffs, fmt.Sprintf("%s attr namespace differs: %s vs %s", a.Name.Full, a.Name.Space, b.Name.Space))
+		}
+	}
+	for i := range other.Attrs {
+		if t.attr(other.Attrs[i].Name.Full) == nil {
+			diffs = append(diffs, fmt.Sprintf("%s attr only in other", other.Attrs[i].Name.Full))
+		}
+	}
+	if string(t.Data) != string(other.Data) {
+		diffs = append(diffs, fmt.Sprintf("data differs: %q vs %q", shorten(t.Data), shorten(other.Data)))
+	}
+	if t.SelfClosing != other.SelfClosing {
+		diffs = append(diffs, fmt.Sprintf("self-closing differs: %t vs %t", t.SelfClosing, other.SelfClosing))
+	}
+	if t.IsEndElement != other.IsEndElement {
+		diffs = append(diffs, fmt.Sprintf("end element differs: %t vs %t", t.IsEndElement, other.IsEndElement))
+	}
+	if t.Continued != other.Continued {
+		diffs = append(diffs, fmt.Sprintf("continued differs: %t vs %t", t.Continued, other.Continued))
+	}
+	if len(diffs) == 0 && len(t.Attrs) != len(other.Attrs) {
+		diffs = append(diffs, fmt.Sprintf("attrs length differs: %d vs %d", len(t.Attrs), len(other.Attrs)))
+	}
+	if len(diffs) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("name=%s %s @ line %d", t.Name.Full, strings.Join(diffs, ", "), t.Begin.Line)
+}
+
+// AttrAt returns the attribute whose Index is i, which is Attrs[i] as
+// returned by Token since Attrs are in document order, and whether it is
+// found. It still finds the attribute once Attrs are filtered or sorted,
+// e.g. by the transform package.
+func (t *Token) AttrAt(i int) (attr Attr, ok bool) {
+	if i >= 0 && i < len(t.Attrs) && t.Attrs[i].Index == i {
+		return t.Attrs[i], true
+	}
+	for j := range t.Attrs {
+		if t.Attrs[j].Index == i {
+			return t.Attrs[j], true
+		}
+	}
+	return Attr{}, false
+}
+
+// attr returns the first attribute with the given full name, or nil if none.
+func (t *Token) attr(full []byte) *Attr {
+	for i := range t.Attrs {
+		if string(t.Attrs[i].Name.Full) == string(full) {
+			return &t.Attrs[i]
+		}
+	}
+	return nil
+}
+
+// shorten truncates b to a length suitable for a message.
+func shorten(b []byte) string {
+	const max = 32
+	if len(b) <= max {
+		return string(b)
+	}
+	n := max
+	for n > 0 && !utf8.RuneStart(b[n]) {
+		n--
+	}
+	return string(b[:n]) + "..."
+}
+
 // Attr represents an XML attribute.
 type Attr struct {
 	Name  Name
 	Value []byte
+	// Index among the attributes of its element in document order, it is -1
+	// for an attribute added afterwards, e.g. by the transform package.
+	Index int
 }
 
-// Name represents an XML name <prefix:local>,
-// we don't manage the bookkeeping of namespaces.
+// Name represents an XML name <prefix:local>. The namespace bookkeeping is
+// left to the caller, unless WithNamespaceResolution is used to fill Space.
 type Name struct {
 	Prefix []byte
 	Local  []byte
 	Full   []byte // Full is combination of "prefix:local"
+	Space  []byte // Space is the namespace URI, see WithNamespaceResolution.
 }
@@ -1,6 +1,7 @@
 package xmltokenizer_test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -17,6 +18,41 @@ func TestGetToken(t *testing.T) {
 	}
 }
 
+func TestTokenPool(t *testing.T) {
+	large := func() *xmltokenizer.Token {
+		return &xmltokenizer.Token{Data: make([]byte, 0, 1024)}
+	}
+
+	tt := []struct {
+		name        string
+		maxRetained int
+		token       *xmltokenizer.Token
+	}{
+		{name: "disabled", maxRetained: -1, token: new(xmltokenizer.Token)},
+		{name: "exceeds max retained", maxRetained: 512, token: large()},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			p := xmltokenizer.NewTokenPool(tc.maxRetained)
+			p.Put(tc.token)
+			if got := p.Get(); got == tc.token {
+				t.Fatalf("expected token to be dropped")
+			}
+		})
+	}
+
+	t.Run("attrs references are cleared", func(t *testing.T) {
+		var p xmltokenizer.TokenPool
+		token := p.Get()
+		token.Attrs = append(token.Attrs, xmltokenizer.Attr{Value: []byte("large buffer")})
+		p.Put(token)
+		if token.Attrs[0].Value != nil {
+			t.Fatalf("expected attrs to be cleared, got: %v", token.Attrs[0])
+		}
+	})
+}
+
 func TestIsEndElement(t *testing.T) {
 	tt := []struct {
 		name     string
@@ -159,3 +195,267 @@ func TestCopy(t *testing.T) {
 		t.Fatal(diff)
 	}
 }
+
+func TestDeepCopy(t *testing.T) {
+	tok := xmltokenizer.New(strings.NewReader(`<gpxtpx:hr units="bpm">70</gpxtpx:hr><x a="overwrite">overwritten</x>`))
+	token, err := tok.Token()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t1 := token.DeepCopy()
+	if !t1.Equal(token) || t1.Begin != token.Begin || t1.End != token.End {
+		t.Fatalf("expected equal, got: %s", t1.Diff(token))
+	}
+	for i := 0; i < 3; i++ {
+		if _, err = tok.Token(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := xmltokenizer.Token{
+		Name: xmltokenizer.Name{Prefix: []byte("gpxtpx"), Local: []byte("hr"), Full: []byte("gpxtpx:hr")},
+		Attrs: []xmltokenizer.Attr{{
+			Name:  xmltokenizer.Name{Local: []byte("units"), Full: []byte("units")},
+			Value: []byte("bpm"),
+		}},
+		Data:  []byte("70"),
+		Begin: t1.Begin,
+		End:   t1.End,
+	}
+	if diff := cmp.Diff(t1, expected); diff != "" {
+		t.Fatal(diff)
+	}
+
+	// Appending to a copied slice must not overwrite its neighbours.
+	t2 := t1.DeepCopy()
+	t2.Name.Local = append(t2.Name.Local, 'x')
+	t2.Attrs[0].Value = append(t2.Attrs[0].Value, 'x')
+	t2.Attrs = append(t2.Attrs, xmltokenizer.Attr{})
+	t2.Attrs[0].Name.Full[0] = 'U'
+	if diff := cmp.Diff(t1, expected); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestDeepCopyHandBuilt(t *testing.T) {
+	token := xmltokenizer.Token{
+		Name: xmltokenizer.Name{Prefix: []byte("p"), Local: []byte("other"), Full: []byte("p:a"), Space: []byte("urn:p")},
+		Attrs: []xmltokenizer.Attr{{
+			Name:  xmltokenizer.Name{Prefix: []byte("q"), Local: []byte("b")}, // without Full
+			Value: []byte("v"),
+		}},
+	}
+	expected := xmltokenizer.Token{
+		Name: xmltokenizer.Name{Prefix: []byte("p"), Local: []byte("other"), Full: []byte("p:a"), Space: []byte("urn:p")},
+		Attrs: []xmltokenizer.Attr{{
+			Name:  xmltokenizer.Name{Prefix: []byte("q"), Local: []byte("b")},
+			Value: []byte("v"),
+		}},
+	}
+
+	copied := token.DeepCopy()
+	if diff := cmp.Diff(copied, expected); diff != "" {
+		t.Fatal(diff)
+	}
+	for _, b := range [][]byte{token.Name.Prefix, token.Name.Local, token.Name.Full, token.Name.Space,
+		token.Attrs[0].Name.Prefix, token.Attrs[0].Name.Local, token.Attrs[0].Value} {
+		b[0] = '!'
+	}
+	if diff := cmp.Diff(copied, expected); diff != "" {
+		t.Fatalf("expected the copy to alias nothing: %s", diff)
+	}
+}
+
+func TestEqualAndDiff(t *testing.T) {
+	trkpt := func(lat string, attrs ...xmltokenizer.Attr) xmltokenizer.Token {
+		return xmltokenizer.Token{
+			Name: xmltokenizer.Name{Local: []byte("trkpt"), Full: []byte("trkpt")},
+			Attrs: append([]xmltokenizer.Attr{
+				{Name: xmltokenizer.Name{Local: []byte("lat"), Full: []byte("lat")}, Value: []byte(lat)},
+			}, attrs...),
+			Begin: xmltokenizer.Pos{Line: 10, Column: 1},
+		}
+	}
+	lon := xmltokenizer.Attr{Name: xmltokenizer.Name{Local: []byte("lon"), Full: []byte("lon")}, Value: []byte("8.5")}
+
+	tt := []struct {
+		name     string
+		a, b     xmltokenizer.Token
+		expected string
+	}{
+		{
+			name: "equal regardless of attrs order and position",
+			a:    trkpt("47.1", lon),
+			b: func() xmltokenizer.Token {
+				tok := trkpt("47.1", lon)
+				tok.Attrs[0], tok.Attrs[1] = tok.Attrs[1], tok.Attrs[0]
+				tok.Begin.Line = 11
+				return tok
+			}(),
+			expected: "",
+		},
+		{
+			name:     "attr differs",
+			a:        trkpt("47.1"),
+			b:        trkpt("47.2"),
+			expected: "name=trkpt lat attr differs: 47.1 vs 47.2 @ line 10",
+		},
+		{
+			name:     "attr missing and data differs",
+			a:        trkpt("47.1", lon),
+			b:        func() xmltokenizer.Token { tok := trkpt("47.1"); tok.Data = []byte("x"); return tok }(),
+			expected: "name=trkpt lon attr missing in other, data differs: \"\" vs \"x\" @ line 10",
+		},
+		{
+			name: "name and flags differ",
+			a:    xmltokenizer.Token{Name: xmltokenizer.Name{Full: []byte("a")}, SelfClosing: true},
+			b:    xmltokenizer.Token{Name: xmltokenizer.Name{Full: []byte("b")}, Attrs: []xmltokenizer.Attr{lon}},
+			expected: "name=a name differs: a vs b, lon attr only in other, " +
+				"self-closing differs: true vs false @ line 0",
+		},
+		{
+			name: "namespaces differ",
+			a: func() xmltokenizer.Token {
+				tok := trkpt("47.1")
+				tok.Name.Space = []byte("urn:a")
+				tok.Attrs[0].Name.Space = []byte("urn:a")
+				return tok
+			}(),
+			b: func() xmltokenizer.Token {
+				tok := trkpt("47.1")
+				tok.Name.Space = []byte("urn:b")
+				return tok
+			}(),
+			expected: "name=trkpt namespace differs: urn:a vs urn:b, lat attr namespace differs: urn:a vs  @ line 10",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if equal := tc.a.Equal(tc.b); equal != (tc.expected == "") {
+				t.Fatalf("expected equal: %t, got: %t", tc.expected == "", equal)
+			}
+			if diff := tc.a.Diff(tc.b); diff != tc.expected {
+				t.Fatalf("expected: %q, got: %q", tc.expected, diff)
+			}
+		})
+	}
+}
+
+func TestCopyInto(t *testing.T) {
+	src := xmltokenizer.Token{
+		Name: xmltokenizer.Name{Prefix: []byte("x"), Local: []byte("c"), Full: []byte("x:c")},
+		Attrs: []xmltokenizer.Attr{
+			{Name: xmltokenizer.Name{Local: []byte("r"), Full: []byte("r")}, Value: []byte("A1")},
+			{Name: xmltokenizer.Name{Local: []byte("t"), Full: []byte("t")}, Value: []byte("s"), Index: 1},
+		},
+		Data:  []byte("text"),
+		Begin: xmltokenizer.Pos{Line: 1, Column: 1},
+		End:   xmltokenizer.Pos{Line: 1, Column: 30, Offset: 29},
+	}
+
+	arena := xmltokenizer.NewArena(1 << 20)
+	var copied xmltokenizer.Token
+	alloc := testing.AllocsPerRun(100, func() {
+		copied = src.CopyInto(arena)
+	})
+	if alloc != 0 {
+		t.Fatalf("expected alloc: 0, got: %g", alloc)
+	}
+	if diff := cmp.Diff(copied, src); diff != "" {
+		t.Fatal(diff)
+	}
+
+	var zero xmltokenizer.Arena
+	if diff := cmp.Diff(src.CopyInto(&zero), src); diff != "" {
+		t.Fatal(diff)
+	}
+
+	src.Name.Full[0], src.Data[0], src.Attrs[0].Value[0] = 'y', 'T', 'B'
+	if string(copied.Name.Full) != "x:c" || string(copied.Data) != "text" || string(copied.Attrs[0].Value) != "A1" {
+		t.Fatalf("expected copy to own its memory, got: %s %s %s", copied.Name.Full, copied.Data, copied.Attrs[0].Value)
+	}
+}
+
+func TestPosCompare(t *testing.T) {
+	pos := func(line, column, offset int) xmltokenizer.Pos {
+		return xmltokenizer.Pos{Line: line, Column: column, Offset: offset}
+	}
+
+	tt := []struct {
+		name     string
+		a, b     xmltokenizer.Pos
+		expected int
+	}{
+		{name: "same", a: pos(2, 3, 10), b: pos(2, 3, 10), expected: 0},
+		{name: "previous line", a: pos(1, 30, 9), b: pos(2, 3, 10), expected: -1},
+		{name: "next column", a: pos(2, 4, 11), b: pos(2, 3, 10), expected: 1},
+		{name: "offset only", a: pos(0, 0, 5), b: pos(0, 0, 10), expected: -1},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if c := tc.a.Compare(tc.b); c != tc.expected {
+				t.Fatalf("expected: %d, got: %d", tc.expected, c)
+			}
+			if c := tc.b.Compare(tc.a); c != -tc.expected {
+				t.Fatalf("expected reversed: %d, got: %d", -tc.expected, c)
+			}
+		})
+	}
+
+	if !(xmltokenizer.Pos{}).IsZero() || pos(1, 1, 0).IsZero() {
+		t.Fatalf("expected only the zero Pos to be zero")
+	}
+}
+
+func TestRange(t *testing.T) {
+	tok := xmltokenizer.New(strings.NewReader("<a>\n  <b/><c/>\n</a>"))
+	var ranges []xmltokenizer.Range
+	for i := 0; i < 3; i++ {
+		token, err := tok.Token()
+		if err != nil {
+			t.Fatal(err)
+		}
+		ranges = append(ranges, token.Range())
+	}
+	a, b, c := ranges[0], ranges[1], ranges[2]
+
+	if !b.Contains(b.Begin) || b.Contains(b.End) || !c.Contains(b.End) {
+		t.Fatalf("expected Begin to be within %s and End not", b)
+	}
+	if b.Overlaps(c) || c.Overlaps(b) || a.Overlaps(b) {
+		t.Fatalf("expected adjacent ranges not to overlap: %s, %s, %s", a, b, c)
+	}
+	whole := xmltokenizer.Range{Begin: a.Begin, End: c.End}
+	if !whole.Overlaps(b) || !b.Overlaps(whole) {
+		t.Fatalf("expected %s to overlap %s", whole, b)
+	}
+	if !(xmltokenizer.Range{}).IsZero() || whole.IsZero() {
+		t.Fatalf("expected only the zero Range to be zero")
+	}
+}
+
+func TestTokenAttrAt(t *testing.T) {
+	tok := xmltokenizer.New(strings.NewReader(`<a z="1" x="2" y="3"/>`))
+	token, err := tok.Token()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, name := range []string{"z", "x", "y"} {
+		attr, ok := token.AttrAt(i)
+		if !ok || string(attr.Name.Full) != name || attr.Index != i {
+			t.Fatalf("[%d] expected attr: %s, got: %v, %t", i, name, attr, ok)
+		}
+	}
+
+	token.Attrs = token.Attrs[1:] // filtered out z
+	if attr, ok := token.AttrAt(2); !ok || string(attr.Name.Full) != "y" {
+		t.Fatalf("expected attr: y, got: %v, %t", attr, ok)
+	}
+	for _, i := range []int{-1, 0, 3} {
+		if attr, ok := token.AttrAt(i); ok {
+			t.Fatalf("[%d] expected not found, got: %v", i, attr)
+		}
+	}
+}
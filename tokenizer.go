@@ -2,17 +2,40 @@ package xmltokenizer
 
 import (
 	"bytes"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
+	"sync"
+	"unicode/utf8"
 )
 
 type errorString string
 
 func (e errorString) Error() string { return string(e) }
 
+// ErrMaxInputBytesExceeded is returned when the Tokenizer has read more bytes
+// than the limit set by WithMaxInputBytes.
+const ErrMaxInputBytesExceeded = errorString("max input bytes exceeded")
+
+// ErrMaxAttrValueSizeExceeded is returned when an attribute value is longer
+// than the limit set by WithMaxAttrValueSize.
+const ErrMaxAttrValueSizeExceeded = errorString("max attribute value size exceeded")
+
+// ErrIllegalChar is returned when a character not allowed in XML, such as a
+// control character, is found, see WithIllegalCharCheck.
+const ErrIllegalChar = errorString("illegal character")
+
+// ErrFixedBufferExceeded is returned when a token does not fit in the
+// buffer set by WithFixedBuffer, or has more attributes than its capacity.
+const ErrFixedBufferExceeded = errorString("fixed buffer exceeded")
+
 const (
 	errAutoGrowBufferExceedMaxLimit = errorString("auto grow buffer exceed max limit")
+	errGrowPolicyInsufficientSize   = errorString("grow policy returns insufficient size")
+	errClosed                       = errorString("tokenizer is closed")
+	errRestoreUnsupported           = errorString("restore requires an io.ReaderAt and io.Seeker")
+	errUnexpectedEndElement         = errorString("unexpected end element")
 )
 
 const (
@@ -21,20 +44,90 @@ const (
 	defaultAttrsBufferSize     = 16
 )
 
+// chunk modes of a char data that is split into multiple tokens.
+const (
+	chunkNone byte = iota
+	chunkText
+	chunkCDATA
+)
+
 // Tokenizer is a XML tokenizer.
 type Tokenizer struct {
-	r       io.Reader // reader provided by the client
-	options options   // tokenizer's options
-	buf     []byte    // buffer that will grow as needed, large enough to hold a token (default max limit: 1MB)
-	cur     int       // cursor byte position
-	err     error     // last encountered error
-	token   Token     // shared token
+	r           io.Reader     // reader provided by the client
+	options     options       // tokenizer's options
+	buf         []byte        // buffer that will grow as needed, large enough to hold a token (default max limit: 1MB)
+	cur         int           // cursor byte position
+	err         error         // last encountered error
+	token       Token         // shared token
+	chunk       byte          // chunk mode of the pending char data continuation
+	partial     bool          // last raw token's char data continues in the next raw token
+	continued   bool          // last raw token is a continuation of the previous raw token's char data
+	lastChunk   byte          // chunk mode of the last raw token if it is continued
+	kind        Kind          // kind of the last token, see Kind
+	cdata       bool          // whether the Data of the last token is a CDATA section's
+	unescaped   []byte        // decoded Data and values of the last token, see WithUnescapeData
+	escaped     []byte        // Data of the last token before it is decoded, nil if it is not
+	escapedVals [][]byte      // values of the last token's Attrs before they are decoded, nil if not
+	read        int64         // total bytes read from r
+	recording   bool          // whether the consumed bytes are being recorded into rec
+	rec         []byte        // recorded bytes, see record
+	space       []byte        // bytes skipped before the last token, see WithSpace
+	fold        []byte        // lowercased names of the last token, see WithCaseFold
+	ra          io.ReaderAt   // r if it is an io.ReaderAt and io.Seeker, see New
+	off         int64         // offset of buf[0] within ra
+	stale       bool          // buf holds bytes that must be read again from ra
+	arena       Arena         // memory of the returned tokens, see WithPersistentTokens
+	cr          bool          // last byte stepped over is '\r', see WithNewline
+	reported    bool          // whether an error has been reported, see WithMetrics
+	span        TraceSpan     // current span, see WithTracer
+	tokens      int           // number of tokens returned within span
+	interrupted bool          // t.err is returned by r, see Resume
+	resumed     bool          // the last token is being read again, see Resume
+	ns          namespaces    // namespace declarations in scope, see WithNamespaceAliases
+	stats       TokenStats    // sizes of the tokens read, see WithAdaptiveBuffer
+	open        []openElement // elements open at the last token, see SyntaxError
+	openNames   []byte        // full names of the open elements
+	consumed    bool          // the token causing t.err is already consumed, see Recover
+	rangeEnd    int64         // offset where the tokens stop beginning, see NewRanged
+}
+
+// openElement is an element open at the last token, its full name is
+// openNames[start:end].
+type openElement struct {
+	start, end int
+	begin      Pos
+}
+
+type readerAtSeeker interface {
+	io.ReaderAt
+	io.Seeker
 }
 
 type options struct {
 	readBufferSize             int
 	autoGrowBufferMaxLimitSize int
 	attrsBufferSize            int
+	growPolicy                 func(current, needed int) (newSize int, err error)
+	chunkedCharData            bool
+	maxInputBytes              int64
+	maxAttrValueSize           int
+	adaptiveBuffer             bool
+	illegalCharCheck           bool
+	closeReader                bool
+	space                      bool
+	caseFold                   bool
+	fixedBuffer                []byte
+	persistentTokens           bool
+	maxRetainedBuffer          int
+	newline                    Newline
+	columnUnit                 ColumnUnit
+	metrics                    Metrics
+	tracer                     Tracer
+	namespaceAliases           map[string]string
+	namespaceCheck             bool
+	namespaceResolution        bool
+	unescapeData               bool
+	framing                    bool // never read beyond a tag's ">", see StanzaReader
 }
 
 func defaultOptions() options {
@@ -75,16 +168,187 @@ func WithAttrBufferSize(size int) Option {
 	return func(o *options) { o.attrsBufferSize = size }
 }
 
+// WithGrowPolicy directs XML Tokenizer to use this policy to decide the new
+// buffer size whenever a token does not fit in the current buffer. It receives
+// the current buffer capacity and the minimum size needed, and returns the new
+// size, which must be at least the needed size. Any error returned is passed
+// through to the caller. When set, the auto grow buffer max limit is not
+// enforced by the Tokenizer, the policy is responsible for limiting it.
+// Default: double the capacity up to the auto grow buffer max limit.
+func WithGrowPolicy(policy func(current, needed int) (newSize int, err error)) Option {
+	return func(o *options) { o.growPolicy = policy }
+}
+
+// WithChunkedCharData directs XML Tokenizer to split a CharData or CDATA
+// that exceeds the auto grow buffer max limit into multiple tokens instead
+// of failing. The first chunk is delivered in the start element's Data,
+// the following chunks are delivered as tokens with only Data and
+// Continued set. Only the outer whitespace of the whole CharData is trimmed.
+func WithChunkedCharData() Option {
+	return func(o *options) { o.chunkedCharData = true }
+}
+
+// WithMaxInputBytes directs XML Tokenizer to abort with ErrMaxInputBytesExceeded
+// once more than n bytes have been read from the io.Reader. Default: 0 (no limit).
+func WithMaxInputBytes(n int64) Option {
+	if n < 0 {
+		n = 0
+	}
+	return func(o *options) { o.maxInputBytes = n }
+}
+
+// WithMaxAttrValueSize directs XML Tokenizer to abort with
+// ErrMaxAttrValueSizeExceeded, as a SyntaxError positioned at the start of
+// the value, as soon as an attribute value is known to be longer than n bytes,
+// e.g. a multi-megabyte data URI, rather than growing the buffer toward the
+// limit set by WithAutoGrowBufferMaxLimitSize. The offending element can be
+// skipped with Recover. Default: 0 (no limit).
+func WithMaxAttrValueSize(n int) Option {
+	if n < 0 {
+		n = 0
+	}
+	return func(o *options) { o.maxAttrValueSize = n }
+}
+
+// WithIllegalCharCheck directs XML Tokenizer to abort with ErrIllegalChar,
+// as a SyntaxError positioned at the character, when a token contains a
+// character not allowed in XML 1.0: the control characters other than tab,
+// line feed and carriage return, U+FFFE and U+FFFF. Character references, such
+// as "&#1;", are not checked. The offending token, unless it is a chunk of
+// CharData, see WithChunkedCharData, can be skipped with Recover.
+func WithIllegalCharCheck() Option {
+	return func(o *options) { o.illegalCharCheck = true }
+}
+
+// WithCloseReader directs XML Tokenizer to also close the io.Reader
+// on Close if it implements io.Closer.
+func WithCloseReader() Option {
+	return func(o *options) { o.closeReader = true }
+}
+
+// WithSpace directs XML Tokenizer to keep the bytes skipped before each
+// token, i.e. the whitespace between tokens, so they can be retrieved by
+// Space. CharData following a tag is not skipped, it is part of the Raw of
+// that tag.
+func WithSpace() Option {
+	return func(o *options) { o.space = true }
+}
+
+// WithCaseFold directs XML Tokenizer to lowercase the ASCII letters of the
+// names of elements and attributes, so <Item>, <ITEM> and <item> are treated
+// identically, e.g. for HTML-ish or legacy feeds. Values, Data, RawToken
+// and Raw are left untouched.
+func WithCaseFold() Option {
+	return func(o *options) { o.caseFold = true }
+}
+
+// WithFixedBuffer directs XML Tokenizer to use buf as its only buffer, so it
+// never allocates after New: a token that does not fit in buf, or an element
+// with more attributes than the capacity set by WithAttrBufferSize, results in
+// ErrFixedBufferExceeded. The options keeping extra bytes, such as WithSpace
+// and WithCaseFold, may still allocate. The caller must not use buf until the
+// Tokenizer is closed or reset without this option.
+func WithFixedBuffer(buf []byte) Option {
+	return func(o *options) { o.fixedBuffer = buf }
+}
+
+// WithPersistentTokens directs XML Tokenizer to return tokens owning their
+// memory, drawn from an internal arena, so they remain valid after the next
+// Token invocation, e.g. to be sent through a channel. The arena allocates
+// memory in large blocks which are garbage collected once their tokens are
+// no longer referenced. RawToken is not affected.
+func WithPersistentTokens() Option {
+	return func(o *options) { o.persistentTokens = true }
+}
+
+// WithMaxRetainedBuffer directs XML Tokenizer to release, rather than reuse
+// on Reset or put back to the pool on Close, its internal buffers grown beyond
+// size bytes, e.g. by a single huge token, so reused Tokenizers return to a
+// sane footprint. Default: 0 (buffers are always retained).
+func WithMaxRetainedBuffer(size int) Option {
+	if size < 0 {
+		size = 0
+	}
+	return func(o *options) { o.maxRetainedBuffer = size }
+}
+
+// Newline is a convention of line terminators, see WithNewline.
+type Newline byte
+
+const (
+	// NewlineLF terminates lines with '\n' only, so "\r\n" terminates one
+	// line and a lone '\r' counts as a column.
+	NewlineLF Newline = iota
+	// NewlineAny terminates lines with "\r\n", '\n' or a lone '\r', as the
+	// XML end-of-line handling and most editors do.
+	NewlineAny
+	// NewlineCRAndLF terminates lines with either '\r' or '\n', so "\r\n"
+	// terminates two lines.
+	NewlineCRAndLF
+)
+
+// ColumnUnit is the unit in which columns are counted, see WithColumnUnit.
+type ColumnUnit byte
+
+const (
+	ColumnRunes ColumnUnit = iota // Columns count Unicode code points.
+	ColumnBytes                   // Columns count bytes.
+	ColumnUTF16                   // Columns count UTF-16 code units, as VS Code and the LSP do.
+)
+
+// WithNewline directs XML Tokenizer to count the lines of Begin and End using
+// the given line terminators convention. Default: NewlineLF.
+func WithNewline(n Newline) Option {
+	return func(o *options) { o.newline = n }
+}
+
+// WithColumnUnit directs XML Tokenizer to count the columns of Begin and End
+// in the given unit, so the positions line up with other tools. Offsets are
+// always in bytes. Default: ColumnRunes.
+func WithColumnUnit(u ColumnUnit) Option {
+	return func(o *options) { o.columnUnit = u }
+}
+
 // New creates new XML tokenizer.
+//
+// If r implements both io.ReaderAt and io.Seeker, such as *os.File, the
+// Tokenizer reads directly at offsets starting from r's current offset,
+// which it leaves untouched, instead of moving the remaining bytes of its
+// buffer forward, and it can Restore a Checkpoint.
 func New(r io.Reader, opts ...Option) *Tokenizer {
 	t := new(Tokenizer)
 	t.reset(r, opts...)
 	return t
 }
 
+// Reset resets the Tokenizer to read from r with the given options, reusing
+// its internal buffers, even after Close. Buffers grown beyond the size set by
+// WithMaxRetainedBuffer are released instead. Any token or raw token previously
+// returned must not be used after Reset.
+func (t *Tokenizer) Reset(r io.Reader, opts ...Option) {
+	t.reset(r, opts...)
+}
+
 func (t *Tokenizer) reset(r io.Reader, opts ...Option) {
+	t.endSpan(nil)
+	if t.options.fixedBuffer != nil {
+		t.buf = nil // owned by the caller, see WithFixedBuffer
+	}
 	t.r, t.err = r, nil
-	t.cur = 0
+	t.cur, t.read = 0, 0
+	t.chunk, t.partial, t.continued = chunkNone, false, false
+	t.recording, t.rec = false, t.rec[:0]
+	t.space = t.space[:0]
+	t.ra, t.off, t.stale = nil, 0, false
+	t.rangeEnd = 0
+	t.cr, t.reported = false, false
+	t.interrupted, t.resumed, t.consumed = false, false, false
+	t.open, t.openNames = t.open[:0], t.openNames[:0]
+	if ras, ok := r.(readerAtSeeker); ok {
+		if off, err := ras.Seek(0, io.SeekCurrent); err == nil {
+			t.ra, t.off = ras, off
+		}
+	}
 	t.token.Begin = Pos{1, 1, 0}
 	t.token.End = Pos{1, 1, 0}
 
@@ -93,20 +357,100 @@ func (t *Tokenizer) reset(r io.Reader, opts ...Option) {
 		opts[i](&t.options)
 	}
 
+	if max := t.options.maxRetainedBuffer; max > 0 {
+		t.buf = shrink(t.buf, max)
+		t.rec, t.space, t.fold = shrink(t.rec, max), shrink(t.space, max), shrink(t.fold, max)
+		t.ns.alias, t.ns.uris = shrink(t.ns.alias, max), shrink(t.ns.uris, max)
+		t.unescaped = shrink(t.unescaped, max)
+	}
+	t.ns.reset(t.options.namespaceAliases, t.options.namespaceCheck, t.options.namespaceResolution)
+
 	if cap(t.token.Attrs) < t.options.attrsBufferSize {
-		t.token.Attrs = make([]Attr, 0, t.options.attrsBufferSize)
+		t.token.Attrs = getAttrs(t.options.attrsBufferSize)
 	}
 	if t.options.readBufferSize > t.options.autoGrowBufferMaxLimitSize {
 		t.options.autoGrowBufferMaxLimitSize = t.options.readBufferSize
 	}
 
-	switch size := t.options.readBufferSize; {
+	switch size := t.bufferSize(); {
+	case t.options.fixedBuffer != nil:
+		t.buf = t.options.fixedBuffer[:0:cap(t.options.fixedBuffer)]
 	case cap(t.buf) >= size+defaultReadBufferSize:
 		t.buf = t.buf[:0]
 	default:
 		// Create buffer with additional cap since we need to memmove remaining bytes
-		t.buf = make([]byte, 0, size+defaultReadBufferSize)
+		t.buf = getBuffer(size + defaultReadBufferSize)
+	}
+}
+
+// shrink returns b, or nil if its capacity exceeds max.
+func shrink(b []byte, max int) []byte {
+	if cap(b) > max {
+		return nil
+	}
+	return b
+}
+
+// Close releases the Tokenizer's internal buffers so they can be reused by
+// other Tokenizers. If WithCloseReader is specified, it also closes the
+// io.Reader if it implements io.Closer. The Tokenizer, and any token or raw
+// token previously returned, must not be used after Close.
+func (t *Tokenizer) Close() error {
+	if t.err == errClosed {
+		return nil
+	}
+	t.endSpan(nil)
+	if max := t.options.maxRetainedBuffer; t.options.fixedBuffer == nil && (max <= 0 || cap(t.buf) <= max) {
+		putBuffer(t.buf)
+	}
+	putAttrs(t.token.Attrs)
+	t.buf, t.token = nil, Token{}
+	t.cur, t.err = 0, errClosed
+
+	var err error
+	if c, ok := t.r.(io.Closer); ok && t.options.closeReader {
+		err = c.Close()
 	}
+	t.r = nil
+	return err
+}
+
+var bufferPool, attrsPool sync.Pool
+
+// getBuffer gets an empty buffer with at least the given capacity from the pool.
+func getBuffer(size int) []byte {
+	if p, _ := bufferPool.Get().(*[]byte); p != nil && cap(*p) >= size {
+		return (*p)[:0]
+	}
+	return make([]byte, 0, size)
+}
+
+// putBuffer puts the buffer back to the pool.
+func putBuffer(buf []byte) {
+	if cap(buf) == 0 {
+		return
+	}
+	buf = buf[:0]
+	bufferPool.Put(&buf)
+}
+
+// getAttrs gets an empty attrs with at least the given capacity from the pool.
+func getAttrs(size int) []Attr {
+	if p, _ := attrsPool.Get().(*[]Attr); p != nil && cap(*p) >= size {
+		return (*p)[:0]
+	}
+	return make([]Attr, 0, size)
+}
+
+// putAttrs puts the attrs back to the pool, the elements are cleared
+// so it does not hold any reference to the previous buffer.
+func putAttrs(attrs []Attr) {
+	if cap(attrs) == 0 {
+		return
+	}
+	clear(attrs[:cap(attrs)])
+	attrs = attrs[:0]
+	attrsPool.Put(&attrs)
 }
 
 // Token returns either a valid token or an error.
@@ -114,29 +458,41 @@ func (t *Tokenizer) reset(r io.Reader, opts ...Option) {
 // Token or RawToken method invocation.
 func (t *Tokenizer) Token() (token Token, err error) {
 	if t.err != nil {
-		return token, t.err
+		t.trailingSpace()
+		return token, t.syntaxError()
 	}
 
 	b, err := t.RawToken()
 	if err != nil {
-		if !errors.Is(err, io.EOF) {
-			pos := t.token.End
-			pos.step(t.buf[t.cur:])
-			err = fmt.Errorf("line: %d column: %d byte offset %d: %w", pos.Line, pos.Column, pos.Offset, err)
-		}
-		if len(b) == 0 || errors.Is(err, io.ErrUnexpectedEOF) {
-			return
-		}
-		t.err = err
+		return token, t.syntaxError() // b, if any, is an incomplete token
 	}
 
 	t.clearToken()
 
-	b = t.consumeNonTagIdentifier(b)
-	if len(b) > 0 {
+	if t.continued {
+		t.consumeCharDataContinuation(b)
+	} else if b = t.consumeNonTagIdentifier(b); len(b) > 0 {
 		b = t.consumeTagName(b)
-		b = t.consumeAttrs(b)
+		if b = t.consumeAttrs(b); errors.Is(t.err, ErrFixedBufferExceeded) {
+			t.consumed = true
+			t.report(t.err)
+			return token, t.syntaxError()
+		}
 		t.consumeCharData(b)
+		if t.options.caseFold {
+			t.foldNames()
+		}
+		if t.options.namespaceAliases != nil || t.options.namespaceCheck || t.options.namespaceResolution {
+			if t.err = t.resolveNamespaces(); t.err != nil {
+				t.consumed = true
+				t.report(t.err)
+				return token, t.err
+			}
+		}
+		t.trackElement()
+	}
+	if t.options.unescapeData {
+		t.unescapeToken()
 	}
 
 	token = t.token
@@ -146,18 +502,294 @@ func (t *Tokenizer) Token() (token Token, err error) {
 	if len(token.Data) == 0 {
 		token.Data = nil
 	}
+	if t.options.persistentTokens {
+		token = t.arena.copyToken(token)
+	}
 
 	return token, nil
 }
 
+// Space returns the bytes skipped before the last token returned by Token
+// or RawToken, i.e. the whitespace preceding it, or the trailing bytes once
+// io.EOF is returned. CharData following a tag, an end element included, is
+// part of the Raw of that tag, not of Space. Concatenating Space and Raw
+// of every token, and finally Space, reproduces the input byte-exact.
+// WithSpace must be specified, otherwise it returns nil. The returned bytes
+// are only valid before next Token or RawToken method invocation.
+func (t *Tokenizer) Space() []byte {
+	if !t.options.space {
+		return nil
+	}
+	return t.space
+}
+
+// Raw returns the raw bytes of the last token returned by Token or RawToken,
+// or nil if there is none. The returned bytes are only valid before next
+// Token or RawToken method invocation.
+func (t *Tokenizer) Raw() []byte {
+	n := t.token.End.Offset - t.token.Begin.Offset
+	if n > t.cur {
+		return nil
+	}
+	return t.buf[t.cur-n : t.cur]
+}
+
+// Checkpoint is the state of a Tokenizer between two tokens, see Restore.
+type Checkpoint struct {
+	offset int64 // offset within the io.ReaderAt
+	pos    Pos   // end position of the last token
+	chunk  byte  // chunk mode of the pending char data continuation
+	cr     bool  // whether the last token ends with '\r'
+}
+
+// Checkpoint returns the state of the Tokenizer after the last token, which
+// can be passed to Restore to continue tokenizing from there again.
+func (t *Tokenizer) Checkpoint() Checkpoint {
+	return Checkpoint{
+		offset: t.off + int64(t.cur),
+		pos:    t.token.End,
+		chunk:  t.chunk,
+		cr:     t.cr,
+	}
+}
+
+// Restore restores the state of the Tokenizer to the given Checkpoint, so
+// the next token is the one following the Checkpoint. It is only supported
+// when the io.Reader implements both io.ReaderAt and io.Seeker, see New.
+// The tokens previously returned must not be used after Restore.
+func (t *Tokenizer) Restore(c Checkpoint) error {
+	if t.ra == nil {
+		return errRestoreUnsupported
+	}
+	if t.err == errClosed {
+		return t.err
+	}
+	t.err = nil
+	t.buf = t.buf[:0]
+	t.cur, t.off, t.stale = 0, c.offset, false
+	t.chunk, t.partial, t.continued = c.chunk, false, false
+	t.recording, t.rec = false, t.rec[:0]
+	t.space = t.space[:0]
+	t.read = int64(c.pos.Offset)
+	t.token.Begin, t.token.End = c.pos, c.pos
+	t.cr = c.cr
+	t.reported = false
+	t.interrupted, t.resumed = false, false
+	return nil
+}
+
+// Resume resumes tokenizing after an error returned by the io.Reader other
+// than io.EOF, keeping the state of the Tokenizer, e.g. to wake up a Tokenizer
+// blocked on a long-lived network stream, either by a read deadline or by an
+// error injected by the reader, and later continue where it left off. The
+// token being read when the error occurs is read again from its beginning.
+// Resume must be invoked before the next Token or RawToken invocation. It
+// returns the last error if it is not returned by the io.Reader, in which case
+// the Tokenizer can not resume.
+func (t *Tokenizer) Resume() error {
+	if !t.interrupted {
+		return t.err
+	}
+	t.err, t.interrupted, t.resumed, t.reported = nil, false, true, false
+	return nil
+}
+
+// Recover resumes tokenizing after a SyntaxError caused by a malformed or
+// oversized token, such as an unclosed quote swallowing the rest of the
+// document, a truncated tag or a token exceeding the buffer limits, so tools
+// like linters and extractors can keep processing a damaged document. The
+// "<" starting the offending token is skipped, so tokenizing resynchronizes
+// at the next "<", and the bytes in between are skipped as CharData would be:
+// they are returned by Space along with the next token. Recover must be
+// invoked before the next Token or RawToken invocation. It returns the last
+// error if it can not be recovered from, such as io.EOF,
+// ErrMaxInputBytesExceeded or an error returned by the io.Reader, see Resume.
+func (t *Tokenizer) Recover() error {
+	if t.err == nil {
+		return nil
+	}
+	if t.interrupted || !(errors.Is(t.err, io.ErrUnexpectedEOF) ||
+		errors.Is(t.err, errAutoGrowBufferExceedMaxLimit) ||
+		errors.Is(t.err, errGrowPolicyInsufficientSize) ||
+		errors.Is(t.err, ErrFixedBufferExceeded) ||
+		errors.Is(t.err, ErrMaxAttrValueSizeExceeded) ||
+		errors.Is(t.err, ErrIllegalChar) ||
+		errors.Is(t.err, ErrNamespace)) {
+		return t.err
+	}
+	switch {
+	case t.consumed:
+		if t.options.space {
+			t.space = append(t.space, t.Raw()...)
+		}
+	case t.stale: // the '<' is at the start of the bytes to be read again
+		t.off, t.buf, t.stale = t.off+1, t.buf[:0], false
+		t.skip([]byte{'<'})
+		if t.recording {
+			t.rec = append(t.rec, '<')
+		}
+	case t.cur < len(t.buf) && t.buf[t.cur] == '<':
+		t.skip(t.buf[t.cur : t.cur+1])
+		t.advance(1)
+	default:
+		return t.err
+	}
+	t.err, t.reported = nil, false
+	t.chunk, t.partial, t.continued = chunkNone, false, false
+	t.resumed = t.options.space // the skipped bytes are kept in the space
+	if !t.consumed {
+		t.skipToTag()
+	}
+	t.consumed = false
+	t.token.Begin = t.token.End
+	return nil
+}
+
+// skipToTag skips the bytes up to the next '<', reading them as needed,
+// without retaining them in the buffer, see Recover. An error other than io.EOF
+// is latched, io.EOF is left to be encountered again by the next token.
+func (t *Tokenizer) skipToTag() {
+	for {
+		p := bytes.IndexByte(t.buf[t.cur:], '<')
+		if p != -1 {
+			t.skip(t.buf[t.cur : t.cur+p])
+			t.advance(p)
+			return
+		}
+		t.skip(t.buf[t.cur:])
+		t.advance(len(t.buf) - t.cur)
+		t.memmoveRemainingBytes(t.cur)
+		if err := t.manageBuffer(); err != nil {
+			if err != io.EOF {
+				t.err = err
+			}
+			return
+		}
+	}
+}
+
+// skip steps over b, which is appended to the space, see Recover.
+func (t *Tokenizer) skip(b []byte) {
+	t.step(&t.token.End, b)
+	if t.options.space {
+		t.space = append(t.space, b...)
+	}
+}
+
+// trailingSpace sets the remaining bytes as the space once an error is
+// latched, so they are only reported by the first call returning the error.
+func (t *Tokenizer) trailingSpace() {
+	if !t.options.space || t.interrupted {
+		return
+	}
+	t.space = append(t.space[:0], t.buf[t.cur:]...)
+	t.cur = len(t.buf)
+	t.token.Begin = t.token.End // there is no last token, so Raw is empty.
+}
+
+// syntaxError wraps t.err as SyntaxError with the position where it occurs
+// so the subsequent calls return the same error, unless it is io.EOF or an
+// error of the io.Reader, e.g. a timeout, which is not a malformed document.
+func (t *Tokenizer) syntaxError() error {
+	var syntaxErr *SyntaxError
+	if t.err == io.EOF || t.err == errClosed || t.interrupted || errors.As(t.err, &syntaxErr) {
+		return t.err
+	}
+	t.err = t.syntaxErrorAt(t.err, len(t.buf))
+	return t.err
+}
+
+// syntaxErrorAt returns err as SyntaxError occurring at t.buf[i], where i is
+// not before t.cur, annotated with the innermost open element.
+func (t *Tokenizer) syntaxErrorAt(err error, i int) *SyntaxError {
+	pos, cr := t.token.End, t.cr
+	t.step(&pos, t.buf[t.cur:i])
+	t.cr = cr
+	return t.annotate(newSyntaxError(err, pos, t.buf, i))
+}
+
+// annotate annotates syntaxErr with the innermost open element, if any.
+func (t *Tokenizer) annotate(syntaxErr *SyntaxError) *SyntaxError {
+	if n := len(t.open); n > 0 {
+		e := t.open[n-1]
+		full := append([]byte(nil), t.openNames[e.start:e.end]...)
+		prefix, local := SplitQName(full)
+		syntaxErr.Element = Name{Prefix: prefix, Local: local, Full: full}
+		syntaxErr.ElementBegin = e.begin
+	}
+	return syntaxErr
+}
+
+// trackElement pushes the last token if it is a start element, or pops its
+// start element if it is an end element, so a SyntaxError can tell
+// which element is being parsed.
+func (t *Tokenizer) trackElement() {
+	switch {
+	case len(t.token.Name.Full) == 0 || t.token.SelfClosing:
+	case t.token.IsEndElement:
+		// Pop up to the matching element, if any, so an unclosed element is
+		// not reported once its parent is closed.
+		for i := len(t.open) - 1; i >= 0; i-- {
+			e := t.open[i]
+			if string(t.openNames[e.start:e.end]) == string(t.token.Name.Full) {
+				t.open, t.openNames = t.open[:i], t.openNames[:e.start]
+				break
+			}
+		}
+	default:
+		start := len(t.openNames)
+		t.openNames = append(t.openNames, t.token.Name.Full...)
+		t.open = append(t.open, openElement{start: start, end: len(t.openNames), begin: t.token.Begin})
+	}
+}
+
 // RawToken returns token in its raw bytes. At the end,
 // it may returns last token bytes and an error.
 // The returned token bytes is only valid before next
 // Token or RawToken method invocation.
 func (t *Tokenizer) RawToken() ([]byte, error) {
+	if t.options.metrics == nil && t.options.tracer == nil && !t.options.adaptiveBuffer {
+		return t.rawToken()
+	}
+	t.startSpan()
+	b, err := t.rawToken()
+	if err == nil {
+		t.tokens++
+		if t.options.metrics != nil {
+			t.options.metrics.Token(len(b))
+		}
+		if t.options.adaptiveBuffer {
+			t.observe(len(b))
+		}
+	}
+	t.report(err)
+	return b, err
+}
+
+// report reports the first error to the Metrics, unless it is io.EOF, and
+// ends the span with it.
+func (t *Tokenizer) report(err error) {
+	if err == nil || t.reported {
+		return
+	}
+	t.reported = true
+	if err == io.EOF {
+		err = nil
+	} else if t.options.metrics != nil {
+		t.options.metrics.Error(err)
+	}
+	t.endSpan(err)
+}
+
+func (t *Tokenizer) rawToken() ([]byte, error) {
 	if t.err != nil {
+		t.trailingSpace()
 		return nil, t.err
 	}
+	if t.chunk != chunkNone {
+		return t.rawCharDataChunk()
+	}
+	t.partial, t.continued = false, false
 	for {
 		// Find opening <
 		p := bytes.IndexByte(t.buf[t.cur:], '<')
@@ -167,15 +799,38 @@ func (t *Tokenizer) RawToken() ([]byte, error) {
 			if t.err == nil {
 				continue
 			}
+			if t.options.space {
+				if !t.resumed { // otherwise append to the bytes skipped before, see Recover
+					t.space = t.space[:0]
+				}
+				if !t.interrupted { // otherwise the bytes are not skipped yet
+					t.space = append(t.space, t.buf[t.cur:]...)
+				}
+			}
+			return nil, t.err
+		}
+		if t.options.space {
+			if !t.resumed { // otherwise append to the bytes skipped before the interruption
+				t.space = t.space[:0]
+			}
+			t.space = append(t.space, t.buf[t.cur:t.cur+p]...)
+		}
+		t.resumed = false
+		t.step(&t.token.End, t.buf[t.cur:t.cur+p])
+		t.advance(p)
+		if t.rangeEnd > 0 && int64(t.token.End.Offset) >= t.rangeEnd {
+			// The token begins beyond the range, see NewRanged.
+			t.buf, t.err = t.buf[:t.cur], io.EOF
 			return nil, t.err
 		}
-		t.token.End.step(t.buf[t.cur : t.cur+p])
-		t.cur += p
 		break
 	}
 	for {
 		// Find closing >
 		pos := t.findTokenEnd(t.cur)
+		if pos == -1 && t.err != nil {
+			return nil, t.err // see WithMaxAttrValueSize
+		}
 		if pos == -1 {
 			_, pos = t.memmoveRemainingBytes(t.cur)
 			t.err = t.manageBuffer()
@@ -183,76 +838,138 @@ func (t *Tokenizer) RawToken() ([]byte, error) {
 				continue
 			}
 			if errors.Is(t.err, io.EOF) {
-				t.err = io.ErrUnexpectedEOF
+				t.err = t.truncated(t.cur)
 			}
 			return t.buf[t.cur:pos], t.err
 		}
 		switch t.buf[t.cur+1] {
 		default:
-			_, pos = t.parseCharData(t.cur, pos)
+			if t.options.framing {
+				break // the CharData is skipped before the next token
+			}
+			if _, pos = t.parseCharData(t.cur, pos); t.interrupted {
+				return nil, t.err // read the token again once resumed
+			}
 			pos++
 		case '?', '!':
 		}
-		buf := trimSuffix(t.buf[t.cur:pos])
+		buf := t.buf[t.cur:pos]
+		if !t.partial {
+			buf = TrimRightSpace(buf)
+		}
+		if t.options.illegalCharCheck {
+			if t.err = t.checkChars(buf); t.err != nil {
+				return nil, t.err
+			}
+		}
 		t.token.Begin = t.token.End
-		t.token.End.step(buf)
-		t.cur += len(buf)
+		t.step(&t.token.End, buf)
+		t.advance(len(buf))
 		return buf, nil
 	}
 }
 
+// truncated returns the error of the input ending within the token starting
+// at t.buf[start], which is a TruncatedError if the token is a CDATA section,
+// a comment or a processing instruction, otherwise io.ErrUnexpectedEOF.
+func (t *Tokenizer) truncated(start int) error {
+	b := t.buf[start:]
+	var opening, terminator string
+	for _, delim := range [...][2]string{{"<![CDATA[", "]]>"}, {"<!--", "-->"}, {"<?", "?>"}} {
+		if bytes.HasPrefix(b, []byte(delim[0])) {
+			opening, terminator = delim[0], delim[1]
+			break
+		}
+	}
+	if terminator == "" {
+		return io.ErrUnexpectedEOF
+	}
+	begin, cr := t.token.End, t.cr
+	t.step(&begin, t.buf[t.cur:start])
+	t.cr = cr
+	return &TruncatedError{
+		Begin:      begin,
+		Partial:    append([]byte(nil), b[len(opening):]...),
+		Terminator: terminator,
+	}
+}
+
 // findTokenEnd returns the index of the first character after the
 // token started at the given position, or -1 if more data needs
 // to be buffered.
 func (t *Tokenizer) findTokenEnd(pivot int) int {
 	left := pivot + 1 // left-hand bound on the search area
+	if left >= len(t.buf) {
+		return -1
+	}
+	switch t.buf[pivot+1] {
+	case '?':
+		// is a processing instruction
+		return t.findTokenSuffix(left, pivot+3, "?>")
+	case '!':
+		if len(t.buf) <= pivot+4 && bytes.IndexByte(t.buf[left:], '>') == -1 {
+			return -1 // not enough data to tell whether it is a comment
+		}
+		if len(t.buf) > pivot+4 && t.buf[pivot+2] == '-' && t.buf[pivot+3] == '-' {
+			// is a comment
+			return t.findTokenSuffix(left, pivot+6, "-->")
+		}
+		// is DOCTYPE, ENTITY etc, may contain nested tags
+		return t.findTagEnd(left, "\"'<>", 0)
+	}
+	return t.findTagEnd(left, "\"'>", t.options.maxAttrValueSize)
+}
+
+// findTokenSuffix returns the index of the first character after the given
+// suffix that ends at or after min, or -1 if more data needs to be buffered.
+func (t *Tokenizer) findTokenSuffix(left, min int, suffix string) int {
 	for {
-		var right int // the candidate end point
-		if p := bytes.IndexByte(t.buf[left:], '>'); p == -1 {
+		p := bytes.IndexByte(t.buf[left:], '>')
+		if p == -1 {
 			return -1
-		} else {
-			right = left + p + 1
-		}
-		switch t.buf[pivot+1] {
-		case '?':
-			// is a processing instruction
-			if right >= pivot+3 && t.buf[right-2] == '?' {
-				return right
-			}
-			// this > is not part of the closing ?>
-			left = right
-			continue
-		case '!':
-			if len(t.buf) > pivot+4 && t.buf[pivot+2] == '-' && t.buf[pivot+3] == '-' {
-				// is a comment
-				if right >= pivot+6 && t.buf[right-3] == '-' && t.buf[right-2] == '-' {
-					return right
-				}
-				// this > is not part of the closing -->
-				left = right
-				continue
-			}
-			// is DOCTYPE, ENTITY etc
-			p := bytes.IndexByte(t.buf[left+1:right-1], '<')
-			if p != -1 {
-				left = t.findTokenEnd(left + p + 1)
-				if left == -1 {
-					return -1
-				}
-				// this > is part of a nested tag
-				continue
-			}
 		}
-		if bytes.Count(t.buf[left:right], []byte{'"'})%2 == 0 && bytes.Count(t.buf[left:right], []byte{'\''})%2 == 0 {
+		right := left + p + 1
+		if right >= min && string(t.buf[right-len(suffix):right]) == suffix {
 			return right
 		}
-		// this > might be within a quoted value, scan to closing quote
-		p := bytes.IndexAny(t.buf[left:right], "'\"")
-		p = bytes.IndexByte(t.buf[left+p+1:], t.buf[left+p])
+		// this > is not part of the closing suffix
+		left = right
+	}
+}
+
+// findTagEnd returns the index of the first character after the closing >
+// that is not within a quoted value, or -1 if more data needs to be buffered.
+// The quote state is tracked while scanning so each byte is examined once.
+// When chars contains '<', nested tags are skipped as a whole. When a quoted
+// value exceeds maxValue bytes, if positive, t.err is set and -1 is returned.
+func (t *Tokenizer) findTagEnd(left int, chars string, maxValue int) int {
+	for {
+		p := bytes.IndexAny(t.buf[left:], chars)
 		if p == -1 {
 			return -1
 		}
-		left += p + 2
+		left += p
+		switch c := t.buf[left]; c {
+		case '>':
+			return left + 1
+		case '<':
+			// this is a nested tag, skip to its end
+			if left = t.findTokenEnd(left); left == -1 {
+				return -1
+			}
+		default:
+			// this is an opening quote, skip to the closing quote
+			p = bytes.IndexByte(t.buf[left+1:], c)
+			if maxValue > 0 && (p > maxValue || (p == -1 && len(t.buf)-left-1 > maxValue)) {
+				t.err = t.syntaxErrorAt(fmt.Errorf("attribute value exceeds %d bytes: %w",
+					maxValue, ErrMaxAttrValueSizeExceeded), left+1)
+				return -1
+			}
+			if p == -1 {
+				return -1
+			}
+			left += p + 2
+		}
 	}
 }
 
@@ -267,6 +984,9 @@ func (t *Tokenizer) parseCharData(pivot, pos int) (newPivot, newPos int) {
 			pivot, i = t.memmoveRemainingBytes(pivot)
 			pos = i - 1
 			if t.err = t.manageBuffer(); t.err != nil {
+				if t.isChunkable(t.err) {
+					pos = t.splitCharData(pivot, chunkText) - 1
+				}
 				break
 			}
 			continue
@@ -282,8 +1002,12 @@ func (t *Tokenizer) parseCharData(pivot, pos int) (newPivot, newPos int) {
 				pivot, j = t.memmoveRemainingBytes(pivot)
 				pos = pos - (prevLast - len(t.buf))
 				if t.err = t.manageBuffer(); t.err != nil {
+					if k == len(prefix) && t.isChunkable(t.err) {
+						pos = t.splitCharData(pivot, chunkCDATA) - 1
+						break
+					}
 					if errors.Is(t.err, io.EOF) {
-						t.err = io.ErrUnexpectedEOF
+						t.err = t.truncated(pos + 1)
 					}
 					break
 				}
@@ -305,10 +1029,232 @@ func (t *Tokenizer) parseCharData(pivot, pos int) (newPivot, newPos int) {
 	return pivot, pos
 }
 
+// isChunkable reports whether the char data being parsed can be split
+// into multiple tokens instead of failing with the given error.
+func (t *Tokenizer) isChunkable(err error) bool {
+	return t.options.chunkedCharData &&
+		(errors.Is(err, errAutoGrowBufferExceedMaxLimit) || errors.Is(err, ErrFixedBufferExceeded))
+}
+
+// splitCharData marks the char data started at the given pivot to be
+// continued in the next raw token and returns the end of current chunk.
+// It makes sure the chunk does not split a UTF-8 encoded rune nor a
+// CDATA suffix, unless the chunk would be empty.
+func (t *Tokenizer) splitCharData(pivot int, mode byte) int {
+	t.err = nil
+	t.chunk, t.partial = mode, true
+	t.event("xmltokenizer.split_char_data", TraceAttr{"offset", int64(t.token.End.Offset + pivot - t.cur)})
+
+	end := len(t.buf)
+	if mode == chunkCDATA {
+		for i := 0; i < len("]]") && end > pivot && t.buf[end-1] == ']'; i++ {
+			end--
+		}
+	}
+	for i := end - 1; i >= pivot && i >= end-utf8.UTFMax; i-- {
+		if utf8.RuneStart(t.buf[i]) {
+			if !utf8.FullRune(t.buf[i:end]) {
+				end = i
+			}
+			break
+		}
+	}
+	if end <= pivot {
+		end = len(t.buf)
+	}
+	return end
+}
+
+// rawCharDataChunk returns the next chunk of the char data that has
+// been split since it exceeds the auto grow buffer max limit.
+func (t *Tokenizer) rawCharDataChunk() ([]byte, error) {
+	mode, partial, continued := t.chunk, t.partial, t.continued
+	t.chunk, t.partial, t.continued = chunkNone, false, true
+	t.lastChunk = mode
+	t.space = t.space[:0]
+
+	const suffix = "]]>"
+	end, i := -1, t.cur
+	for end == -1 {
+		switch mode {
+		case chunkText:
+			if p := bytes.IndexByte(t.buf[i:], '<'); p != -1 {
+				end = i + p
+				continue
+			}
+		case chunkCDATA:
+			if p := bytes.Index(t.buf[i:], []byte(suffix)); p != -1 {
+				end = i + p + len(suffix)
+				continue
+			}
+		}
+		_, last := t.memmoveRemainingBytes(t.cur)
+		i = max(t.cur, last-len(suffix)+1)
+		if t.err = t.manageBuffer(); t.err != nil {
+			switch {
+			case t.isChunkable(t.err):
+				end = t.splitCharData(t.cur, mode)
+			case errors.Is(t.err, io.EOF) && mode == chunkText:
+				end = len(t.buf)
+			case errors.Is(t.err, io.EOF):
+				t.err = &TruncatedError{
+					Begin:      t.token.End,
+					Partial:    append([]byte(nil), t.buf[t.cur:]...),
+					Terminator: suffix,
+				}
+				return t.buf[t.cur:], t.err
+			case t.interrupted:
+				t.chunk, t.partial, t.continued = mode, partial, continued
+				return nil, t.err
+			default:
+				return nil, t.err
+			}
+		}
+	}
+
+	buf := t.buf[t.cur:end]
+	if !t.partial {
+		buf = TrimRightSpace(buf)
+	}
+	if t.options.illegalCharCheck {
+		if t.err = t.checkChars(buf); t.err != nil {
+			return nil, t.err
+		}
+	}
+	t.token.Begin = t.token.End
+	t.step(&t.token.End, buf)
+	t.advance(len(buf))
+	return buf, nil
+}
+
+// checkChars returns a SyntaxError positioned at the first character of b,
+// the raw token starting at t.buf[t.cur], that is not allowed in XML, see
+// WithIllegalCharCheck.
+func (t *Tokenizer) checkChars(b []byte) error {
+	for i := 0; i < len(b); i++ {
+		var r rune
+		switch c := b[i]; {
+		case c < 0x20 && c != '\t' && c != '\n' && c != '\r':
+			r = rune(c)
+		case c == 0xEF && i+2 < len(b) && b[i+1] == 0xBF && (b[i+2] == 0xBE || b[i+2] == 0xBF):
+			r = 0xFFFE + rune(b[i+2]-0xBE) // U+FFFE or U+FFFF
+		default:
+			continue
+		}
+		return t.syntaxErrorAt(fmt.Errorf("%U: %w", r, ErrIllegalChar), t.cur+i)
+	}
+	return nil
+}
+
+// advance moves the cursor n bytes forward, recording the bytes if needed.
+func (t *Tokenizer) advance(n int) {
+	if t.recording {
+		t.rec = append(t.rec, t.buf[t.cur:t.cur+n]...)
+	}
+	t.cur += n
+}
+
+// step advances p over b, counting the lines and columns as configured by
+// WithNewline and WithColumnUnit.
+func (t *Tokenizer) step(p *Pos, b []byte) {
+	if t.options.newline == NewlineLF && t.options.columnUnit == ColumnRunes {
+		p.step(b)
+		return
+	}
+	terminators := "\n"
+	if t.options.newline != NewlineLF {
+		terminators = "\r\n"
+	}
+	p.Offset += len(b)
+	for {
+		i := bytes.IndexAny(b, terminators)
+		if i == -1 {
+			if len(b) > 0 {
+				t.cr = false
+			}
+			p.Column += columns(b, t.options.columnUnit)
+			return
+		}
+		p.Column += columns(b[:i], t.options.columnUnit)
+		if !(t.options.newline == NewlineAny && b[i] == '\n' && i == 0 && t.cr) {
+			p.Line, p.Column = p.Line+1, 1
+		} // else the '\n' of "\r\n" which is already counted
+		t.cr = b[i] == '\r'
+		b = b[i+1:]
+	}
+}
+
+// columns returns the number of columns of b in the given unit.
+func columns(b []byte, unit ColumnUnit) int {
+	switch unit {
+	case ColumnBytes:
+		return len(b)
+	case ColumnUTF16:
+		n := 0
+		for len(b) > 0 {
+			r, size := utf8.DecodeRune(b)
+			if n++; r > 0xFFFF {
+				n++ // surrogate pair
+			}
+			b = b[size:]
+		}
+		return n
+	}
+	return utf8.RuneCount(b)
+}
+
+// record starts recording the consumed bytes, beginning with the
+// last token, so the byte-exact form of the subsequent tokens,
+// including anything in between, can be retrieved by stopRecording.
+func (t *Tokenizer) record() {
+	n := t.token.End.Offset - t.token.Begin.Offset
+	t.rec = append(t.rec[:0], t.buf[t.cur-n:t.cur]...)
+	t.recording = true
+}
+
+// stopRecording stops recording and returns the recorded bytes up to the end
+// of the last token's tag, the CharData following it is left out. The bytes
+// are only valid until the next record invocation.
+func (t *Tokenizer) stopRecording() []byte {
+	t.recording = false
+	n := t.token.End.Offset - t.token.Begin.Offset
+	if n > len(t.rec) {
+		return t.rec
+	}
+	return t.rec[:len(t.rec)-n+tagEnd(t.rec[len(t.rec)-n:])]
+}
+
+// tagEnd returns the index following the ">" ending the tag at the start of
+// raw, skipping the quoted attribute values, or len(raw) if there is none.
+func tagEnd(raw []byte) int {
+	var quote byte
+	for i, c := range raw {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '>':
+			return i + 1
+		}
+	}
+	return len(raw)
+}
+
 func (t *Tokenizer) memmoveRemainingBytes(pivot int) (cur, last int) {
 	if pivot == 0 {
 		return t.cur, len(t.buf)
 	}
+	if t.ra != nil {
+		// Rather than moving, the remaining bytes are read again by manageBuffer.
+		t.off += int64(pivot)
+		t.buf = t.buf[: len(t.buf)-pivot : cap(t.buf)]
+		t.stale = true
+		t.cur = 0
+		return t.cur, len(t.buf)
+	}
 	n := copy(t.buf, t.buf[pivot:])
 	t.buf = t.buf[:n:cap(t.buf)]
 	t.cur = 0
@@ -316,35 +1262,112 @@ func (t *Tokenizer) memmoveRemainingBytes(pivot int) (cur, last int) {
 }
 
 func (t *Tokenizer) manageBuffer() error {
-	growSize := len(t.buf) + t.options.readBufferSize
+	growSize := len(t.buf) + t.readSize()
 	start, end := len(t.buf), growSize
 	switch {
 	case growSize <= cap(t.buf): // Grow by reslice
 		t.buf = t.buf[:growSize:cap(t.buf)]
+	case t.options.fixedBuffer != nil: // Never grow, use what is left
+		if len(t.buf) == cap(t.buf) {
+			return fmt.Errorf("could not grow fixed buffer of %d bytes: %w",
+				cap(t.buf), ErrFixedBufferExceeded)
+		}
+		t.buf, end = t.buf[:cap(t.buf)], cap(t.buf)
 	default: // Grow by make new alloc
-		if growSize > t.options.autoGrowBufferMaxLimitSize {
-			return fmt.Errorf("could not grow buffer to %d, max limit is set to %d: %w",
-				growSize, t.options.autoGrowBufferMaxLimitSize, errAutoGrowBufferExceedMaxLimit)
+		size, err := t.growSize(cap(t.buf), growSize)
+		if err != nil {
+			return err
+		}
+		buf := make([]byte, size)
+		n := len(t.buf)
+		if !t.stale {
+			copy(buf, t.buf)
 		}
-		buf := make([]byte, growSize)
-		n := copy(buf, t.buf)
 		t.buf = buf
 		start, end = n, cap(t.buf)
+		if t.options.metrics != nil {
+			t.options.metrics.Grow(size)
+		}
+		t.event("xmltokenizer.grow", TraceAttr{"size", int64(size)}, TraceAttr{"needed", int64(growSize)})
+	}
+
+	held := start // bytes before held are not new
+	if max := t.options.maxInputBytes; max > 0 && int64(end-held) > max-t.read {
+		end = held + int(max-t.read) + 1 // read at most 1 byte beyond the limit to detect it
+	}
+	stale := t.stale
+	if t.stale {
+		start, t.stale = 0, false
 	}
 
-	n, err := io.ReadAtLeast(t.r, t.buf[start:end], 1)
+	var n int
+	var err error
+	if t.ra != nil {
+		n, err = t.ra.ReadAt(t.buf[start:end], t.off+int64(start))
+		if start+n > held {
+			err = nil // only fail when there is no new byte
+		}
+	} else {
+		n, err = io.ReadAtLeast(t.r, t.buf[start:end], 1)
+	}
+	if err != nil && err != io.EOF {
+		// Keep the state as it was before the read, see Resume.
+		t.buf, t.stale, t.interrupted = t.buf[:held:cap(t.buf)], stale, true
+		return err
+	}
 	t.buf = t.buf[: start+n : cap(t.buf)]
+	if fresh := start + n - held; fresh > 0 {
+		t.read += int64(fresh)
+		if t.options.metrics != nil {
+			t.options.metrics.Read(fresh)
+		}
+	}
+	if max := t.options.maxInputBytes; max > 0 && t.read > max {
+		return fmt.Errorf("could not read more than %d bytes: %w", max, ErrMaxInputBytesExceeded)
+	}
 	return err
 }
 
+// growSize returns the new buffer size that is at least the needed size.
+func (t *Tokenizer) growSize(current, needed int) (int, error) {
+	if t.options.growPolicy != nil {
+		size, err := t.options.growPolicy(current, needed)
+		if err != nil {
+			return 0, err
+		}
+		if size < needed {
+			return 0, fmt.Errorf("could not grow buffer to %d, policy returns %d: %w",
+				needed, size, errGrowPolicyInsufficientSize)
+		}
+		return size, nil
+	}
+	if needed > t.options.autoGrowBufferMaxLimitSize {
+		return 0, fmt.Errorf("could not grow buffer to %d, max limit is set to %d: %w",
+			needed, t.options.autoGrowBufferMaxLimitSize, errAutoGrowBufferExceedMaxLimit)
+	}
+	// Grow geometrically to amortize the cost of copying large tokens.
+	size := 2 * current
+	if size < needed {
+		size = needed
+	}
+	if size > t.options.autoGrowBufferMaxLimitSize {
+		size = t.options.autoGrowBufferMaxLimitSize
+	}
+	return size, nil
+}
+
 func (t *Tokenizer) clearToken() {
 	t.token.Name.Prefix = nil
 	t.token.Name.Local = nil
 	t.token.Name.Full = nil
+	t.token.Name.Space = nil
 	t.token.Attrs = t.token.Attrs[:0]
 	t.token.Data = nil
 	t.token.SelfClosing = false
 	t.token.IsEndElement = false
+	t.token.Continued = false
+	t.kind, t.cdata = 0, false
+	t.escaped, t.escapedVals = nil, t.escapedVals[:0]
 }
 
 // consumeNonTagIdentifier consumes identifier starts with "<?" or "<!", make it raw data.
@@ -354,111 +1377,245 @@ func (t *Tokenizer) consumeNonTagIdentifier(b []byte) []byte {
 	}
 	t.token.Data = b
 	t.token.SelfClosing = true
+	t.kind = markupKind(b)
 	return nil
 }
 
 func (t *Tokenizer) consumeTagName(b []byte) []byte {
 	b = b[1:]
+	t.kind = StartElement
 	if b[0] == '/' {
-		t.token.IsEndElement = true
+		t.token.IsEndElement, t.kind = true, EndElement
 		b = b[1:]
 	}
 	pos := bytes.IndexAny(b, "> \t\r\n")
 	if b[pos] == '>' && len(b) > 1 && b[pos-1] == '/' {
 		pos--
 	}
-	t.token.Name.Full = trim(b[:pos])
+	t.token.Name.Full = TrimSpace(b[:pos])
 	b = b[pos:]
-	pos = bytes.IndexByte(t.token.Name.Full, ':')
-	if pos == -1 {
-		t.token.Name.Local = t.token.Name.Full
-	} else {
-		t.token.Name.Prefix = t.token.Name.Full[:pos]
-		t.token.Name.Local = t.token.Name.Full[pos+1:]
-	}
+	t.token.Name.Prefix, t.token.Name.Local = SplitQName(t.token.Name.Full)
 	return b
 }
 
 func (t *Tokenizer) consumeAttrs(b []byte) []byte {
 	for {
-		pos := bytes.IndexAny(b, "=>")
-		if b[pos] == '>' {
-			if pos > 0 && b[pos-1] == '/' {
-				t.token.SelfClosing = true
+		attr, n, ok := nextAttr(b)
+		if !ok {
+			if n == -1 {
+				return nil
+			}
+			if n > 0 && b[n-1] == '/' {
+				t.token.SelfClosing, t.kind = true, SelfClosing
 			}
-			return b[pos+1:]
+			return b[n+1:]
+		}
+		if t.options.fixedBuffer != nil && len(t.token.Attrs) == cap(t.token.Attrs) {
+			t.err = fmt.Errorf("could not hold more than %d attributes: %w",
+				cap(t.token.Attrs), ErrFixedBufferExceeded)
+			return nil
 		}
-		full := trim(b[:pos])
-		b = b[pos+1:]
-		pos = bytes.IndexAny(b, "'\"")
-		width := bytes.IndexByte(b[pos+1:], b[pos])
-		value := b[pos+1 : pos+width+1]
-		b = b[pos+width+2:]
-		colon := bytes.IndexByte(full, ':')
-		var prefix, local []byte
-		if colon == -1 {
-			local = full
-		} else {
-			prefix = full[:colon]
-			local = full[colon+1:]
+		attr.Index = len(t.token.Attrs)
+		t.token.Attrs = append(t.token.Attrs, attr)
+		b = b[n:]
+	}
+}
+
+// nextAttr parses the next attribute of b, the bytes of a tag following its
+// name, returning the attribute and the number of bytes it consumes. When
+// there is no more attribute, ok is false and n is the index of the closing
+// '>' of the tag, or -1 if b is malformed.
+func nextAttr(b []byte) (attr Attr, n int, ok bool) {
+	pos := bytes.IndexAny(b, "=>")
+	if pos == -1 {
+		return attr, -1, false
+	}
+	if b[pos] == '>' {
+		return attr, pos, false
+	}
+	full := TrimSpace(b[:pos])
+	n = pos + 1
+	pos = bytes.IndexAny(b[n:], "'\"")
+	if pos == -1 {
+		return attr, -1, false
+	}
+	n += pos
+	width := bytes.IndexByte(b[n+1:], b[n])
+	if width == -1 {
+		return attr, -1, false
+	}
+	value := b[n+1 : n+width+1]
+	n += width + 2
+	prefix, local := SplitQName(full)
+	return Attr{
+		Name:  Name{Prefix: prefix, Local: local, Full: full},
+		Value: value,
+	}, n, true
+}
+
+// foldNames lowercases the names of the token into t.fold, leaving the buffer
+// untouched so RawToken and Raw still return the bytes as they are read.
+func (t *Tokenizer) foldNames() {
+	n := len(t.token.Name.Full)
+	for i := range t.token.Attrs {
+		n += len(t.token.Attrs[i].Name.Full)
+	}
+	if cap(t.fold) < n {
+		t.fold = make([]byte, 0, n) // names must not be moved by append
+	}
+	t.fold = t.fold[:0]
+	t.token.Name = t.foldName(t.token.Name)
+	for i := range t.token.Attrs {
+		t.token.Attrs[i].Name = t.foldName(t.token.Attrs[i].Name)
+	}
+}
+
+func (t *Tokenizer) foldName(name Name) Name {
+	if bytes.IndexFunc(name.Full, isASCIIUpper) == -1 {
+		return name
+	}
+	start := len(t.fold)
+	for _, c := range name.Full {
+		if 'A' <= c && c <= 'Z' {
+			c += 'a' - 'A'
 		}
-		t.token.Attrs = append(t.token.Attrs, Attr{
-			Name:  Name{Prefix: prefix, Local: local, Full: full},
-			Value: value,
-		})
+		t.fold = append(t.fold, c)
 	}
+	full := t.fold[start:len(t.fold):len(t.fold)]
+	folded := Name{Local: full[len(full)-len(name.Local):], Full: full}
+	if name.Prefix != nil {
+		folded.Prefix = full[:len(name.Prefix)]
+	}
+	return folded
 }
 
+func isASCIIUpper(r rune) bool { return 'A' <= r && r <= 'Z' }
+
 func (t *Tokenizer) consumeCharData(b []byte) {
 	const prefix, suffix = "<![CDATA[", "]]>"
-	b = trimPrefix(b)
+	b = TrimLeftSpace(b)
 	if len(b) >= len(prefix) && string(b[:len(prefix)]) == prefix {
-		b = b[len(prefix):]
+		b, t.cdata = b[len(prefix):], true
+	}
+	if t.partial {
+		t.token.Data = TrimLeftSpace(b)
+		return
 	}
 	if end := len(b) - len(suffix); end >= 0 && string(b[end:]) == suffix {
 		b = b[:end]
 	}
-	t.token.Data = trim(b)
+	t.token.Data = TrimSpace(b)
+}
+
+// consumeCharDataContinuation consumes a chunk of a CharData or CDATA that
+// has been split into multiple tokens.
+func (t *Tokenizer) consumeCharDataContinuation(b []byte) {
+	const suffix = "]]>"
+	t.token.Continued, t.kind = true, CharData
+	if t.lastChunk == chunkCDATA {
+		t.kind, t.cdata = CDATA, true
+	}
+	if t.partial {
+		t.token.Data = b
+		return
+	}
+	if end := len(b) - len(suffix); end >= 0 && string(b[end:]) == suffix {
+		b = b[:end]
+	}
+	t.token.Data = TrimRightSpace(b)
+}
+
+// SplitQName splits the qualified name b into its prefix and local part at
+// the first colon, the same way the Tokenizer splits the names of elements
+// and attributes. The prefix is nil when b has no colon.
+func SplitQName(b []byte) (prefix, local []byte) {
+	if i := bytes.IndexByte(b, ':'); i != -1 {
+		return b[:i], b[i+1:]
+	}
+	return nil, b
 }
 
-func trim(b []byte) []byte {
-	b = trimPrefix(b)
-	b = trimSuffix(b)
+// TrimSpace returns b without its leading and trailing whitespace, the same
+// way the Tokenizer trims names and CharData: whitespace is ' ', '\t', '\n'
+// and "\r\n", see TrimLeftSpace and TrimRightSpace.
+func TrimSpace(b []byte) []byte {
+	b = TrimLeftSpace(b)
+	b = TrimRightSpace(b)
 	return b
 }
 
-func trimPrefix(b []byte) []byte {
-	var start int
-	for i := 0; i < len(b); i++ {
+// TrimLeftSpace returns b without its leading whitespace, the same way the
+// Tokenizer trims the beginning of CharData.
+func TrimLeftSpace(b []byte) []byte {
+	if len(b) == 0 || b[0] > ' ' { // no whitespace byte is above ' '
+		return b
+	}
+	return trimLeftSpace(b)
+}
+
+func trimLeftSpace(b []byte) []byte {
+	i := 0
+	for i < len(b) {
 		switch b[i] {
+		case '\n':
+			i++
+			// Indentation, if any, follows a newline: skip it a word at a time.
+			for len(b)-i >= 8 && isSpaceWord(binary.LittleEndian.Uint64(b[i:])) {
+				i += 8
+			}
+		case ' ', '\t':
+			i++
 		case '\r':
 			if i+1 < len(b) && b[i+1] == '\n' {
-				start += 2
 				i++
+				continue
 			}
-		case '\n', ' ', '\t':
-			start++
+			return b[i:]
 		default:
-			return b[start:]
+			return b[i:]
 		}
 	}
-	return b[start:]
+	return b[i:]
 }
 
-func trimSuffix(b []byte) []byte {
-	var end int = len(b)
-	for i := len(b) - 1; i >= 0; i-- {
-		switch b[i] {
+// TrimRightSpace returns b without its trailing whitespace, the same way the
+// Tokenizer trims the end of CharData.
+func TrimRightSpace(b []byte) []byte {
+	if len(b) == 0 || b[len(b)-1] > ' ' {
+		return b
+	}
+	return trimRightSpace(b)
+}
+
+func trimRightSpace(b []byte) []byte {
+	i := len(b)
+	for i >= 8 && isSpaceWord(binary.LittleEndian.Uint64(b[i-8:])) {
+		i -= 8
+	}
+	for i > 0 {
+		switch b[i-1] {
 		case '\n':
-			end--
-			if i-1 > 0 && b[i-1] == '\r' {
-				end--
+			i--
+			for i >= 8 && isSpaceWord(binary.LittleEndian.Uint64(b[i-8:])) {
+				i -= 8
 			}
 		case ' ', '\t':
-			end--
+			i--
+		case '\r':
+			if i < len(b) && b[i] == '\n' {
+				i--
+				continue
+			}
+			return b[:i]
 		default:
-			return b[:end]
+			return b[:i]
 		}
 	}
-	return b[:end]
+	return b[:i]
+}
+
+// isSpaceWord reports whether the 8 bytes of w are either all ' ' or all
+// '\t', as indentation usually is, so it is skipped a word at a time.
+func isSpaceWord(w uint64) bool {
+	return w == 0x2020202020202020 || w == 0x0909090909090909
 }
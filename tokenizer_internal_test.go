@@ -1,6 +1,7 @@
 package xmltokenizer
 
 import (
+	"bytes"
 	"errors"
 	"io"
 	"os"
@@ -111,3 +112,191 @@ func TestAutoGrowBuffer(t *testing.T) {
 		})
 	}
 }
+
+func TestManageBufferGrowth(t *testing.T) {
+	tt := []struct {
+		name        string
+		opts        []Option
+		cap         int
+		expectedCap int
+	}{
+		{
+			name:        "double the capacity",
+			opts:        []Option{WithReadBufferSize(1)},
+			cap:         10,
+			expectedCap: 20,
+		},
+		{
+			name:        "capped at max limit",
+			opts:        []Option{WithReadBufferSize(1), WithAutoGrowBufferMaxLimitSize(15)},
+			cap:         10,
+			expectedCap: 15,
+		},
+		{
+			name:        "read buffer size larger than double",
+			opts:        []Option{WithReadBufferSize(30)},
+			cap:         10,
+			expectedCap: 40,
+		},
+		{
+			name: "grow policy",
+			opts: []Option{
+				WithReadBufferSize(1),
+				WithGrowPolicy(func(current, needed int) (int, error) { return needed + 5, nil }),
+			},
+			cap:         10,
+			expectedCap: 16,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tok := New(io.MultiReader(bytes.NewReader([]byte("<a/>"))), tc.opts...) // hide io.ReaderAt
+			tok.buf = make([]byte, tc.cap)
+			if err := tok.manageBuffer(); err != nil {
+				t.Fatalf("expected nil, got: %v", err)
+			}
+			if cap(tok.buf) != tc.expectedCap {
+				t.Fatalf("expected cap: %d, got: %d", tc.expectedCap, cap(tok.buf))
+			}
+		})
+	}
+}
+
+func TestGrowPolicyError(t *testing.T) {
+	errQuota := errors.New("quota exceeded")
+	tt := []struct {
+		name   string
+		policy func(current, needed int) (int, error)
+		err    error
+	}{
+		{
+			name:   "policy returns error",
+			policy: func(current, needed int) (int, error) { return 0, errQuota },
+			err:    errQuota,
+		},
+		{
+			name:   "policy returns insufficient size",
+			policy: func(current, needed int) (int, error) { return needed - 1, nil },
+			err:    errGrowPolicyInsufficientSize,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tok := New(bytes.NewReader([]byte("<a/>")), WithGrowPolicy(tc.policy))
+			tok.buf = make([]byte, 10)
+			if err := tok.manageBuffer(); !errors.Is(err, tc.err) {
+				t.Fatalf("expected error: %v, got: %v", tc.err, err)
+			}
+		})
+	}
+}
+
+type closeRecorder struct {
+	io.Reader
+	closed bool
+}
+
+func (c *closeRecorder) Close() error { c.closed = true; return nil }
+
+func TestClose(t *testing.T) {
+	tt := []struct {
+		name           string
+		opts           []Option
+		expectedClosed bool
+	}{
+		{
+			name:           "reader is not closed",
+			expectedClosed: false,
+		},
+		{
+			name:           "reader is closed",
+			opts:           []Option{WithCloseReader()},
+			expectedClosed: true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			r := &closeRecorder{Reader: bytes.NewReader([]byte(`<a x="1">text</a>`))}
+			tok := New(r, tc.opts...)
+			if _, err := tok.Token(); err != nil {
+				t.Fatalf("expected nil, got: %v", err)
+			}
+			if err := tok.Close(); err != nil {
+				t.Fatalf("expected nil, got: %v", err)
+			}
+			if r.closed != tc.expectedClosed {
+				t.Fatalf("expected closed: %t, got: %t", tc.expectedClosed, r.closed)
+			}
+			if tok.buf != nil || tok.token.Attrs != nil {
+				t.Fatalf("expected buffers to be released")
+			}
+			if _, err := tok.Token(); !errors.Is(err, errClosed) {
+				t.Fatalf("expected error: %v, got: %v", errClosed, err)
+			}
+			if err := tok.Close(); err != nil {
+				t.Fatalf("expected nil on second close, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestPutAttrsClearsReferences(t *testing.T) {
+	attrs := []Attr{{Name: Name{Full: []byte("a")}, Value: []byte("b")}}
+	putAttrs(attrs)
+	if attrs[0].Name.Full != nil || attrs[0].Value != nil {
+		t.Fatalf("expected attrs to be cleared, got: %v", attrs[0])
+	}
+}
+
+func TestResetMaxRetainedBuffer(t *testing.T) {
+	huge := "<a>" + string(bytes.Repeat([]byte("x"), 64<<10)) + "</a>"
+
+	tt := []struct {
+		name        string
+		opts        []Option
+		expectedCap int
+	}{
+		{
+			name:        "retained by default",
+			expectedCap: 64 << 10,
+		},
+		{
+			name:        "released when exceeding max retained",
+			opts:        []Option{WithMaxRetainedBuffer(16 << 10)},
+			expectedCap: -(16 << 10),
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tok := New(io.MultiReader(bytes.NewReader([]byte(huge))), tc.opts...)
+			for {
+				if _, err := tok.Token(); err != nil {
+					break
+				}
+			}
+			tok.Reset(bytes.NewReader([]byte("<a/>")), tc.opts...)
+			switch c := cap(tok.buf); {
+			case tc.expectedCap > 0 && c < tc.expectedCap:
+				t.Fatalf("expected cap >= %d, got: %d", tc.expectedCap, c)
+			case tc.expectedCap < 0 && c > -tc.expectedCap:
+				t.Fatalf("expected cap <= %d, got: %d", -tc.expectedCap, c)
+			}
+			if token, err := tok.Token(); err != nil || string(token.Name.Full) != "a" {
+				t.Fatalf("expected a, got: %s (%v)", token.Name.Full, err)
+			}
+		})
+	}
+}
+
+func TestResetAfterFixedBuffer(t *testing.T) {
+	fixed := make([]byte, 64<<10)
+	tok := New(bytes.NewReader([]byte("<a/>")), WithFixedBuffer(fixed))
+	tok.Reset(bytes.NewReader([]byte("<b/>")))
+	if cap(tok.buf) == cap(fixed) && &tok.buf[:1][0] == &fixed[0] {
+		t.Fatalf("expected the fixed buffer not to be reused")
+	}
+}
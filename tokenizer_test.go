@@ -11,12 +11,14 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"unicode/utf8"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/muktihari/xmltokenizer"
 	"github.com/muktihari/xmltokenizer/internal/gpx"
 	"github.com/muktihari/xmltokenizer/internal/xlsx"
 	"github.com/muktihari/xmltokenizer/internal/xlsx/schema"
+	"github.com/muktihari/xmltokenizer/xmltest"
 )
 
 var tokenHeader = xmltokenizer.Token{
@@ -69,8 +71,8 @@ func TestTokenWithInmemXML(t *testing.T) {
 					Name: xmltokenizer.Name{Local: []byte("body"), Full: []byte("body")},
 					Attrs: []xmltokenizer.Attr{
 						{Name: xmltokenizer.Name{Prefix: []byte("xmlns"), Local: []byte("foo"), Full: []byte("xmlns:foo")}, Value: []byte("ns1")},
-						{Name: xmltokenizer.Name{Local: []byte("xmlns"), Full: []byte("xmlns")}, Value: []byte("ns2")},
-						{Name: xmltokenizer.Name{Prefix: []byte("xmlns"), Local: []byte("tag"), Full: []byte("xmlns:tag")}, Value: []byte("ns3")},
+						{Name: xmltokenizer.Name{Local: []byte("xmlns"), Full: []byte("xmlns")}, Value: []byte("ns2"), Index: 1},
+						{Name: xmltokenizer.Name{Prefix: []byte("xmlns"), Local: []byte("tag"), Full: []byte("xmlns:tag")}, Value: []byte("ns3"), Index: 2},
 					},
 					Begin: xmltokenizer.Pos{5, 1, 163},
 					End:   xmltokenizer.Pos{6, 5, 219},
@@ -112,7 +114,7 @@ func TestTokenWithInmemXML(t *testing.T) {
 					Name: xmltokenizer.Name{Local: []byte("outer"), Full: []byte("outer")},
 					Attrs: []xmltokenizer.Attr{
 						{Name: xmltokenizer.Name{Prefix: []byte("foo"), Local: []byte("attr"), Full: []byte("foo:attr")}, Value: []byte("value")},
-						{Name: xmltokenizer.Name{Prefix: []byte("xmlns"), Local: []byte("tag"), Full: []byte("xmlns:tag")}, Value: []byte("ns4")},
+						{Name: xmltokenizer.Name{Prefix: []byte("xmlns"), Local: []byte("tag"), Full: []byte("xmlns:tag")}, Value: []byte("ns4"), Index: 1},
 					},
 					Begin: xmltokenizer.Pos{10, 2, 337},
 					End:   xmltokenizer.Pos{10, 42, 377},
@@ -180,7 +182,7 @@ func TestTokenWithInmemXML(t *testing.T) {
 				},
 				{
 					Name:  xmltokenizer.Name{Local: []byte("a"), Full: []byte("a")},
-					Attrs: []xmltokenizer.Attr{{xmltokenizer.Name{Local: []byte{}, Full: []byte{}}, []byte("ns2")}},
+					Attrs: []xmltokenizer.Attr{{Name: xmltokenizer.Name{Local: []byte{}, Full: []byte{}}, Value: []byte("ns2")}},
 					Begin: xmltokenizer.Pos{1, 39, 38},
 					End:   xmltokenizer.Pos{1, 49, 48},
 				},
@@ -212,6 +214,7 @@ func TestTokenWithInmemXML(t *testing.T) {
 						{
 							Name:  xmltokenizer.Name{Local: []uint8("URL2"), Full: []uint8("URL2")},
 							Value: []uint8("https://ok.com"),
+							Index: 1,
 						},
 					},
 					SelfClosing: true,
@@ -287,6 +290,7 @@ func TestTokenWithInmemXML(t *testing.T) {
 								Local: []uint8("baz"),
 								Full:  []uint8("baz")},
 							Value: []uint8("quux"),
+							Index: 1,
 						},
 					},
 					SelfClosing: true,
@@ -316,6 +320,7 @@ func TestTokenWithInmemXML(t *testing.T) {
 								Local: []uint8("baz"),
 								Full:  []uint8("baz")},
 							Value: []uint8("\"quux\""),
+							Index: 1,
 						},
 					},
 					SelfClosing: true,
@@ -358,6 +363,33 @@ func TestTokenWithInmemXML(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "right angle bracket inside mixed quoted attribute values",
+			xml:  `<sample a="x>'y" b='>"' c="z">`,
+			expecteds: []xmltokenizer.Token{
+				{
+					Name: xmltokenizer.Name{Local: []byte("sample"), Full: []byte("sample")},
+					Attrs: []xmltokenizer.Attr{
+						{
+							Name:  xmltokenizer.Name{Local: []uint8("a"), Full: []uint8("a")},
+							Value: []uint8("x>'y"),
+						},
+						{
+							Name:  xmltokenizer.Name{Local: []uint8("b"), Full: []uint8("b")},
+							Value: []uint8(">\""),
+							Index: 1,
+						},
+						{
+							Name:  xmltokenizer.Name{Local: []uint8("c"), Full: []uint8("c")},
+							Value: []uint8("z"),
+							Index: 2,
+						},
+					},
+					Begin: xmltokenizer.Pos{1, 1, 0},
+					End:   xmltokenizer.Pos{1, 31, 30},
+				},
+			},
+		},
 		{
 			name: "right angle bracket inside comment",
 			xml:  `<!-->--><!-- foo>bar>baz -->`,
@@ -770,23 +802,11 @@ func TestTokenOnGPXFiles(t *testing.T) {
 				t.Skip(err)
 			}
 
-			gpx1, err := gpx.UnmarshalWithXMLTokenizer(bytes.NewReader(data))
-			if err != nil {
-				t.Fatalf("xmltokenizer: %v", err)
-			}
-
-			gpx2, err := gpx.UnmarshalWithStdlibXML(bytes.NewReader(data))
-			if err != nil {
-				t.Fatalf("xml: %v", err)
-			}
-
-			if diff := cmp.Diff(gpx1, gpx2,
+			xmltest.AssertEquivalent(t, data, gpx.UnmarshalWithXMLTokenizer, gpx.UnmarshalWithStdlibXML,
 				cmp.Transformer("float64", func(x float64) uint64 {
 					return math.Float64bits(x)
 				}),
-			); diff != "" {
-				t.Fatal(diff)
-			}
+			)
 		})
 
 		return nil
@@ -801,18 +821,7 @@ func TestTokenOnXLSXFiles(t *testing.T) {
 		t.Skip(err)
 	}
 
-	sheet1, err := xlsx.UnmarshalWithXMLTokenizer(bytes.NewReader(data))
-	if err != nil {
-		t.Fatalf("xmltokenizer: %v", err)
-	}
-	sheet2, err := xlsx.UnmarshalWithStdlibXML(bytes.NewReader(data))
-	if err != nil {
-		t.Fatalf("xml: %v", err)
-	}
-
-	if diff := cmp.Diff(sheet1, sheet2); diff != "" {
-		t.Fatal(diff)
-	}
+	xmltest.AssertEquivalent(t, data, xlsx.UnmarshalWithXMLTokenizer, xlsx.UnmarshalWithStdlibXML)
 }
 
 func TestAutoGrowBufferCorrectness(t *testing.T) {
@@ -962,3 +971,721 @@ func TestRawTokenWithInmemXML(t *testing.T) {
 		_ = token
 	})
 }
+
+func TestChunkedCharData(t *testing.T) {
+	text := strings.Repeat("lorem ipsum dolor sit amet 白鵬翔 ]]", 300)
+	tt := []struct {
+		name     string
+		xml      string
+		expected string
+		err      error
+	}{
+		{
+			name:     "chardata",
+			xml:      "<a>\n  " + text + "\n</a>",
+			expected: text,
+		},
+		{
+			name:     "cdata",
+			xml:      "<a>\n  <![CDATA[" + text + "<b></b>]]>\n</a>",
+			expected: text + "<b></b>",
+		},
+		{
+			name:     "chardata at EOF",
+			xml:      "<a>" + text,
+			expected: text,
+		},
+		{
+			name: "truncated cdata",
+			xml:  "<a><![CDATA[" + text,
+			err:  io.ErrUnexpectedEOF,
+		},
+	}
+
+	for i, tc := range tt {
+		t.Run(fmt.Sprintf("[%d]: %s", i, tc.name), func(t *testing.T) {
+			tok := xmltokenizer.New(strings.NewReader(tc.xml),
+				xmltokenizer.WithReadBufferSize(8),
+				xmltokenizer.WithAutoGrowBufferMaxLimitSize(32),
+				xmltokenizer.WithChunkedCharData(),
+			)
+
+			var data []byte
+			var chunks int
+			for {
+				token, err := tok.Token()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					if !errors.Is(err, tc.err) {
+						t.Fatalf("expected error: %v, got: %v", tc.err, err)
+					}
+					return
+				}
+				if !utf8.Valid(token.Data) {
+					t.Fatalf("chunk #%d is not a valid UTF-8: %q", chunks, token.Data)
+				}
+				if string(token.Name.Local) == "a" && !token.IsEndElement || token.Continued {
+					data = append(data, token.Data...)
+					chunks++
+				}
+			}
+			if tc.err != nil {
+				t.Fatalf("expected error: %v, got: nil", tc.err)
+			}
+			if chunks < 2 {
+				t.Fatalf("expected chardata to be chunked, got: %d chunk", chunks)
+			}
+			if diff := cmp.Diff(string(data), tc.expected); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
+
+func TestMaxInputBytes(t *testing.T) {
+	const xml = `<a><b>text</b></a>`
+	tt := []struct {
+		name string
+		max  int64
+		err  error
+	}{
+		{name: "no limit", max: 0, err: nil},
+		{name: "exactly the input size", max: int64(len(xml)), err: nil},
+		{name: "less than the input size", max: int64(len(xml) - 1), err: xmltokenizer.ErrMaxInputBytesExceeded},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tok := xmltokenizer.New(strings.NewReader(xml),
+				xmltokenizer.WithReadBufferSize(2),
+				xmltokenizer.WithMaxInputBytes(tc.max),
+			)
+			var err error
+			for {
+				if _, err = tok.Token(); err != nil {
+					break
+				}
+			}
+			if err == io.EOF {
+				err = nil
+			}
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expected error: %v, got: %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestSpaceAndRaw(t *testing.T) {
+	inputs := []string{
+		"\ufeff<?xml version=\"1.0\"?>\n<a x='1'>\n  text\n  <b/>tail\n  <c><![CDATA[ <d> ]]></c>\n</a>\n<!-- end -->\n",
+		"<a>\n  <b>text</b>\n</a>",
+	}
+	for i, bufferSize := range []int{1, 2, 3, 4096, 1, 4096} {
+		xml := inputs[i/4]
+		t.Run(fmt.Sprintf("input %d buffer size %d", i/4, bufferSize), func(t *testing.T) {
+			tok := xmltokenizer.New(strings.NewReader(xml),
+				xmltokenizer.WithReadBufferSize(bufferSize),
+				xmltokenizer.WithSpace(),
+			)
+			var sb strings.Builder
+			for {
+				_, err := tok.Token()
+				sb.Write(tok.Space())
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				sb.Write(tok.Raw())
+			}
+			if diff := cmp.Diff(sb.String(), xml); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+
+	t.Run("without WithSpace", func(t *testing.T) {
+		tok := xmltokenizer.New(strings.NewReader(" <a/>"))
+		if _, err := tok.Token(); err != nil {
+			t.Fatal(err)
+		}
+		if space := tok.Space(); space != nil {
+			t.Fatalf("expected nil, got: %q", space)
+		}
+		if raw := string(tok.Raw()); raw != "<a/>" {
+			t.Fatalf("expected: %q, got: %q", "<a/>", raw)
+		}
+	})
+}
+
+func TestCaseFold(t *testing.T) {
+	const xml = `<Feed><ITEM ID="A1" Xlink:HREF="X">Text</ITEM><item/></Feed>`
+	tok := xmltokenizer.New(strings.NewReader(xml), xmltokenizer.WithCaseFold(), xmltokenizer.WithSpace())
+
+	var names, raws []string
+	for {
+		token, err := tok.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		name := string(token.Name.Full)
+		for _, attr := range token.Attrs {
+			name += fmt.Sprintf(" %s|%s|%s=%s", attr.Name.Prefix, attr.Name.Local, attr.Name.Full, attr.Value)
+		}
+		names = append(names, name+" "+string(token.Data))
+		raws = append(raws, string(tok.Raw()))
+	}
+
+	expectedNames := []string{
+		"feed ",
+		"item |id|id=A1 xlink|href|xlink:href=X Text",
+		"item ",
+		"item ",
+		"feed ",
+	}
+	if diff := cmp.Diff(names, expectedNames); diff != "" {
+		t.Fatal(diff)
+	}
+	if raw := strings.Join(raws, ""); raw != xml {
+		t.Fatalf("expected raw: %q, got: %q", xml, raw)
+	}
+}
+
+func TestNewlineAndColumnUnit(t *testing.T) {
+	const xml = "<a>\r\n<b>é😀</b>\r<c/>\n</a>"
+
+	tt := []struct {
+		name     string
+		opts     []xmltokenizer.Option
+		expected []string
+	}{
+		{
+			name:     "default",
+			expected: []string{"1:1", "2:1", "2:6", "2:11", "3:1"},
+		},
+		{
+			name:     "any newline",
+			opts:     []xmltokenizer.Option{xmltokenizer.WithNewline(xmltokenizer.NewlineAny)},
+			expected: []string{"1:1", "2:1", "2:6", "3:1", "4:1"},
+		},
+		{
+			name:     "cr and lf",
+			opts:     []xmltokenizer.Option{xmltokenizer.WithNewline(xmltokenizer.NewlineCRAndLF)},
+			expected: []string{"1:1", "3:1", "3:6", "4:1", "5:1"},
+		},
+		{
+			name:     "utf16",
+			opts:     []xmltokenizer.Option{xmltokenizer.WithColumnUnit(xmltokenizer.ColumnUTF16)},
+			expected: []string{"1:1", "2:1", "2:7", "2:12", "3:1"},
+		},
+		{
+			name: "bytes and any newline",
+			opts: []xmltokenizer.Option{
+				xmltokenizer.WithColumnUnit(xmltokenizer.ColumnBytes),
+				xmltokenizer.WithNewline(xmltokenizer.NewlineAny),
+			},
+			expected: []string{"1:1", "2:1", "2:10", "3:1", "4:1"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tok := xmltokenizer.New(strings.NewReader(xml), tc.opts...)
+			var positions []string
+			var offset int
+			for {
+				token, err := tok.Token()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				positions = append(positions, fmt.Sprintf("%d:%d", token.Begin.Line, token.Begin.Column))
+				offset = token.End.Offset
+			}
+			if diff := cmp.Diff(positions, tc.expected); diff != "" {
+				t.Fatal(diff)
+			}
+			if offset != len(xml) {
+				t.Fatalf("expected offset: %d, got: %d", len(xml), offset)
+			}
+		})
+	}
+}
+
+func TestReaderAt(t *testing.T) {
+	// Tokens read at offsets must be the same as the ones read sequentially.
+	filenames := []string{"dtd.xml", "long_comment_token.xml", "hike_mt_prau.gpx", "cdata.xml"}
+	for _, filename := range filenames {
+		t.Run(filename, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join("testdata", filename))
+			if err != nil {
+				panic(err)
+			}
+			opts := []xmltokenizer.Option{xmltokenizer.WithReadBufferSize(7)}
+			seq, err := xmltest.TokenizeString(string(data), opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tok := xmltokenizer.New(io.MultiReader(bytes.NewReader(data)), opts...) // hide io.ReaderAt
+			for i := 0; ; i++ {
+				token, err := tok.Token()
+				if err == io.EOF {
+					if i != len(seq) {
+						t.Fatalf("expected len: %d, got: %d", len(seq), i)
+					}
+					break
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				if diff := seq[i].Diff(token); diff != "" {
+					t.Fatalf("[%d] %s", i, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckpointRestore(t *testing.T) {
+	const xml = "<a>\n  <b x=\"1\">one</b>\n  <c>two</c>\n</a>"
+	f, err := os.CreateTemp(t.TempDir(), "*.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString("junk" + xml); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Seek(4, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+
+	tok := xmltokenizer.New(f, xmltokenizer.WithReadBufferSize(3))
+	token, err := tok.Token()
+	if err != nil || string(token.Name.Full) != "a" {
+		t.Fatalf("expected a, got: %s (%v)", token.Name.Full, err)
+	}
+	cp := tok.Checkpoint()
+
+	var first []string
+	for {
+		token, err := tok.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		first = append(first, fmt.Sprintf("%s %s %d:%d", token.Name.Full, token.Data, token.Begin.Line, token.Begin.Column))
+	}
+
+	if err := tok.Restore(cp); err != nil {
+		t.Fatalf("expected nil, got: %v", err)
+	}
+	var second []string
+	for {
+		token, err := tok.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		second = append(second, fmt.Sprintf("%s %s %d:%d", token.Name.Full, token.Data, token.Begin.Line, token.Begin.Column))
+	}
+	if diff := cmp.Diff(first, second); diff != "" {
+		t.Fatal(diff)
+	}
+	if offset, _ := f.Seek(0, io.SeekCurrent); offset != 4 {
+		t.Fatalf("expected file offset to be untouched: 4, got: %d", offset)
+	}
+
+	tok = xmltokenizer.New(io.MultiReader(strings.NewReader(xml)))
+	if err := tok.Restore(tok.Checkpoint()); err == nil {
+		t.Fatalf("expected error, got nil")
+	}
+}
+
+// repeatReader reads b over and over again.
+type repeatReader struct {
+	b []byte
+	i int
+}
+
+func (r *repeatReader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		c := copy(p[n:], r.b[r.i:])
+		n += c
+		r.i = (r.i + c) % len(r.b)
+	}
+	return n, nil
+}
+
+func TestFixedBuffer(t *testing.T) {
+	t.Run("no allocation", func(t *testing.T) {
+		r := &repeatReader{b: []byte("<row r=\"1\" spans=\"1:3\"><c r=\"A1\" t=\"s\"><v>0</v></c></row>\n")}
+		tok := xmltokenizer.New(r, xmltokenizer.WithFixedBuffer(make([]byte, 64)), xmltokenizer.WithReadBufferSize(16))
+		alloc := testing.AllocsPerRun(1000, func() {
+			if _, err := tok.Token(); err != nil {
+				t.Fatal(err)
+			}
+		})
+		if alloc != 0 {
+			t.Fatalf("expected alloc: 0, got: %g", alloc)
+		}
+	})
+
+	tt := []struct {
+		name string
+		xml  string
+		opts []xmltokenizer.Option
+		err  error
+	}{
+		{
+			name: "token fits",
+			xml:  `<a x="1">text</a>`,
+			opts: []xmltokenizer.Option{xmltokenizer.WithFixedBuffer(make([]byte, 16))},
+		},
+		{
+			name: "token exceeds buffer",
+			xml:  `<a>` + strings.Repeat("x", 32) + `</a>`,
+			opts: []xmltokenizer.Option{xmltokenizer.WithFixedBuffer(make([]byte, 16))},
+			err:  xmltokenizer.ErrFixedBufferExceeded,
+		},
+		{
+			name: "chunked char data",
+			xml:  `<a>` + strings.Repeat("x", 32) + `</a>`,
+			opts: []xmltokenizer.Option{
+				xmltokenizer.WithFixedBuffer(make([]byte, 16)),
+				xmltokenizer.WithChunkedCharData(),
+			},
+		},
+		{
+			name: "too many attributes",
+			xml:  `<a x="1" y="2" z="3"/>`,
+			opts: []xmltokenizer.Option{
+				xmltokenizer.WithFixedBuffer(make([]byte, 64)),
+				xmltokenizer.WithAttrBufferSize(2),
+			},
+			err: xmltokenizer.ErrFixedBufferExceeded,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tok := xmltokenizer.New(io.MultiReader(strings.NewReader(tc.xml)), tc.opts...)
+			var err error
+			for {
+				if _, err = tok.Token(); err != nil {
+					break
+				}
+			}
+			if err == io.EOF {
+				err = nil
+			}
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expected error: %v, got: %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestPersistentTokens(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "xlsx_sheet1.xml"))
+	if err != nil {
+		panic(err)
+	}
+	expected, err := xmltest.TokenizeString(string(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tok := xmltokenizer.New(bytes.NewReader(data),
+		xmltokenizer.WithReadBufferSize(16),
+		xmltokenizer.WithPersistentTokens(),
+	)
+	var tokens []xmltokenizer.Token
+	for {
+		token, err := tok.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		tokens = append(tokens, token) // retained without copying
+	}
+
+	if len(tokens) != len(expected) {
+		t.Fatalf("expected len: %d, got: %d", len(expected), len(tokens))
+	}
+	for i := range tokens {
+		if diff := expected[i].Diff(tokens[i]); diff != "" {
+			t.Fatalf("[%d] %s", i, diff)
+		}
+		if !cmp.Equal(tokens[i].Name, expected[i].Name) {
+			t.Fatalf("[%d] expected name: %v, got: %v", i, expected[i].Name, tokens[i].Name)
+		}
+	}
+}
+
+func TestTrimSpace(t *testing.T) {
+	tt := []struct {
+		in, expected, expectedLeft, expectedRight string
+	}{
+		{in: "", expected: "", expectedLeft: "", expectedRight: ""},
+		{in: " \t\n text \t\n", expected: "text", expectedLeft: "text \t\n", expectedRight: " \t\n text"},
+		{in: "\r\n text\r\n", expected: "text", expectedLeft: "text\r\n", expectedRight: "\r\n text"},
+		{in: "text", expected: "text", expectedLeft: "text", expectedRight: "text"},
+		{in: "\r\n", expected: "", expectedLeft: "", expectedRight: ""},
+		{in: "\r text\r", expected: "\r text\r", expectedLeft: "\r text\r", expectedRight: "\r text\r"},
+		{in: "x\r\n       ", expected: "x", expectedLeft: "x\r\n       ", expectedRight: "x"},
+		{
+			in:            "\r\n\t  \n        \t\ttext  text\r\n  \t\t      \n\t",
+			expected:      "text  text",
+			expectedLeft:  "text  text\r\n  \t\t      \n\t",
+			expectedRight: "\r\n\t  \n        \t\ttext  text",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(fmt.Sprintf("%q", tc.in), func(t *testing.T) {
+			if got := string(xmltokenizer.TrimSpace([]byte(tc.in))); got != tc.expected {
+				t.Fatalf("TrimSpace: expected: %q, got: %q", tc.expected, got)
+			}
+			if got := string(xmltokenizer.TrimLeftSpace([]byte(tc.in))); got != tc.expectedLeft {
+				t.Fatalf("TrimLeftSpace: expected: %q, got: %q", tc.expectedLeft, got)
+			}
+			if got := string(xmltokenizer.TrimRightSpace([]byte(tc.in))); got != tc.expectedRight {
+				t.Fatalf("TrimRightSpace: expected: %q, got: %q", tc.expectedRight, got)
+			}
+		})
+	}
+}
+
+func TestSplitQName(t *testing.T) {
+	tt := []struct {
+		in            string
+		prefix, local []byte
+	}{
+		{in: "xsi:type", prefix: []byte("xsi"), local: []byte("type")},
+		{in: "type", prefix: nil, local: []byte("type")},
+		{in: "a:b:c", prefix: []byte("a"), local: []byte("b:c")},
+		{in: ":b", prefix: []byte{}, local: []byte("b")},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.in, func(t *testing.T) {
+			prefix, local := xmltokenizer.SplitQName([]byte(tc.in))
+			if diff := cmp.Diff(prefix, tc.prefix); diff != "" {
+				t.Fatalf("prefix: %s", diff)
+			}
+			if (prefix == nil) != (tc.prefix == nil) {
+				t.Fatalf("expected nil prefix: %t, got: %t", tc.prefix == nil, prefix == nil)
+			}
+			if diff := cmp.Diff(local, tc.local); diff != "" {
+				t.Fatalf("local: %s", diff)
+			}
+		})
+	}
+}
+
+// wakeReader reads one byte at a time from r, returning errWake every other read.
+type wakeReader struct {
+	r    io.Reader
+	wake bool
+}
+
+var errWake = errors.New("wake")
+
+func (w *wakeReader) Read(p []byte) (int, error) {
+	if w.wake = !w.wake; w.wake {
+		return 0, errWake
+	}
+	return w.r.Read(p[:1])
+}
+
+func TestResume(t *testing.T) {
+	const xml = `<?xml version="1.0"?>
+<a x="1">text &amp; more<![CDATA[ <c> ]]>
+  <b/>  <!-- comment -->
+  <d>` + "0123456789abcdef0123456789abcdef" + `</d>
+</a>`
+
+	tt := []struct {
+		name string
+		opts []xmltokenizer.Option
+	}{
+		{name: "default", opts: []xmltokenizer.Option{xmltokenizer.WithReadBufferSize(1)}},
+		{name: "chunked", opts: []xmltokenizer.Option{
+			xmltokenizer.WithReadBufferSize(1),
+			xmltokenizer.WithAutoGrowBufferMaxLimitSize(8),
+			xmltokenizer.WithChunkedCharData(),
+		}},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tokenize := func(r io.Reader) (tokens []string, wakes int) {
+				tok := xmltokenizer.New(r, append(tc.opts, xmltokenizer.WithSpace())...)
+				var raw strings.Builder
+				for {
+					token, err := tok.Token()
+					if errors.Is(err, errWake) {
+						wakes++
+						if err = tok.Resume(); err != nil {
+							t.Fatalf("expected nil, got: %v", err)
+						}
+						continue
+					}
+					raw.Write(tok.Space())
+					if err == io.EOF {
+						break
+					}
+					if err != nil {
+						t.Fatal(err)
+					}
+					raw.Write(tok.Raw())
+					tokens = append(tokens, token.String())
+				}
+				if raw.String() != xml {
+					t.Fatalf("expected raw: %q, got: %q", xml, raw.String())
+				}
+				if err := tok.Resume(); err != io.EOF {
+					t.Fatalf("expected error: %v, got: %v", io.EOF, err)
+				}
+				return tokens, wakes
+			}
+
+			expected, _ := tokenize(strings.NewReader(xml))
+			result, wakes := tokenize(&wakeReader{r: strings.NewReader(xml)})
+			if wakes < len(xml) {
+				t.Fatalf("expected at least %d wakes, got: %d", len(xml), wakes)
+			}
+			if diff := cmp.Diff(result, expected); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
+
+func TestRecover(t *testing.T) {
+	long := "<a><!-- " + strings.Repeat("x", 5000) + " --><b/>text</a>"
+
+	tt := []struct {
+		name     string
+		xml      string
+		opts     []xmltokenizer.Option
+		readerAt bool
+		expected []string
+		errs     []error
+	}{
+		{
+			name:     "unclosed quote",
+			xml:      "<a>\n  <b x=\"1></b>\n  <c>ok</c>\n</a>",
+			expected: []string{`<a @1:1>`, `</b @2:11>`, `<c @3:3>"ok"`, `</c @3:8>`, `</a @4:1>`},
+			errs:     []error{io.ErrUnexpectedEOF},
+		},
+		{
+			name: "token exceeds the buffer limit",
+			xml:  long,
+			opts: []xmltokenizer.Option{
+				xmltokenizer.WithReadBufferSize(4),
+				xmltokenizer.WithAutoGrowBufferMaxLimitSize(16),
+			},
+			expected: []string{`<a @1:1>`, `<b @1:5013/>"text"`, `</a @1:5021>`},
+			errs:     []error{errors.New("auto grow buffer exceed max limit")},
+		},
+		{
+			name: "token exceeds the buffer limit of a reader at",
+			xml:  long,
+			opts: []xmltokenizer.Option{
+				xmltokenizer.WithReadBufferSize(4),
+				xmltokenizer.WithAutoGrowBufferMaxLimitSize(16),
+			},
+			readerAt: true,
+			expected: []string{`<a @1:1>`, `<b @1:5013/>"text"`, `</a @1:5021>`},
+			errs:     []error{errors.New("auto grow buffer exceed max limit")},
+		},
+		{
+			name: "too many attributes",
+			xml:  `<a><b x="1" y="2" z="3"/><c x="1"/></a>`,
+			opts: []xmltokenizer.Option{
+				xmltokenizer.WithFixedBuffer(make([]byte, 64)),
+				xmltokenizer.WithAttrBufferSize(2),
+			},
+			expected: []string{`<a @1:1>`, `<c x="1" @1:26/>`, `</a @1:36>`},
+			errs:     []error{xmltokenizer.ErrFixedBufferExceeded},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var r io.Reader = strings.NewReader(tc.xml)
+			if tc.readerAt {
+				r = bytes.NewReader([]byte(tc.xml))
+			}
+			tok := xmltokenizer.New(r, append(tc.opts, xmltokenizer.WithSpace())...)
+			var raw strings.Builder
+			var tokens []string
+			var errs []error
+			for {
+				token, err := tok.Token()
+				if err == io.EOF {
+					raw.Write(tok.Space())
+					break
+				}
+				if err != nil {
+					errs = append(errs, err)
+					if err = tok.Recover(); err != nil {
+						t.Fatalf("expected nil, got: %v", err)
+					}
+					continue
+				}
+				raw.Write(tok.Space())
+				raw.Write(tok.Raw())
+				tokens = append(tokens, token.String())
+			}
+			if diff := cmp.Diff(tokens, tc.expected); diff != "" {
+				t.Fatal(diff)
+			}
+			if len(errs) != len(tc.errs) {
+				t.Fatalf("expected errors: %v, got: %v", tc.errs, errs)
+			}
+			for i := range errs {
+				if !strings.Contains(errs[i].Error(), tc.errs[i].Error()) {
+					t.Fatalf("expected error: %v, got: %v", tc.errs[i], errs[i])
+				}
+			}
+			if !tc.readerAt && raw.String() != tc.xml {
+				t.Fatalf("expected raw: %q, got: %q", tc.xml, raw.String())
+			}
+		})
+	}
+
+	t.Run("unrecoverable", func(t *testing.T) {
+		tok := xmltokenizer.New(strings.NewReader("<a>"+strings.Repeat("x", 100)+"</a>"),
+			xmltokenizer.WithReadBufferSize(1), xmltokenizer.WithMaxInputBytes(10))
+		var err error
+		for err == nil {
+			_, err = tok.Token()
+		}
+		if rerr := tok.Recover(); rerr != err {
+			t.Fatalf("expected error: %v, got: %v", err, rerr)
+		}
+		if rerr := xmltokenizer.New(strings.NewReader("")).Recover(); rerr != nil {
+			t.Fatalf("expected nil, got: %v", rerr)
+		}
+	})
+}
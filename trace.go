@@ -0,0 +1,65 @@
+package xmltokenizer
+
+// Tracer starts the spans of the Tokenizers, see WithTracer. It is meant to be
+// implemented by an adapter to a tracing system such as OpenTelemetry, which
+// typically captures the parent context.Context when it is created. A Tracer
+// shared by Tokenizers used concurrently must be safe for concurrent use.
+type Tracer interface {
+	// Start starts a span covering the tokenization of a document, named
+	// "xmltokenizer.Tokenize". It is invoked by the first Token or RawToken
+	// invocation after New, Reset or Restore.
+	Start(name string) TraceSpan
+}
+
+// TraceSpan is a span started by a Tracer.
+type TraceSpan interface {
+	// Event records a notable condition occurring during the span:
+	//   - "xmltokenizer.grow": the buffer is reallocated, with the
+	//     attributes "size" and "needed".
+	//   - "xmltokenizer.split_char_data": a CharData exceeding the buffer
+	//     limit is split into chunks instead of failing, see
+	//     WithChunkedCharData, with the attribute "offset" of the token
+	//     being split.
+	Event(name string, attrs ...TraceAttr)
+	// End ends the span once the Tokenizer returns an error, which is nil
+	// for io.EOF, or is closed or reset, with the attributes "tokens" and
+	// "bytes" holding the number of tokens returned and bytes read.
+	End(err error, attrs ...TraceAttr)
+}
+
+// TraceAttr is an attribute of a TraceSpan event.
+type TraceAttr struct {
+	Key   string
+	Value int64
+}
+
+// WithTracer directs XML Tokenizer to trace its operation using tr, so the
+// tokenization of large documents shows up in distributed traces, see Tracer.
+func WithTracer(tr Tracer) Option {
+	return func(o *options) { o.tracer = tr }
+}
+
+// startSpan starts the span unless there is one or the outcome is reported.
+func (t *Tokenizer) startSpan() {
+	if t.options.tracer == nil || t.span != nil || t.reported {
+		return
+	}
+	t.span = t.options.tracer.Start("xmltokenizer.Tokenize")
+	t.tokens = 0
+}
+
+// event records an event of the span, if any.
+func (t *Tokenizer) event(name string, attrs ...TraceAttr) {
+	if t.span != nil {
+		t.span.Event(name, attrs...)
+	}
+}
+
+// endSpan ends the span, if any.
+func (t *Tokenizer) endSpan(err error) {
+	if t.span == nil {
+		return
+	}
+	t.span.End(err, TraceAttr{"tokens", int64(t.tokens)}, TraceAttr{"bytes", t.read})
+	t.span = nil
+}
@@ -0,0 +1,79 @@
+package xmltokenizer_test
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/muktihari/xmltokenizer"
+)
+
+// tracer records the spans as lines of text.
+type tracer struct{ lines []string }
+
+func (tr *tracer) Start(name string) xmltokenizer.TraceSpan {
+	tr.lines = append(tr.lines, "start "+name)
+	return span{tr}
+}
+
+type span struct{ tr *tracer }
+
+func (s span) Event(name string, attrs ...xmltokenizer.TraceAttr) {
+	s.tr.lines = append(s.tr.lines, fmt.Sprintf("event %s %v", name, attrs))
+}
+
+func (s span) End(err error, attrs ...xmltokenizer.TraceAttr) {
+	s.tr.lines = append(s.tr.lines, fmt.Sprintf("end %v %v", err, attrs))
+}
+
+func TestTracer(t *testing.T) {
+	xml := `<a>` + strings.Repeat("x", 20000) + `</a>`
+	var tr tracer
+	tok := xmltokenizer.New(strings.NewReader(xml),
+		xmltokenizer.WithTracer(&tr),
+		xmltokenizer.WithReadBufferSize(1024),
+		xmltokenizer.WithAutoGrowBufferMaxLimitSize(12000),
+		xmltokenizer.WithChunkedCharData(),
+	)
+	for {
+		if _, err := tok.Token(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+	}
+	tok.Token() // the span is ended once
+	tok.Close()
+
+	expected := []string{
+		"start xmltokenizer.Tokenize",
+		"event xmltokenizer.grow [{size 10240} {needed 6144}]",
+		"event xmltokenizer.grow [{size 12000} {needed 11264}]",
+		"event xmltokenizer.split_char_data [{offset 0}]",
+		"end <nil> [{tokens 3} {bytes 20007}]",
+	}
+	if diff := cmp.Diff(tr.lines, expected); diff != "" {
+		t.Fatal(diff)
+	}
+
+	// The span is ended with the error, or by Reset.
+	tr.lines = nil
+	tok.Reset(strings.NewReader(`<a><b`), xmltokenizer.WithTracer(&tr))
+	tok.Token()
+	tok.Token()
+	tok.Reset(strings.NewReader(`<a>`), xmltokenizer.WithTracer(&tr))
+	tok.Token()
+	tok.Reset(strings.NewReader(`<a>`))
+
+	expected = []string{
+		"start xmltokenizer.Tokenize",
+		"end unexpected EOF [{tokens 1} {bytes 5}]",
+		"start xmltokenizer.Tokenize",
+		"end <nil> [{tokens 1} {bytes 3}]",
+	}
+	if diff := cmp.Diff(tr.lines, expected); diff != "" {
+		t.Fatal(diff)
+	}
+}
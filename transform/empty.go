@@ -0,0 +1,98 @@
+package transform
+
+import (
+	"bufio"
+	"io"
+
+	"github.com/muktihari/xmltokenizer"
+)
+
+// EmptyForm is the form of an element without content.
+type EmptyForm int
+
+const (
+	// AsWritten keeps the form the element is written in.
+	AsWritten EmptyForm = iota
+	// SelfClosingForm writes the element as <a/>.
+	SelfClosingForm
+	// ExpandedForm writes the element as <a></a>.
+	ExpandedForm
+)
+
+// EmptyElements configures NormalizeEmpty.
+type EmptyElements struct {
+	// Form is the form of the elements that are not in Elements.
+	Form EmptyForm
+	// Elements maps element names to their form, names are compared against
+	// the Full name, e.g. "soap:Header".
+	Elements map[string]EmptyForm
+}
+
+func (e *EmptyElements) form(name []byte) EmptyForm {
+	if form, ok := e.Elements[string(name)]; ok {
+		return form
+	}
+	return e.Form
+}
+
+// NormalizeEmpty copies the XML from r to w byte-exact, except the elements
+// without content, not even whitespace, which are written in the form set by
+// e, since some consumers only accept one of them. The attributes of the
+// rewritten elements are copied byte-exact, and so is the space within their
+// tags.
+func NormalizeEmpty(w io.Writer, r io.Reader, e EmptyElements, opts ...xmltokenizer.Option) error {
+	opts = append(opts[:len(opts):len(opts)], xmltokenizer.WithSpace())
+	tok := xmltokenizer.New(r, opts...)
+	bw := bufio.NewWriter(w)
+	if err := normalizeEmpty(bw, tok, &e); err != nil {
+		bw.Flush()
+		return err
+	}
+	return bw.Flush()
+}
+
+func normalizeEmpty(w *bufio.Writer, tok *xmltokenizer.Tokenizer, e *EmptyElements) error {
+	var buf []byte
+	token, err := tok.Token()
+	for {
+		w.Write(tok.Space())
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+
+		raw := tok.Raw()
+		switch {
+		case len(token.Name.Full) == 0 || token.Continued || token.IsEndElement:
+		case token.SelfClosing:
+			if e.form(token.Name.Full) != ExpandedForm {
+				break
+			}
+			end := tagEnd(raw)
+			buf = append(buf[:0], raw[:end-len("/>")]...)
+			buf = append(buf, "></"...)
+			buf = append(buf, token.Name.Full...)
+			buf = append(buf, '>')
+			raw = append(buf, raw[end:]...)
+		case tagEnd(raw) == len(raw) && e.form(token.Name.Full) == SelfClosingForm:
+			// The end element may follow right away, the start element is
+			// copied since it is only valid until the next token.
+			buf = append(buf[:0], raw...)
+			if token, err = tok.Token(); err == nil && token.IsEndElement && len(tok.Space()) == 0 {
+				buf = append(buf[:len(buf)-len(">")], "/>"...)
+				end := tok.Raw()
+				buf = append(buf, end[tagEnd(end):]...)
+				w.Write(buf)
+				token, err = tok.Token()
+				continue
+			}
+			w.Write(buf)
+			continue
+		}
+
+		w.Write(raw)
+		token, err = tok.Token()
+	}
+}
@@ -0,0 +1,92 @@
+package transform_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/muktihari/xmltokenizer/transform"
+)
+
+func TestNormalizeEmpty(t *testing.T) {
+	in := `<?xml version="1.0"?>
+<soap:Envelope xmlns:soap="urn:s">
+  <soap:Header/>
+  <soap:Body a="1"></soap:Body >
+  <x:br x:id='1' />text<p> </p><q>text</q>
+</soap:Envelope>`
+
+	tt := []struct {
+		name     string
+		e        transform.EmptyElements
+		expected string
+	}{
+		{
+			name:     "as written",
+			expected: in,
+		},
+		{
+			name: "self-closing",
+			e:    transform.EmptyElements{Form: transform.SelfClosingForm},
+			expected: `<?xml version="1.0"?>
+<soap:Envelope xmlns:soap="urn:s">
+  <soap:Header/>
+  <soap:Body a="1"/>
+  <x:br x:id='1' />text<p> </p><q>text</q>
+</soap:Envelope>`,
+		},
+		{
+			name: "expanded",
+			e:    transform.EmptyElements{Form: transform.ExpandedForm},
+			expected: `<?xml version="1.0"?>
+<soap:Envelope xmlns:soap="urn:s">
+  <soap:Header></soap:Header>
+  <soap:Body a="1"></soap:Body >
+  <x:br x:id='1' ></x:br>text<p> </p><q>text</q>
+</soap:Envelope>`,
+		},
+		{
+			name: "per element",
+			e: transform.EmptyElements{
+				Form: transform.SelfClosingForm,
+				Elements: map[string]transform.EmptyForm{
+					"soap:Header": transform.ExpandedForm,
+					"x:br":        transform.AsWritten,
+				},
+			},
+			expected: `<?xml version="1.0"?>
+<soap:Envelope xmlns:soap="urn:s">
+  <soap:Header></soap:Header>
+  <soap:Body a="1"/>
+  <x:br x:id='1' />text<p> </p><q>text</q>
+</soap:Envelope>`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := transform.NormalizeEmpty(&buf, strings.NewReader(in), tc.e); err != nil {
+				t.Fatalf("expected nil, got: %v", err)
+			}
+			if diff := cmp.Diff(buf.String(), tc.expected); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
+
+func TestNormalizeEmptyError(t *testing.T) {
+	var buf bytes.Buffer
+	e := transform.EmptyElements{Form: transform.SelfClosingForm}
+	err := transform.NormalizeEmpty(&buf, strings.NewReader(`<a><b></b><c><d`), e)
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("expected error: %v, got: %v", io.ErrUnexpectedEOF, err)
+	}
+	if diff := cmp.Diff(buf.String(), `<a><b/><c>`); diff != "" {
+		t.Fatal(diff)
+	}
+}
@@ -0,0 +1,51 @@
+package transform
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/muktihari/xmltokenizer"
+)
+
+// FilteredReader is an io.Reader of the XML read from another io.Reader where
+// only the tokens passing a predicate are kept, so components expecting an XML
+// byte stream, such as decoders and HTTP responses, can consume a filtered
+// document without writing it to a temporary file. The kept tokens are copied
+// byte-exact, and so is the space between them.
+type FilteredReader struct {
+	tf  transformer
+	fn  Func
+	out bytes.Buffer
+	err error
+}
+
+// NewFilteredReader returns a FilteredReader reading the XML from r and keeping
+// the tokens for which keep returns true. A start element for which it returns
+// false is dropped along with its subtree, see Skip, while the CharData
+// following it is kept since it belongs to the parent element. The token and the path,
+// the names of its ancestors, are only valid during the invocation of keep.
+func NewFilteredReader(r io.Reader, keep func(token *xmltokenizer.Token, path []xmltokenizer.Name) bool, opts ...xmltokenizer.Option) *FilteredReader {
+	opts = append(opts[:len(opts):len(opts)], xmltokenizer.WithSpace())
+	fr := &FilteredReader{
+		fn: func(token *xmltokenizer.Token, path []xmltokenizer.Name) (Action, error) {
+			if keep(token, path) {
+				return Keep, nil
+			}
+			return Skip, nil
+		},
+	}
+	fr.tf = transformer{tok: xmltokenizer.New(r, opts...), w: &fr.out}
+	return fr
+}
+
+// Read reads the filtered XML into p, tokenizing r as needed. It returns
+// io.EOF once the whole document is read, or the first error encountered.
+func (fr *FilteredReader) Read(p []byte) (n int, err error) {
+	for fr.out.Len() == 0 && fr.err == nil {
+		fr.err = fr.tf.step(fr.fn)
+	}
+	if fr.out.Len() > 0 {
+		return fr.out.Read(p)
+	}
+	return 0, fr.err
+}
@@ -0,0 +1,67 @@
+package transform_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/muktihari/xmltokenizer"
+	"github.com/muktihari/xmltokenizer/transform"
+)
+
+func TestFilteredReader(t *testing.T) {
+	const in = `<?xml version="1.0"?>
+<!-- generated -->
+<users>
+  <user id="1"><name>a</name><password>x</password></user>
+  <user id="2"><!-- note --><name>b</name><password>y</password></user>
+</users>
+`
+	const expected = `<?xml version="1.0"?>
+
+<users>
+  <user id="1"><name>a</name></user>
+  <user id="2"><name>b</name></user>
+</users>
+`
+	keep := func(token *xmltokenizer.Token, path []xmltokenizer.Name) bool {
+		return !strings.HasPrefix(string(token.Data), "<!--") && string(token.Name.Full) != "password"
+	}
+
+	b, err := io.ReadAll(transform.NewFilteredReader(strings.NewReader(in), keep))
+	if err != nil {
+		t.Fatalf("expected nil, got: %v", err)
+	}
+	if diff := cmp.Diff(string(b), expected); diff != "" {
+		t.Fatal(diff)
+	}
+
+	fr := transform.NewFilteredReader(iotest.OneByteReader(strings.NewReader(in)), keep)
+	if err := iotest.TestReader(fr, []byte(expected)); err != nil {
+		t.Fatal(err)
+	}
+
+	fr = transform.NewFilteredReader(strings.NewReader("<a><password>x"), keep)
+	if _, err := io.ReadAll(fr); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("expected error: %v, got: %v", io.ErrUnexpectedEOF, err)
+	}
+}
+
+func TestFilteredReaderMixedContent(t *testing.T) {
+	const in = `<p>Name: <secret>123</secret> is the id, <secret/> hidden <b>ok</b> end</p>`
+	const expected = `<p>Name:  is the id,  hidden <b>ok</b> end</p>`
+	keep := func(token *xmltokenizer.Token, path []xmltokenizer.Name) bool {
+		return string(token.Name.Full) != "secret"
+	}
+
+	b, err := io.ReadAll(transform.NewFilteredReader(strings.NewReader(in), keep))
+	if err != nil {
+		t.Fatalf("expected nil, got: %v", err)
+	}
+	if diff := cmp.Diff(string(b), expected); diff != "" {
+		t.Fatal(diff)
+	}
+}
@@ -0,0 +1,79 @@
+package transform
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/muktihari/xmltokenizer"
+)
+
+// Markup configures what to do with the comments and the processing
+// instructions, such as keeping the licence header while stripping the
+// editor's processing instructions from a generated artifact. A nil func keeps
+// them all. The XML declaration is not a processing instruction, it is always
+// kept.
+type Markup struct {
+	// Comment decides what to do with a comment, text is its content between
+	// "<!--" and "-->". It returns Keep, Skip or Rewrite in which case the
+	// content is replaced with the returned text, which must not contain "--".
+	Comment func(text []byte) (Action, []byte)
+	// ProcInst decides what to do with a processing instruction, target is its
+	// target, e.g. "xml-stylesheet", and inst its content after the target's
+	// trailing space up to "?>". It returns Keep, Skip or Rewrite in which case
+	// the content is replaced with the returned inst, which must not contain
+	// "?>".
+	ProcInst func(target, inst []byte) (Action, []byte)
+}
+
+// Wrap returns a Func applying m to the comments and processing instructions
+// and fn to the other tokens, so m is combined with any other Transform in a
+// single pass. A nil fn keeps the other tokens.
+func (m Markup) Wrap(fn Func) Func {
+	var buf []byte
+	return func(token *xmltokenizer.Token, path []xmltokenizer.Name) (Action, error) {
+		const commentPrefix, commentSuffix = "<!--", "-->"
+		const procInstPrefix, procInstSuffix = "<?", "?>"
+		switch data := token.Data; {
+		case len(token.Name.Full) > 0 || token.Continued:
+		case m.Comment != nil && bytes.HasPrefix(data, []byte(commentPrefix)):
+			text := bytes.TrimSuffix(data[len(commentPrefix):], []byte(commentSuffix))
+			action, text := m.Comment(text)
+			if action == Rewrite {
+				buf = append(buf[:0], commentPrefix...)
+				buf = append(buf, text...)
+				token.Data = append(buf, commentSuffix...)
+			}
+			return action, nil
+		case m.ProcInst != nil && bytes.HasPrefix(data, []byte(procInstPrefix)):
+			rest := bytes.TrimSuffix(data[len(procInstPrefix):], []byte(procInstSuffix))
+			target := rest
+			if i := bytes.IndexAny(rest, " \t\r\n"); i != -1 {
+				target = rest[:i]
+			}
+			if string(target) == "xml" {
+				return Keep, nil
+			}
+			action, inst := m.ProcInst(target, xmltokenizer.TrimLeftSpace(rest[len(target):]))
+			if action == Rewrite {
+				buf = append(buf[:0], procInstPrefix...)
+				buf = append(buf, target...)
+				if len(inst) > 0 {
+					buf = append(buf, ' ')
+					buf = append(buf, inst...)
+				}
+				token.Data = append(buf, procInstSuffix...)
+			}
+			return action, nil
+		}
+		if fn == nil {
+			return Keep, nil
+		}
+		return fn(token, path)
+	}
+}
+
+// FilterMarkup copies the XML from r to w byte-exact, except the comments and
+// the processing instructions that are dropped or rewritten according to m.
+func FilterMarkup(w io.Writer, r io.Reader, m Markup, opts ...xmltokenizer.Option) error {
+	return Transform(w, r, m.Wrap(nil), opts...)
+}
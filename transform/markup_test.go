@@ -0,0 +1,120 @@
+package transform_test
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/muktihari/xmltokenizer"
+	"github.com/muktihari/xmltokenizer/transform"
+)
+
+func TestFilterMarkup(t *testing.T) {
+	keepLicence := func(text []byte) (transform.Action, []byte) {
+		if bytes.Contains(text, []byte("Copyright")) {
+			return transform.Keep, nil
+		}
+		return transform.Skip, nil
+	}
+	dropEditor := func(target, inst []byte) (transform.Action, []byte) {
+		if bytes.HasPrefix(target, []byte("editor")) {
+			return transform.Skip, nil
+		}
+		return transform.Keep, nil
+	}
+
+	tt := []struct {
+		name     string
+		in       string
+		m        transform.Markup
+		expected string
+	}{
+		{
+			name:     "keep all",
+			in:       `<?xml version="1.0"?><!-- c --><?pi x?><a><!--d--></a>`,
+			expected: `<?xml version="1.0"?><!-- c --><?pi x?><a><!--d--></a>`,
+		},
+		{
+			name: "keep licence header, drop editor instructions",
+			in: `<!-- Copyright 2024 -->
+<?xml version="1.0"?>
+<?editor-fold state="open"?><!-- generated --><?xml-stylesheet href="a.xsl"?>
+<a>text<!-- todo --></a>`,
+			m: transform.Markup{Comment: keepLicence, ProcInst: dropEditor},
+			expected: `<!-- Copyright 2024 -->
+<?xml version="1.0"?>
+<?xml-stylesheet href="a.xsl"?>
+<a>text</a>`,
+		},
+		{
+			name: "rewrite",
+			in:   `<?xml version="1.0"?><!--old--><?pi  a="1" ?><?empty?><a/>`,
+			m: transform.Markup{
+				Comment: func(text []byte) (transform.Action, []byte) {
+					return transform.Rewrite, []byte(" new ")
+				},
+				ProcInst: func(target, inst []byte) (transform.Action, []byte) {
+					return transform.Rewrite, bytes.ToUpper(inst)
+				},
+			},
+			expected: `<?xml version="1.0"?><!-- new --><?pi A="1" ?><?empty?><a/>`,
+		},
+		{
+			name: "drop all",
+			in:   `<?xml version="1.0"?><!--a--><?b?><c>1</c>`,
+			m: transform.Markup{
+				Comment:  func([]byte) (transform.Action, []byte) { return transform.Skip, nil },
+				ProcInst: func(_, _ []byte) (transform.Action, []byte) { return transform.Skip, nil },
+			},
+			expected: `<?xml version="1.0"?><c>1</c>`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := transform.FilterMarkup(&buf, strings.NewReader(tc.in), tc.m); err != nil {
+				t.Fatalf("expected nil, got: %v", err)
+			}
+			if diff := cmp.Diff(buf.String(), tc.expected); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
+
+func TestMarkupWrap(t *testing.T) {
+	in, err := os.ReadFile("../testdata/copyright_header.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	in = append(in, "<!-- note --><a b='1'/>"...)
+
+	m := transform.Markup{Comment: func(text []byte) (transform.Action, []byte) {
+		if bytes.Contains(text, []byte("Copyright")) {
+			return transform.Keep, nil
+		}
+		return transform.Skip, nil
+	}}
+	fn := m.Wrap(func(token *xmltokenizer.Token, path []xmltokenizer.Name) (transform.Action, error) {
+		if bytes.HasPrefix(token.Data, []byte("<!--")) {
+			t.Fatalf("expected no comment, got: %q", token.Data)
+		}
+		if len(token.Name.Full) == 0 {
+			return transform.Keep, nil
+		}
+		token.Attrs = nil
+		return transform.Rewrite, nil
+	})
+
+	var buf bytes.Buffer
+	if err := transform.Transform(&buf, bytes.NewReader(in), fn); err != nil {
+		t.Fatalf("expected nil, got: %v", err)
+	}
+	expected := strings.Replace(string(in), "<!-- note --><a b='1'/>", "<a/>", 1)
+	if diff := cmp.Diff(buf.String(), expected); diff != "" {
+		t.Fatal(diff)
+	}
+}
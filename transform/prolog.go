@@ -0,0 +1,114 @@
+package transform
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+
+	"github.com/muktihari/xmltokenizer"
+)
+
+// Prolog configures SetProlog.
+type Prolog struct {
+	// Version of the XML declaration, "1.0" if empty.
+	Version string
+	// Encoding of the XML declaration, e.g. "UTF-8", omitted if empty.
+	Encoding string
+	// Standalone of the XML declaration, "yes" or "no", omitted if empty.
+	Standalone string
+	// Doctype is the content of the DOCTYPE, e.g. "html" or
+	// `svg PUBLIC "-//W3C//DTD SVG 1.1//EN" "http://www.w3.org/Graphics/SVG/1.1/DTD/svg11.dtd"`,
+	// the DOCTYPE of the document is kept if empty.
+	Doctype string
+}
+
+// appendDeclaration appends the XML declaration to dst.
+func (p *Prolog) appendDeclaration(dst []byte) []byte {
+	version := p.Version
+	if version == "" {
+		version = "1.0"
+	}
+	dst = append(dst, `<?xml version="`...)
+	dst = append(dst, version...)
+	dst = append(dst, '"')
+	if p.Encoding != "" {
+		dst = append(dst, ` encoding="`...)
+		dst = append(dst, p.Encoding...)
+		dst = append(dst, '"')
+	}
+	if p.Standalone != "" {
+		dst = append(dst, ` standalone="`...)
+		dst = append(dst, p.Standalone...)
+		dst = append(dst, '"')
+	}
+	return append(dst, "?>"...)
+}
+
+// appendDoctype appends the DOCTYPE to dst.
+func (p *Prolog) appendDoctype(dst []byte) []byte {
+	dst = append(dst, "<!DOCTYPE "...)
+	dst = append(dst, p.Doctype...)
+	return append(dst, '>')
+}
+
+// SetProlog copies the XML from r to w byte-exact, except its prolog: it
+// starts with the XML declaration set by p on its own line, replacing the
+// document's, and the DOCTYPE is replaced by p's if any, or inserted on its
+// own line before the root element if the document has none. The other
+// directives and the comments and processing instructions of the prolog are
+// copied as they are, so a regenerated document keeps what its consumers
+// require.
+func SetProlog(w io.Writer, r io.Reader, p Prolog, opts ...xmltokenizer.Option) error {
+	opts = append(opts[:len(opts):len(opts)], xmltokenizer.WithSpace())
+	tok := xmltokenizer.New(r, opts...)
+	bw := bufio.NewWriter(w)
+	if err := setProlog(bw, tok, &p); err != nil {
+		bw.Flush()
+		return err
+	}
+	return bw.Flush()
+}
+
+func setProlog(w *bufio.Writer, tok *xmltokenizer.Tokenizer, p *Prolog) error {
+	buf := append(p.appendDeclaration(nil), '\n')
+	w.Write(buf)
+
+	leading, doctype := true, p.Doctype == ""
+	for {
+		token, err := tok.Token()
+		if !leading { // the leading space is replaced by the declaration's newline
+			w.Write(tok.Space())
+		}
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+
+		raw := tok.Raw()
+		switch {
+		case len(token.Name.Full) > 0 || token.Continued:
+			if !doctype && !token.IsEndElement {
+				buf = append(p.appendDoctype(buf[:0]), '\n')
+				w.Write(buf)
+				doctype = true
+			}
+		case isDeclaration(token.Data):
+			continue
+		case bytes.HasPrefix(token.Data, []byte("<!DOCTYPE")):
+			if !doctype {
+				buf = p.appendDoctype(buf[:0])
+				raw, doctype = buf, true
+			}
+		}
+		w.Write(raw)
+		leading = false
+	}
+}
+
+// isDeclaration reports whether data is the XML declaration.
+func isDeclaration(data []byte) bool {
+	rest, ok := bytes.CutPrefix(data, []byte("<?xml"))
+	return ok && len(rest) > 0 && bytes.IndexByte([]byte("? \t\r\n"), rest[0]) != -1
+}
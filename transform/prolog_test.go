@@ -0,0 +1,86 @@
+package transform_test
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/muktihari/xmltokenizer/transform"
+)
+
+func TestSetProlog(t *testing.T) {
+	const svg = `svg PUBLIC "-//W3C//DTD SVG 1.1//EN" "http://www.w3.org/Graphics/SVG/1.1/DTD/svg11.dtd"`
+	tt := []struct {
+		name     string
+		in       string
+		p        transform.Prolog
+		expected string
+	}{
+		{
+			name:     "no prolog",
+			in:       `<svg><g/></svg>`,
+			p:        transform.Prolog{Doctype: svg},
+			expected: "<?xml version=\"1.0\"?>\n<!DOCTYPE " + svg + ">\n<svg><g/></svg>",
+		},
+		{
+			name: "replace declaration, keep doctype",
+			in: `<?xml version="1.0"?>
+<!DOCTYPE a [
+<!ENTITY e "x">
+]>
+<!-- c -->
+<a>&e;</a>
+`,
+			p: transform.Prolog{Version: "1.1", Encoding: "UTF-8", Standalone: "no"},
+			expected: `<?xml version="1.1" encoding="UTF-8" standalone="no"?>
+<!DOCTYPE a [
+<!ENTITY e "x">
+]>
+<!-- c -->
+<a>&e;</a>
+`,
+		},
+		{
+			name: "replace doctype",
+			in: `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html SYSTEM "about:legacy-compat"><?pi?>
+<html/>`,
+			p: transform.Prolog{Doctype: "html"},
+			expected: `<?xml version="1.0"?>
+<!DOCTYPE html><?pi?>
+<html/>`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := transform.SetProlog(&buf, strings.NewReader(tc.in), tc.p); err != nil {
+				t.Fatalf("expected nil, got: %v", err)
+			}
+			if diff := cmp.Diff(buf.String(), tc.expected); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
+
+func TestSetPrologCopyrightHeader(t *testing.T) {
+	in, err := os.ReadFile("../testdata/copyright_header.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	in = append(in, "<a/>"...)
+
+	var buf bytes.Buffer
+	if err := transform.SetProlog(&buf, bytes.NewReader(in), transform.Prolog{Encoding: "UTF-8"}); err != nil {
+		t.Fatalf("expected nil, got: %v", err)
+	}
+	expected := "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n" +
+		"<!--\n  Copyright 2024 Example Licence Authors.\n-->\n<a/>"
+	if diff := cmp.Diff(buf.String(), expected); diff != "" {
+		t.Fatal(diff)
+	}
+}
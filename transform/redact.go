@@ -0,0 +1,78 @@
+package transform
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+
+	"github.com/muktihari/xmltokenizer"
+	"github.com/muktihari/xmltokenizer/xmlpath"
+)
+
+// Redact copies the XML from r to w byte-exact, except the elements and
+// attributes matching any of the given paths, e.g. "//customer/ssn" or
+// "/export//customer[@vip]/@ssn", see the xmlpath package for the syntax.
+// Matching elements are removed including their subtree and matching
+// attributes are removed, unless mask is not empty in which case the
+// element's content and the attribute's value are replaced with the escaped
+// mask instead.
+func Redact(w io.Writer, r io.Reader, paths []string, mask string, opts ...xmltokenizer.Option) error {
+	set, err := xmlpath.CompileSet(paths...)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(mask))
+	escapedMask := buf.Bytes()
+
+	return Transform(w, r, func(token *xmltokenizer.Token, _ []xmltokenizer.Name) (Action, error) {
+		matched, exit := set.Step(token)
+		if exit || len(matched) == 0 {
+			return Keep, nil
+		}
+		action, element := Keep, false
+		for _, i := range matched {
+			if !set.SelectsAttr(i) {
+				element = true
+				continue
+			}
+			if redactAttrs(token, set, i, escapedMask) {
+				action = Rewrite
+			}
+		}
+		if !element {
+			return action, nil
+		}
+		// The subtree is dropped by Transform without being given to this
+		// Func, the set is told the element ends right away.
+		if !token.SelfClosing {
+			set.Step(&xmltokenizer.Token{Name: token.Name, IsEndElement: true})
+		}
+		if len(escapedMask) == 0 {
+			return Skip, nil
+		}
+		token.Data = escapedMask
+		return ReplaceContent, nil
+	}, opts...)
+}
+
+// redactAttrs removes or masks token's attributes selected by the path at
+// index i of set, it reports whether any of the attributes is selected.
+func redactAttrs(token *xmltokenizer.Token, set *xmlpath.Set, i int, mask []byte) bool {
+	var matched bool
+	attrs := token.Attrs[:0]
+	for _, attr := range token.Attrs {
+		if !set.MatchAttr(i, attr.Name) {
+			attrs = append(attrs, attr)
+			continue
+		}
+		matched = true
+		if len(mask) != 0 {
+			attr.Value = mask
+			attrs = append(attrs, attr)
+		}
+	}
+	token.Attrs = attrs
+	return matched
+}
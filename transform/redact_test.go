@@ -0,0 +1,162 @@
+package transform_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/muktihari/xmltokenizer/transform"
+)
+
+func TestRedact(t *testing.T) {
+	const doc = `<export>
+  <customer id="1" ssn="111">
+    <name>Alice</name>
+    <ssn>111-11-1111</ssn>
+    <note><ssn>nested</ssn></note>
+  </customer>
+  <ssn>top</ssn>
+</export>`
+
+	tt := []struct {
+		name     string
+		paths    []string
+		mask     string
+		expected string
+		err      error
+	}{
+		{
+			name:  "remove element",
+			paths: []string{"//customer/ssn"},
+			expected: `<export>
+  <customer id="1" ssn="111">
+    <name>Alice</name>
+    
+    <note><ssn>nested</ssn></note>
+  </customer>
+  <ssn>top</ssn>
+</export>`,
+		},
+		{
+			name:  "mask descendant elements and attribute",
+			paths: []string{"/export/customer//ssn", "//customer/@ssn"},
+			mask:  "<x>",
+			expected: `<export>
+  <customer id="1" ssn="&lt;x&gt;">
+    <name>Alice</name>
+    <ssn>&lt;x&gt;</ssn>
+    <note><ssn>&lt;x&gt;</ssn></note>
+  </customer>
+  <ssn>top</ssn>
+</export>`,
+		},
+		{
+			name:  "remove attribute with wildcard",
+			paths: []string{"/*/*/@ssn"},
+			expected: `<export>
+  <customer id="1">
+    <name>Alice</name>
+    <ssn>111-11-1111</ssn>
+    <note><ssn>nested</ssn></note>
+  </customer>
+  <ssn>top</ssn>
+</export>`,
+		},
+		{
+			name:  "remove elements with predicate",
+			paths: []string{"//customer[@id='1']/ssn", "/export/ssn"},
+			expected: `<export>
+  <customer id="1" ssn="111">
+    <name>Alice</name>
+    
+    <note><ssn>nested</ssn></note>
+  </customer>
+  
+</export>`,
+		},
+		{
+			name:  "mask element and its attribute",
+			paths: []string{"//customer", "//customer/@ssn"},
+			mask:  "x",
+			expected: `<export>
+  <customer id="1" ssn="x">x</customer>
+  <ssn>top</ssn>
+</export>`,
+		},
+		{
+			name:  "invalid path",
+			paths: []string{"customer"},
+			err:   errors.New("invalid path"),
+		},
+		{
+			name:  "invalid attribute path",
+			paths: []string{"//@ssn"},
+			err:   errors.New("invalid path"),
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := transform.Redact(&buf, strings.NewReader(doc), tc.paths, tc.mask)
+			if (err == nil) != (tc.err == nil) {
+				t.Fatalf("expected error: %v, got: %v", tc.err, err)
+			}
+			if diff := cmp.Diff(buf.String(), tc.expected); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
+
+func TestRedactMixedContent(t *testing.T) {
+	tt := []struct {
+		name     string
+		in       string
+		mask     string
+		expected string
+	}{
+		{
+			name:     "remove element",
+			in:       `<p>Name: <ssn>123</ssn> is the id</p>`,
+			expected: `<p>Name:  is the id</p>`,
+		},
+		{
+			name:     "mask element",
+			in:       `<p>Name: <ssn>123</ssn> is the id</p>`,
+			mask:     "XXX",
+			expected: `<p>Name: <ssn>XXX</ssn> is the id</p>`,
+		},
+		{
+			name:     "remove self-closing element",
+			in:       `<p><ssn/> after</p>`,
+			expected: `<p> after</p>`,
+		},
+		{
+			name:     "mask self-closing element",
+			in:       `<p><ssn/> after <ssn a="/>"/>end</p>`,
+			mask:     "XXX",
+			expected: `<p><ssn>XXX</ssn> after <ssn a="/>">XXX</ssn>end</p>`,
+		},
+		{
+			name:     "remove nested element",
+			in:       `<p><ssn><b>1</b> x</ssn> after</p>`,
+			expected: `<p> after</p>`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := transform.Redact(&buf, strings.NewReader(tc.in), []string{"//ssn"}, tc.mask)
+			if err != nil {
+				t.Fatalf("expected error: nil, got: %v", err)
+			}
+			if diff := cmp.Diff(buf.String(), tc.expected); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package transform
+
+import (
+	"io"
+
+	"github.com/muktihari/xmltokenizer"
+)
+
+// Mapping configures Rename. Names are compared against the Full name.
+type Mapping struct {
+	// Elements maps element names to their new names, e.g. "gpx:trkpt" to "trkpt".
+	Elements map[string]string
+	// Prefixes maps namespace prefixes to their new prefixes, applied to the
+	// names of elements and attributes as well as to the xmlns:prefix
+	// declarations. An empty new prefix removes the prefix.
+	Prefixes map[string]string
+	// Namespaces maps namespace URIs to their new URIs, applied to the
+	// values of the xmlns and xmlns:prefix declarations.
+	Namespaces map[string]string
+}
+
+// Rename copies the XML from r to w byte-exact, except the names of elements
+// and attributes and the namespace declarations that are renamed according to
+// the given mapping. An element renamed by Elements is not renamed by Prefixes.
+func Rename(w io.Writer, r io.Reader, m Mapping, opts ...xmltokenizer.Option) error {
+	rn := renamer{
+		elements:   make(map[string][]byte, len(m.Elements)),
+		namespaces: make(map[string][]byte, len(m.Namespaces)),
+		prefixes:   m.Prefixes,
+		cache:      make(map[string][]byte),
+	}
+	for k, v := range m.Elements {
+		rn.elements[k] = []byte(v)
+	}
+	for k, v := range m.Namespaces {
+		rn.namespaces[k] = []byte(v)
+	}
+
+	return Transform(w, r, func(token *xmltokenizer.Token, path []xmltokenizer.Name) (Action, error) {
+		if len(token.Name.Full) == 0 || token.Continued {
+			return Keep, nil
+		}
+		action := Keep
+		if name, ok := rn.elements[string(token.Name.Full)]; ok {
+			token.Name.Full, action = name, Rewrite
+		} else if name, ok := rn.prefixed(token.Name); ok {
+			token.Name.Full, action = name, Rewrite
+		}
+		for i := range token.Attrs {
+			attr := &token.Attrs[i]
+			if name, ok := rn.prefixed(attr.Name); ok {
+				attr.Name.Full, action = name, Rewrite
+			}
+			if !isNamespaceDecl(attr.Name) {
+				continue
+			}
+			if value, ok := rn.namespaces[string(attr.Value)]; ok {
+				attr.Value, action = value, Rewrite
+			}
+		}
+		return action, nil
+	}, opts...)
+}
+
+type renamer struct {
+	elements   map[string][]byte
+	namespaces map[string][]byte
+	prefixes   map[string]string
+	cache      map[string][]byte // new names by the names renamed through prefixes.
+}
+
+// prefixed returns the new name of n if its prefix, or its local name
+// when it is a xmlns:prefix declaration, is mapped.
+func (rn *renamer) prefixed(n xmltokenizer.Name) ([]byte, bool) {
+	if len(n.Prefix) == 0 {
+		return nil, false
+	}
+	if name, ok := rn.cache[string(n.Full)]; ok {
+		return name, true
+	}
+
+	var name []byte
+	if string(n.Prefix) == "xmlns" {
+		prefix, ok := rn.prefixes[string(n.Local)]
+		if !ok {
+			return nil, false
+		}
+		name = append(name, "xmlns"...)
+		if prefix != "" {
+			name = append(name, ':')
+			name = append(name, prefix...)
+		}
+	} else {
+		prefix, ok := rn.prefixes[string(n.Prefix)]
+		if !ok {
+			return nil, false
+		}
+		if prefix != "" {
+			name = append(name, prefix...)
+			name = append(name, ':')
+		}
+		name = append(name, n.Local...)
+	}
+
+	rn.cache[string(n.Full)] = name
+	return name, true
+}
+
+// isNamespaceDecl reports whether n is either xmlns or xmlns:prefix.
+func isNamespaceDecl(n xmltokenizer.Name) bool {
+	if len(n.Prefix) == 0 {
+		return string(n.Full) == "xmlns"
+	}
+	return string(n.Prefix) == "xmlns"
+}
@@ -0,0 +1,76 @@
+package transform_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/muktihari/xmltokenizer/transform"
+)
+
+func TestRename(t *testing.T) {
+	tt := []struct {
+		name     string
+		in       string
+		mapping  transform.Mapping
+		expected string
+	}{
+		{
+			name: "gpx 1.0 to 1.1",
+			in: `<?xml version="1.0"?>
+<gpx version="1.0" xmlns="http://www.topografix.com/GPX/1/0">
+  <trk><trkseg>
+    <trkpt lat="1" lon="2"><course>90</course></trkpt>
+  </trkseg></trk>
+</gpx>
+`,
+			mapping: transform.Mapping{
+				Elements:   map[string]string{"course": "heading"},
+				Namespaces: map[string]string{"http://www.topografix.com/GPX/1/0": "http://www.topografix.com/GPX/1/1"},
+			},
+			expected: `<?xml version="1.0"?>
+<gpx version="1.0" xmlns="http://www.topografix.com/GPX/1/1">
+  <trk><trkseg>
+    <trkpt lat="1" lon="2"><heading>90</heading></trkpt>
+  </trkseg></trk>
+</gpx>
+`,
+		},
+		{
+			name: "rename prefixes",
+			in:   `<v:a xmlns:v="urn:old" xmlns:k="urn:keep" v:x='1' k:y="2"><v:b/><k:c>text</k:c></v:a>`,
+			mapping: transform.Mapping{
+				Prefixes:   map[string]string{"v": "n"},
+				Namespaces: map[string]string{"urn:old": "urn:new"},
+			},
+			expected: `<n:a xmlns:n="urn:new" xmlns:k="urn:keep" n:x="1" k:y="2"><n:b/><k:c>text</k:c></n:a>`,
+		},
+		{
+			name: "remove prefix",
+			in:   `<v:a xmlns:v="urn:v"><v:b>text</v:b></v:a>`,
+			mapping: transform.Mapping{
+				Prefixes: map[string]string{"v": ""},
+			},
+			expected: `<a xmlns="urn:v"><b>text</b></a>`,
+		},
+		{
+			name:     "nothing to rename",
+			in:       "<a x = '1' >\n text </a>",
+			mapping:  transform.Mapping{Elements: map[string]string{"b": "c"}},
+			expected: "<a x = '1' >\n text </a>",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := transform.Rename(&buf, strings.NewReader(tc.in), tc.mapping); err != nil {
+				t.Fatalf("expected nil, got: %v", err)
+			}
+			if diff := cmp.Diff(buf.String(), tc.expected); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package transform
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/muktihari/xmltokenizer"
+)
+
+// RoundTripError is returned by VerifyRoundTrip when the copy diverges from
+// the document.
+type RoundTripError struct {
+	Pos      xmltokenizer.Pos // Position of the first divergent byte in the document, Column counts bytes.
+	Expected []byte           // Bytes of the document from Pos, at most 16.
+	Got      []byte           // Bytes of the copy from Pos, at most 16.
+}
+
+func (e *RoundTripError) Error() string {
+	return fmt.Sprintf("round trip diverges at line %d, col %d, offset %d: expected %q, got %q",
+		e.Pos.Line, e.Pos.Column, e.Pos.Offset, e.Expected, e.Got)
+}
+
+// VerifyRoundTrip tokenizes the XML from r and copies it with Transform keeping
+// every token, returning a RoundTripError at the first byte of the copy that
+// differs from the document, so pipelines editing documents can check that
+// what they do not edit is preserved with the given options. The document is
+// compared as it is read, without being held in memory. A tokenizing error is
+// returned as it is.
+func VerifyRoundTrip(r io.Reader, opts ...xmltokenizer.Option) error {
+	v := roundTripVerifier{pos: xmltokenizer.Pos{Line: 1, Column: 1}}
+	keep := func(*xmltokenizer.Token, []xmltokenizer.Name) (Action, error) { return Keep, nil }
+	if err := Transform(&v, io.TeeReader(r, &v.doc), keep, opts...); err != nil {
+		return err
+	}
+	if v.doc.Len() > 0 {
+		return v.diverge(nil) // the copy is shorter
+	}
+	return nil
+}
+
+// roundTripVerifier compares the copy written to it with the document read
+// so far, which is always ahead of the copy.
+type roundTripVerifier struct {
+	doc bytes.Buffer // read but not yet compared
+	pos xmltokenizer.Pos
+}
+
+func (v *roundTripVerifier) Write(p []byte) (int, error) {
+	doc := v.doc.Bytes()
+	n := 0
+	for n < len(p) && n < len(doc) && p[n] == doc[n] {
+		n++
+	}
+	v.advance(doc[:n])
+	v.doc.Next(n)
+	if n < len(p) {
+		return n, v.diverge(p[n:])
+	}
+	return n, nil
+}
+
+func (v *roundTripVerifier) WriteString(s string) (int, error) {
+	return v.Write([]byte(s))
+}
+
+// advance moves the position past the matching bytes b.
+func (v *roundTripVerifier) advance(b []byte) {
+	v.pos.Offset += len(b)
+	if i := bytes.LastIndexByte(b, '\n'); i != -1 {
+		v.pos.Line += bytes.Count(b, []byte("\n"))
+		v.pos.Column = len(b) - i
+		return
+	}
+	v.pos.Column += len(b)
+}
+
+func (v *roundTripVerifier) diverge(got []byte) error {
+	const snippet = 16
+	expected := v.doc.Bytes()
+	return &RoundTripError{
+		Pos:      v.pos,
+		Expected: bytes.Clone(expected[:min(len(expected), snippet)]),
+		Got:      bytes.Clone(got[:min(len(got), snippet)]),
+	}
+}
@@ -0,0 +1,70 @@
+package transform
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/muktihari/xmltokenizer"
+)
+
+func TestRoundTripVerifier(t *testing.T) {
+	tt := []struct {
+		name   string
+		doc    string
+		writes []string
+		err    *RoundTripError
+	}{
+		{
+			name:   "equal",
+			doc:    "<a>\n  <b/>\n</a>",
+			writes: []string{"<a>\n", "  <b/>", "\n</a>"},
+		},
+		{
+			name:   "diverges",
+			doc:    "<a>\n  <b/>\n</a>",
+			writes: []string{"<a>\n", "  <c/>"},
+			err: &RoundTripError{
+				Pos:      xmltokenizer.Pos{Line: 2, Column: 4, Offset: 7},
+				Expected: []byte("b/>\n</a>"),
+				Got:      []byte("c/>"),
+			},
+		},
+		{
+			name:   "copy is longer",
+			doc:    "<a/>",
+			writes: []string{"<a/>\n"},
+			err: &RoundTripError{
+				Pos:      xmltokenizer.Pos{Line: 1, Column: 5, Offset: 4},
+				Expected: []byte{},
+				Got:      []byte("\n"),
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			v := roundTripVerifier{pos: xmltokenizer.Pos{Line: 1, Column: 1}}
+			v.doc.WriteString(tc.doc)
+			var err error
+			for _, s := range tc.writes {
+				if _, err = v.WriteString(s); err != nil {
+					break
+				}
+			}
+			if tc.err == nil {
+				if err != nil {
+					t.Fatalf("expected nil, got: %v", err)
+				}
+				return
+			}
+			var rtErr *RoundTripError
+			if !errors.As(err, &rtErr) {
+				t.Fatalf("expected error: %v, got: %v", tc.err, err)
+			}
+			if diff := cmp.Diff(tc.err, rtErr); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
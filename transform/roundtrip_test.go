@@ -0,0 +1,29 @@
+package transform_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/muktihari/xmltokenizer"
+	"github.com/muktihari/xmltokenizer/transform"
+)
+
+func TestVerifyRoundTrip(t *testing.T) {
+	filenames, err := filepath.Glob("../testdata/*.*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, filename := range filenames {
+		t.Run(filename, func(t *testing.T) {
+			f, err := os.Open(filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			if err := transform.VerifyRoundTrip(f, xmltokenizer.WithChunkedCharData()); err != nil {
+				t.Fatalf("expected nil, got: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package transform
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/muktihari/xmltokenizer"
+	"github.com/muktihari/xmltokenizer/xmlpath"
+)
+
+// SetAttrs copies the XML from r to w byte-exact, except the start elements
+// matching path, see the xmlpath package for the syntax, which get the given attributes:
+// the value of an attribute already present is replaced and the others are
+// appended sorted by name. Names are compared against the Full name and values
+// are escaped. Only the tags of the matching elements are re-serialized.
+func SetAttrs(w io.Writer, r io.Reader, path string, attrs map[string]string, opts ...xmltokenizer.Option) error {
+	m, err := xmlpath.Compile(path)
+	if err != nil {
+		return err
+	}
+	if m.SelectsAttr() {
+		return fmt.Errorf("%q: must select an element: %w", path, errInvalidPath)
+	}
+
+	names := make([]string, 0, len(attrs))
+	for name := range attrs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	values := make([]xmltokenizer.Attr, len(names))
+	for i, name := range names {
+		var buf bytes.Buffer
+		xml.EscapeText(&buf, []byte(attrs[name]))
+		values[i] = xmltokenizer.Attr{
+			Name:  xmltokenizer.Name{Full: []byte(name)},
+			Value: buf.Bytes(),
+			Index: -1,
+		}
+	}
+
+	var scratch []xmltokenizer.Attr
+	return Transform(w, r, func(token *xmltokenizer.Token, _ []xmltokenizer.Name) (Action, error) {
+		if m.Step(token) != xmlpath.Enter {
+			return Keep, nil
+		}
+		scratch = append(scratch[:0], token.Attrs...)
+		n := len(scratch)
+	next:
+		for _, v := range values {
+			for i := 0; i < n; i++ {
+				if bytes.Equal(scratch[i].Name.Full, v.Name.Full) {
+					scratch[i].Value = v.Value
+					continue next
+				}
+			}
+			scratch = append(scratch, v)
+		}
+		token.Attrs = scratch
+		return Rewrite, nil
+	}, opts...)
+}
@@ -0,0 +1,61 @@
+package transform_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/muktihari/xmltokenizer/transform"
+)
+
+func TestSetAttrs(t *testing.T) {
+	const doc = "<export>\n  <file name='a' encoding=\"latin1\">\n    text\n  </file>\n  <other encoding=\"latin1\"/>\n</export>"
+
+	tt := []struct {
+		name     string
+		path     string
+		attrs    map[string]string
+		expected string
+		err      error
+	}{
+		{
+			name:     "replace and append",
+			path:     "//file",
+			attrs:    map[string]string{"encoding": "utf-8", "generated-at": "2024-01-01", "by": `"x" & y`},
+			expected: "<export>\n  <file name=\"a\" encoding=\"utf-8\" by=\"&#34;x&#34; &amp; y\" generated-at=\"2024-01-01\">\n    text\n  </file>\n  <other encoding=\"latin1\"/>\n</export>",
+		},
+		{
+			name:     "self-closing with wildcard",
+			path:     "/export/*",
+			attrs:    map[string]string{"encoding": "utf-8"},
+			expected: "<export>\n  <file name=\"a\" encoding=\"utf-8\">\n    text\n  </file>\n  <other encoding=\"utf-8\"/>\n</export>",
+		},
+		{
+			name:     "predicate",
+			path:     "/export/*[@name='a']",
+			attrs:    map[string]string{"encoding": "utf-8"},
+			expected: "<export>\n  <file name=\"a\" encoding=\"utf-8\">\n    text\n  </file>\n  <other encoding=\"latin1\"/>\n</export>",
+		},
+		{
+			name:  "attribute path",
+			path:  "//file/@encoding",
+			attrs: map[string]string{"encoding": "utf-8"},
+			err:   errors.New("invalid path"),
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := transform.SetAttrs(&buf, strings.NewReader(doc), tc.path, tc.attrs)
+			if (err == nil) != (tc.err == nil) {
+				t.Fatalf("expected error: %v, got: %v", tc.err, err)
+			}
+			if diff := cmp.Diff(buf.String(), tc.expected); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package transform
+
+import (
+	"bytes"
+	"io"
+	"sort"
+
+	"github.com/muktihari/xmltokenizer"
+)
+
+const xmlNamespace = "http://www.w3.org/XML/1998/namespace"
+
+// SortAttrs copies the XML from r to w byte-exact, except the tags of the
+// start elements whose attributes are not sorted, see SortAttrsFunc, so
+// generated XML is reproducible and diffs stably regardless of the order in
+// which its attributes were written. It is not a canonicalization: the rest
+// of the document is left as it is.
+func SortAttrs(w io.Writer, r io.Reader, opts ...xmltokenizer.Option) error {
+	return Transform(w, r, SortAttrsFunc(nil), opts...)
+}
+
+// SortAttrsFunc returns a Func applying fn, then sorting the attributes of the
+// start elements that are kept or rewritten: the namespace declarations come
+// first sorted by prefix, followed by the other attributes sorted by namespace
+// URI then local name, the attributes without prefix having no namespace URI.
+// The prefixes that are not declared are sorted as if they were URIs. A tag
+// whose attributes are already sorted is kept as returned by fn. A nil fn
+// keeps every token.
+func SortAttrsFunc(fn Func) Func {
+	var s attrSorter
+	return func(token *xmltokenizer.Token, path []xmltokenizer.Name) (Action, error) {
+		action := Keep
+		if fn != nil {
+			var err error
+			if action, err = fn(token, path); err != nil {
+				return action, err
+			}
+		}
+		if len(token.Name.Full) == 0 || token.Continued || action == Skip {
+			return action, nil
+		}
+		if token.IsEndElement {
+			s.pop()
+			return action, nil
+		}
+
+		s.push(token.Attrs)
+		if s.sort(token.Attrs) && action == Keep {
+			action = Rewrite
+		}
+		if token.SelfClosing || action == ReplaceContent { // no end element is passed to fn
+			s.pop()
+		}
+		return action, nil
+	}
+}
+
+// attrSorter sorts attributes, tracking the namespaces in scope.
+type attrSorter struct {
+	bindings []nsBinding
+	marks    []int // len(bindings) before each open element
+	keys     []attrKey
+}
+
+type nsBinding struct {
+	prefix, uri []byte
+}
+
+type attrKey struct {
+	decl       bool
+	uri, local []byte
+	attr       xmltokenizer.Attr
+}
+
+// push declares the namespaces of attrs for the element they belong to.
+// The values are copied since they are used by the subsequent tokens.
+func (s *attrSorter) push(attrs []xmltokenizer.Attr) {
+	s.marks = append(s.marks, len(s.bindings))
+	for i := range attrs {
+		if !isNamespaceDecl(attrs[i].Name) || len(attrs[i].Name.Prefix) == 0 {
+			continue
+		}
+		s.bindings = append(s.bindings, nsBinding{
+			prefix: bytes.Clone(attrs[i].Name.Local),
+			uri:    bytes.Clone(attrs[i].Value),
+		})
+	}
+}
+
+func (s *attrSorter) pop() {
+	if len(s.marks) == 0 {
+		return
+	}
+	s.bindings = s.bindings[:s.marks[len(s.marks)-1]]
+	s.marks = s.marks[:len(s.marks)-1]
+}
+
+// uri returns the namespace URI bound to prefix, or prefix itself if it is
+// not declared.
+func (s *attrSorter) uri(prefix []byte) []byte {
+	if string(prefix) == "xml" {
+		return []byte(xmlNamespace)
+	}
+	for i := len(s.bindings) - 1; i >= 0; i-- {
+		if bytes.Equal(s.bindings[i].prefix, prefix) {
+			return s.bindings[i].uri
+		}
+	}
+	return prefix
+}
+
+// sort sorts attrs in place, it reports whether their order changed.
+func (s *attrSorter) sort(attrs []xmltokenizer.Attr) bool {
+	if len(attrs) < 2 {
+		return false
+	}
+	s.keys = s.keys[:0]
+	for _, attr := range attrs {
+		key := attrKey{decl: isNamespaceDecl(attr.Name), local: attr.Name.Local, attr: attr}
+		if key.decl {
+			key.local = nil
+			if len(attr.Name.Prefix) > 0 {
+				key.local = attr.Name.Local
+			}
+		} else if len(attr.Name.Prefix) > 0 {
+			key.uri = s.uri(attr.Name.Prefix)
+		}
+		s.keys = append(s.keys, key)
+	}
+	less := func(i, j int) bool {
+		a, b := &s.keys[i], &s.keys[j]
+		if a.decl != b.decl {
+			return a.decl
+		}
+		if c := bytes.Compare(a.uri, b.uri); c != 0 {
+			return c < 0
+		}
+		return bytes.Compare(a.local, b.local) < 0
+	}
+	if sort.SliceIsSorted(s.keys, less) {
+		return false
+	}
+	sort.SliceStable(s.keys, less)
+	for i := range s.keys {
+		attrs[i] = s.keys[i].attr
+	}
+	return true
+}
@@ -0,0 +1,94 @@
+package transform_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/muktihari/xmltokenizer"
+	"github.com/muktihari/xmltokenizer/transform"
+)
+
+func TestSortAttrs(t *testing.T) {
+	tt := []struct {
+		name     string
+		in       string
+		expected string
+	}{
+		{
+			name:     "sorted",
+			in:       `<a  b = '1' c="2">text</a >`,
+			expected: `<a  b = '1' c="2">text</a >`,
+		},
+		{
+			name:     "by local name",
+			in:       `<a z="1" y="2" x="3"/><b c='1' a="2">text</b>`,
+			expected: `<a x="3" y="2" z="1"/><b a="2" c="1">text</b>`,
+		},
+		{
+			name: "by namespace uri",
+			in: `<r xmlns:z="urn:a" xmlns:a="urn:b" xmlns="urn:d" z:k="1" a:k="2" k="3" xml:lang="en">
+  <e a:x="1" z:y="2"/>
+  <e xmlns:a="urn:0" a:x="1" z:y="2"/>
+  <e v:x="1" z:y="2"/>
+</r>`,
+			expected: `<r xmlns="urn:d" xmlns:a="urn:b" xmlns:z="urn:a" k="3" xml:lang="en" z:k="1" a:k="2">
+  <e z:y="2" a:x="1"/>
+  <e xmlns:a="urn:0" a:x="1" z:y="2"/>
+  <e z:y="2" v:x="1"/>
+</r>`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := transform.SortAttrs(&buf, strings.NewReader(tc.in)); err != nil {
+				t.Fatalf("expected nil, got: %v", err)
+			}
+			if diff := cmp.Diff(buf.String(), tc.expected); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
+
+func TestSortAttrsFunc(t *testing.T) {
+	in := `<r xmlns:p="urn:b"><skip xmlns:p="urn:a"><e/></skip><e p:b="1" a="2"/></r>`
+	fn := transform.SortAttrsFunc(func(token *xmltokenizer.Token, path []xmltokenizer.Name) (transform.Action, error) {
+		if string(token.Name.Full) == "skip" {
+			return transform.Skip, nil
+		}
+		return transform.Keep, nil
+	})
+
+	var buf bytes.Buffer
+	if err := transform.Transform(&buf, strings.NewReader(in), fn); err != nil {
+		t.Fatalf("expected nil, got: %v", err)
+	}
+	expected := `<r xmlns:p="urn:b"><e a="2" p:b="1"/></r>`
+	if diff := cmp.Diff(buf.String(), expected); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestSortAttrsFuncAttrAt(t *testing.T) {
+	tok := xmltokenizer.New(strings.NewReader(`<a z="1" x="2" y="3"/>`))
+	token, err := tok.Token()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := transform.SortAttrsFunc(nil)(&token, nil); err != nil {
+		t.Fatal(err)
+	}
+	if name := string(token.Attrs[0].Name.Full); name != "x" {
+		t.Fatalf("expected first attr: x, got: %s", name)
+	}
+	// The attributes are still found by their index in document order.
+	for i, name := range []string{"z", "x", "y"} {
+		if attr, ok := token.AttrAt(i); !ok || string(attr.Name.Full) != name {
+			t.Fatalf("[%d] expected attr: %s, got: %v, %t", i, name, attr, ok)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package transform
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/muktihari/xmltokenizer"
+)
+
+// StripNamespaces copies the XML from r to w byte-exact, except that the
+// namespace prefixes of elements and attributes are dropped and the xmlns and
+// xmlns:prefix declarations are removed. When dropping the prefixes results in
+// duplicate attribute names within an element, only the first is kept.
+func StripNamespaces(w io.Writer, r io.Reader, opts ...xmltokenizer.Option) error {
+	return Transform(w, r, func(token *xmltokenizer.Token, path []xmltokenizer.Name) (Action, error) {
+		if len(token.Name.Full) == 0 || token.Continued {
+			return Keep, nil
+		}
+		action := Keep
+		if len(token.Name.Prefix) != 0 {
+			token.Name.Full, action = token.Name.Local, Rewrite
+		}
+		attrs := token.Attrs[:0]
+	next:
+		for _, attr := range token.Attrs {
+			if isNamespaceDecl(attr.Name) {
+				action = Rewrite
+				continue
+			}
+			if len(attr.Name.Prefix) != 0 {
+				attr.Name.Full, action = attr.Name.Local, Rewrite
+			}
+			for i := range attrs {
+				if bytes.Equal(attrs[i].Name.Full, attr.Name.Full) {
+					continue next
+				}
+			}
+			attrs = append(attrs, attr)
+		}
+		token.Attrs = attrs
+		return action, nil
+	}, opts...)
+}
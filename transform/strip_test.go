@@ -0,0 +1,58 @@
+package transform_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/muktihari/xmltokenizer/transform"
+)
+
+func TestStripNamespaces(t *testing.T) {
+	tt := []struct {
+		name     string
+		in       string
+		expected string
+	}{
+		{
+			name: "soap envelope",
+			in: `<?xml version="1.0"?>
+<soap:Envelope xmlns:soap="http://www.w3.org/2003/05/soap-envelope" xmlns="urn:m">
+  <soap:Body soap:encodingStyle="urn:e">
+    <m:Price xmlns:m="urn:m"><![CDATA[1.5]]></m:Price>
+  </soap:Body>
+</soap:Envelope>
+`,
+			expected: `<?xml version="1.0"?>
+<Envelope>
+  <Body encodingStyle="urn:e">
+    <Price><![CDATA[1.5]]></Price>
+  </Body>
+</Envelope>
+`,
+		},
+		{
+			name:     "duplicate attributes",
+			in:       `<a x:id="1" y:id="2" id="3"/>`,
+			expected: `<a id="1"/>`,
+		},
+		{
+			name:     "no namespace",
+			in:       "<a  b = '1'>text</a >",
+			expected: "<a  b = '1'>text</a >",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := transform.StripNamespaces(&buf, strings.NewReader(tc.in)); err != nil {
+				t.Fatalf("expected nil, got: %v", err)
+			}
+			if diff := cmp.Diff(buf.String(), tc.expected); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
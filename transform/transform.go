@@ -0,0 +1,256 @@
+// Package transform rewrites XML streams on top of the xmltokenizer,
+// anything that is not rewritten is copied byte-exact.
+package transform
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+
+	"github.com/muktihari/xmltokenizer"
+)
+
+type errorString string
+
+func (e errorString) Error() string { return string(e) }
+
+const errInvalidPath = errorString("invalid path")
+
+// Action tells Transform what to do with a token.
+type Action int
+
+const (
+	// Keep copies the token byte-exact.
+	Keep Action = iota
+	// Rewrite writes the token's tag from its (modified) Name, Attrs and SelfClosing.
+	// The CharData following a start element is copied byte-exact, unless its Data
+	// has been replaced in which case the new Data is written instead.
+	Rewrite
+	// Skip drops the token, or the whole element including its
+	// subtree if the token is a start element. The CharData following a
+	// dropped element is kept since it belongs to the parent element.
+	Skip
+	// ReplaceContent writes the token's tag like Rewrite followed by its Data as
+	// the element's entire content, the element's subtree is dropped while its
+	// end element is kept. It is equal to Rewrite if the token is not an element.
+	ReplaceContent
+)
+
+// Func decides what to do with the given token, path is the names of its
+// ancestors. The token may be modified for Rewrite and ReplaceContent, the new
+// Data must be a valid XML CharData. The token and the path are only valid
+// during the invocation.
+type Func func(token *xmltokenizer.Token, path []xmltokenizer.Name) (Action, error)
+
+// Transform copies the XML from r to w, applying fn to every token. It stops on
+// the first error other than io.EOF and returns it.
+func Transform(w io.Writer, r io.Reader, fn Func, opts ...xmltokenizer.Option) error {
+	opts = append(opts[:len(opts):len(opts)], xmltokenizer.WithSpace())
+	tf := transformer{tok: xmltokenizer.New(r, opts...)}
+	bw := bufio.NewWriter(w)
+	tf.w = bw
+	if err := tf.run(fn); err != nil {
+		bw.Flush()
+		return err
+	}
+	return bw.Flush()
+}
+
+// writer is either a bufio.Writer for Transform or a bytes.Buffer for
+// FilteredReader.
+type writer interface {
+	io.Writer
+	io.StringWriter
+}
+
+type transformer struct {
+	tok  *xmltokenizer.Tokenizer
+	w    writer
+	path xmltokenizer.NameStack
+	buf  []byte
+}
+
+func (tf *transformer) run(fn Func) error {
+	for {
+		if err := tf.step(fn); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// step applies fn to the next token, it returns io.EOF once there is none.
+func (tf *transformer) step(fn Func) error {
+	token, err := tf.tok.Token()
+	tf.w.Write(tf.tok.Space())
+	if err != nil {
+		return err
+	}
+
+	raw := tf.tok.Raw()
+	isElement := len(token.Name.Full) > 0 && !token.Continued
+	data := token.Data
+	action, err := fn(&token, tf.path.Names())
+	if err != nil {
+		return err
+	}
+	if !isElement && action == ReplaceContent {
+		action = Rewrite
+	}
+
+	switch action {
+	case Keep:
+		tf.w.Write(raw)
+	case Rewrite:
+		if !isElement {
+			tf.w.Write(token.Data)
+			break
+		}
+		tf.buf = appendTag(tf.buf[:0], &token)
+		tf.w.Write(tf.buf)
+		tail := raw[tagEnd(raw):]
+		if token.IsEndElement || sameBytes(token.Data, data) {
+			tf.w.Write(tail)
+		} else {
+			tf.writeCharData(tail, token.Data)
+		}
+	case Skip:
+		if !isElement {
+			return nil
+		}
+		if !token.IsEndElement && !token.SelfClosing {
+			return tf.skipSubtree()
+		}
+		tf.w.Write(raw[tagEnd(raw):])
+	case ReplaceContent:
+		if token.IsEndElement {
+			tf.buf = appendTag(tf.buf[:0], &token)
+			tf.w.Write(tf.buf)
+			tf.w.Write(raw[tagEnd(raw):])
+			break
+		}
+		selfClosing := token.SelfClosing
+		token.SelfClosing = false
+		tf.buf = appendTag(tf.buf[:0], &token)
+		tf.buf = append(tf.buf, token.Data...)
+		tf.buf = append(tf.buf, "</"...)
+		tf.buf = append(tf.buf, token.Name.Full...)
+		tf.buf = append(tf.buf, '>')
+		tf.w.Write(tf.buf)
+		if !selfClosing {
+			return tf.skipSubtree()
+		}
+		tf.w.Write(raw[tagEnd(raw):])
+		return nil
+	}
+
+	if isElement {
+		switch {
+		case token.IsEndElement:
+			tf.path.Pop()
+		case !token.SelfClosing:
+			tf.path.Push(token.Name)
+		}
+	}
+	return nil
+}
+
+// writeCharData writes data replacing the CharData or CDATA in
+// tail while keeping the whitespace surrounding it.
+func (tf *transformer) writeCharData(tail, data []byte) {
+	const prefix, suffix = "<![CDATA[", "]]>"
+	content := xmltokenizer.TrimLeftSpace(tail)
+	tf.w.Write(tail[:len(tail)-len(content)])
+	trimmed := xmltokenizer.TrimRightSpace(content)
+	if bytes.HasPrefix(trimmed, []byte(prefix)) {
+		tf.w.WriteString(prefix)
+		tf.w.Write(data)
+		tf.w.WriteString(suffix)
+	} else {
+		tf.w.Write(data)
+	}
+	tf.w.Write(content[len(trimmed):])
+}
+
+// skipSubtree drops the tokens until the end element of the last start
+// element, then writes the CharData following that end element since it
+// belongs to the parent element.
+func (tf *transformer) skipSubtree() error {
+	for depth := 1; depth > 0; {
+		token, err := tf.tok.Token()
+		if err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return err
+		}
+		switch {
+		case len(token.Name.Full) == 0 || token.Continued || token.SelfClosing:
+		case token.IsEndElement:
+			depth--
+		default:
+			depth++
+		}
+	}
+	raw := tf.tok.Raw()
+	tf.w.Write(raw[tagEnd(raw):])
+	return nil
+}
+
+// appendTag appends the tag of the given element token to dst.
+func appendTag(dst []byte, token *xmltokenizer.Token) []byte {
+	if token.IsEndElement {
+		dst = append(dst, "</"...)
+		dst = append(dst, token.Name.Full...)
+		return append(dst, '>')
+	}
+	dst = append(dst, '<')
+	dst = append(dst, token.Name.Full...)
+	for i := range token.Attrs {
+		attr := &token.Attrs[i]
+		quote := byte('"')
+		if bytes.IndexByte(attr.Value, '"') != -1 {
+			quote = '\''
+		}
+		dst = append(dst, ' ')
+		dst = append(dst, attr.Name.Full...)
+		dst = append(dst, '=', quote)
+		dst = append(dst, attr.Value...)
+		dst = append(dst, quote)
+	}
+	if token.SelfClosing {
+		dst = append(dst, '/')
+	}
+	return append(dst, '>')
+}
+
+// tagEnd returns the index of the first character after the
+// closing > of the tag in raw, the > within quotes is ignored.
+func tagEnd(raw []byte) int {
+	for i := 0; i < len(raw); {
+		p := bytes.IndexAny(raw[i:], "\"'>")
+		if p == -1 {
+			break
+		}
+		i += p
+		if raw[i] == '>' {
+			return i + 1
+		}
+		q := bytes.IndexByte(raw[i+1:], raw[i])
+		if q == -1 {
+			break
+		}
+		i += q + 2
+	}
+	return len(raw)
+}
+
+// sameBytes reports whether a and b are the same slice of bytes.
+func sameBytes(a, b []byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	return len(a) == 0 || &a[0] == &b[0]
+}
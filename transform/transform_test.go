@@ -0,0 +1,96 @@
+package transform_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/muktihari/xmltokenizer"
+	"github.com/muktihari/xmltokenizer/transform"
+)
+
+func TestTransform(t *testing.T) {
+	const doc = "<?xml version=\"1.0\"?>\n<!-- c -->\n<a x='1'>\n  <b y=\"2\">text</b>\n  <c><d/></c>\n  <e><![CDATA[old]]></e>\n</a>\n"
+
+	tt := []struct {
+		name     string
+		fn       transform.Func
+		expected string
+		err      error
+	}{
+		{
+			name:     "keep is byte-exact",
+			fn:       func(*xmltokenizer.Token, []xmltokenizer.Name) (transform.Action, error) { return transform.Keep, nil },
+			expected: doc,
+		},
+		{
+			name: "skip subtree",
+			fn: func(token *xmltokenizer.Token, path []xmltokenizer.Name) (transform.Action, error) {
+				if string(token.Name.Local) == "c" && !token.IsEndElement {
+					return transform.Skip, nil
+				}
+				return transform.Keep, nil
+			},
+			expected: "<?xml version=\"1.0\"?>\n<!-- c -->\n<a x='1'>\n  <b y=\"2\">text</b>\n  \n  <e><![CDATA[old]]></e>\n</a>\n",
+		},
+		{
+			name: "rewrite tag and data",
+			fn: func(token *xmltokenizer.Token, path []xmltokenizer.Name) (transform.Action, error) {
+				switch string(token.Name.Local) {
+				case "b":
+					token.Name.Full = []byte("B")
+					if !token.IsEndElement {
+						token.Attrs[0].Value = []byte(`"q"`)
+						token.Data = []byte("new")
+					}
+					return transform.Rewrite, nil
+				case "e":
+					token.Data = []byte("new")
+					return transform.Rewrite, nil
+				}
+				return transform.Keep, nil
+			},
+			expected: "<?xml version=\"1.0\"?>\n<!-- c -->\n<a x='1'>\n  <B y='\"q\"'>new</B>\n  <c><d/></c>\n  <e><![CDATA[new]]></e>\n</a>\n",
+		},
+		{
+			name: "replace content",
+			fn: func(token *xmltokenizer.Token, path []xmltokenizer.Name) (transform.Action, error) {
+				if len(path) == 1 && string(token.Name.Local) == "c" {
+					token.Data = []byte("x")
+					return transform.ReplaceContent, nil
+				}
+				return transform.Keep, nil
+			},
+			expected: "<?xml version=\"1.0\"?>\n<!-- c -->\n<a x='1'>\n  <b y=\"2\">text</b>\n  <c>x</c>\n  <e><![CDATA[old]]></e>\n</a>\n",
+		},
+		{
+			name: "func error",
+			fn: func(token *xmltokenizer.Token, path []xmltokenizer.Name) (transform.Action, error) {
+				if len(path) == 1 {
+					return transform.Keep, errors.New("stop")
+				}
+				return transform.Keep, nil
+			},
+			expected: "<?xml version=\"1.0\"?>\n<!-- c -->\n<a x='1'>\n  ",
+			err:      errors.New("stop"),
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, size := range []int{1, 4096} {
+				var buf bytes.Buffer
+				err := transform.Transform(&buf, strings.NewReader(doc), tc.fn,
+					xmltokenizer.WithReadBufferSize(size))
+				if (err == nil) != (tc.err == nil) {
+					t.Fatalf("expected error: %v, got: %v", tc.err, err)
+				}
+				if diff := cmp.Diff(buf.String(), tc.expected); diff != "" {
+					t.Fatalf("read buffer size %d: %s", size, diff)
+				}
+			}
+		})
+	}
+}
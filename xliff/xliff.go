@@ -0,0 +1,230 @@
+// Package xliff streams the translation units of XLIFF 1.2 and 2.0 documents,
+// the interchange format of localization tools, using the xmltokenizer. Only
+// the unit being read is held in memory, so translation files of any size can
+// be processed.
+//
+// The content of sources and targets is kept as it is written in the
+// document, including the inline markup such as <g>, <x/>, <ph> or <pc>, so
+// it can be written back, or tokenized, without loss.
+package xliff
+
+import (
+	"io"
+	"strconv"
+
+	"github.com/muktihari/xmltokenizer"
+)
+
+// File describes the <file> enclosing the units, the languages are taken from
+// the <file> in XLIFF 1.2 and from the root <xliff> in XLIFF 2.0.
+type File struct {
+	ID             string // original attribute in XLIFF 1.2.
+	SourceLanguage string
+	TargetLanguage string
+}
+
+// Unit is a <trans-unit> in XLIFF 1.2 or a <unit> in XLIFF 2.0.
+type Unit struct {
+	ID       string
+	Name     string // resname attribute in XLIFF 1.2.
+	Notes    []Note
+	Segments []Segment // A single segment in XLIFF 1.2.
+}
+
+// Segment is a <segment>, or an <ignorable>, of a unit in XLIFF 2.0, or the
+// content of a unit in XLIFF 1.2.
+type Segment struct {
+	ID     string // ID of the unit in XLIFF 1.2.
+	State  string // state attribute of the <segment> in XLIFF 2.0, or of the <target> in XLIFF 1.2, empty when omitted.
+	Source []byte // Content of <source> between its tags, escaped and with the inline markup.
+	Target []byte // Content of <target> between its tags, nil if there is no target.
+}
+
+// Note is a <note>.
+type Note struct {
+	ID       string
+	From     string // from attribute in XLIFF 1.2.
+	Category string // category attribute in XLIFF 2.0.
+	Priority int    // Zero when omitted or invalid.
+	Text     string
+}
+
+// Reader reads the units of a document.
+type Reader struct {
+	tok     *xmltokenizer.Tokenizer
+	version string
+	file    File
+	srcLang string
+	trgLang string
+}
+
+// NewReader creates a Reader reading the document from r, the options are
+// passed to the underlying Tokenizer.
+func NewReader(r io.Reader, opts ...xmltokenizer.Option) *Reader {
+	return &Reader{tok: xmltokenizer.New(r, opts...)}
+}
+
+// Version returns the version attribute of the root element, e.g. "1.2" or
+// "2.0", once the first unit has been read.
+func (r *Reader) Version() string { return r.version }
+
+// File returns the file enclosing the unit last returned by Next.
+func (r *Reader) File() File { return r.file }
+
+// Next returns the next unit, it returns io.EOF at the end of the document.
+// Units within groups are returned in document order.
+func (r *Reader) Next() (Unit, error) {
+	for {
+		token, err := r.tok.Token()
+		if err != nil {
+			return Unit{}, err
+		}
+		if len(token.Name.Full) == 0 || token.IsEndElement {
+			continue
+		}
+		switch string(token.Name.Local) {
+		case "xliff":
+			r.version = attrValue(&token, "version")
+			r.srcLang = attrValue(&token, "srcLang")
+			r.trgLang = attrValue(&token, "trgLang")
+		case "file":
+			r.file = File{
+				ID:             attrValue(&token, "id"),
+				SourceLanguage: r.srcLang,
+				TargetLanguage: r.trgLang,
+			}
+			if r.file.ID == "" {
+				r.file.ID = attrValue(&token, "original")
+			}
+			if lang := attrValue(&token, "source-language"); lang != "" {
+				r.file.SourceLanguage = lang
+			}
+			if lang := attrValue(&token, "target-language"); lang != "" {
+				r.file.TargetLanguage = lang
+			}
+		case "trans-unit", "unit":
+			return r.unit(&token)
+		}
+	}
+}
+
+// unit reads the unit started by se up to its end element. Only the children
+// of the unit, and of its <segment>, <ignorable> and <notes> children, are
+// considered, anything else such as <alt-trans> or <originalData> is skipped.
+func (r *Reader) unit(se *xmltokenizer.Token) (Unit, error) {
+	u := Unit{ID: attrValue(se, "id"), Name: attrValue(se, "resname")}
+	if u.Name == "" {
+		u.Name = attrValue(se, "name")
+	}
+	var parent string // local name of the open child of the unit
+	for depth := 1; depth > 0 && !se.SelfClosing; {
+		token, err := r.tok.Token()
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return u, err
+		}
+		if len(token.Name.Full) == 0 {
+			continue
+		}
+		if token.IsEndElement {
+			depth, parent = depth-1, ""
+			continue
+		}
+
+		local := string(token.Name.Local)
+		switch {
+		case depth == 1 && (local == "segment" || local == "ignorable" || local == "notes"):
+			if local != "notes" {
+				u.Segments = append(u.Segments, Segment{
+					ID:    attrValue(&token, "id"),
+					State: attrValue(&token, "state"),
+				})
+			}
+			if !token.SelfClosing {
+				depth, parent = depth+1, local
+			}
+		case depth == 1 && (local == "source" || local == "target"):
+			if len(u.Segments) == 0 {
+				u.Segments = append(u.Segments, Segment{ID: u.ID})
+			}
+			if local == "target" {
+				u.Segments[0].State = attrValue(&token, "state")
+			}
+			err = r.content(&u.Segments[0], &token)
+		case depth == 2 && parent != "notes" && (local == "source" || local == "target"):
+			err = r.content(&u.Segments[len(u.Segments)-1], &token)
+		case local == "note" && (depth == 1 || parent == "notes"):
+			u.Notes = append(u.Notes, r.note(&token))
+			_, err = r.tok.Subtree(&token)
+		default:
+			_, err = r.tok.Subtree(&token)
+		}
+		if err != nil {
+			return u, err
+		}
+	}
+	return u, nil
+}
+
+// content reads the <source> or <target> started by se into seg.
+func (r *Reader) content(seg *Segment, se *xmltokenizer.Token) error {
+	isSource := string(se.Name.Local) == "source"
+	raw, err := r.tok.Subtree(se)
+	if err != nil {
+		return err
+	}
+	b := []byte{}
+	if !se.SelfClosing {
+		inner := raw[tagLen(raw) : len(raw)-len("</>")-len(se.Name.Full)]
+		b = append(b, inner...)
+	}
+	if isSource {
+		seg.Source = b
+	} else {
+		seg.Target = b
+	}
+	return nil
+}
+
+// note returns the note started by token, which must be the last token
+// returned by Token.
+func (r *Reader) note(token *xmltokenizer.Token) Note {
+	n := Note{
+		ID:       attrValue(token, "id"),
+		From:     attrValue(token, "from"),
+		Category: attrValue(token, "category"),
+		Text:     string(token.DataUnescaped(nil)),
+	}
+	n.Priority, _ = strconv.Atoi(attrValue(token, "priority"))
+	return n
+}
+
+// tagLen returns the length of the start tag at the beginning of raw.
+func tagLen(raw []byte) int {
+	var quote byte
+	for i, c := range raw {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '>':
+			return i + 1
+		}
+	}
+	return len(raw)
+}
+
+// attrValue returns the unescaped value of the attribute with the given local name.
+func attrValue(token *xmltokenizer.Token, local string) string {
+	for i := range token.Attrs {
+		if string(token.Attrs[i].Name.Local) == local {
+			return string(token.Attrs[i].ValueUnescaped(nil))
+		}
+	}
+	return ""
+}
@@ -0,0 +1,129 @@
+package xliff_test
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/muktihari/xmltokenizer/xliff"
+)
+
+func readAll(t *testing.T, doc string) (*xliff.Reader, []xliff.Unit, []xliff.File) {
+	t.Helper()
+	r := xliff.NewReader(strings.NewReader(doc))
+	var units []xliff.Unit
+	var files []xliff.File
+	for {
+		u, err := r.Next()
+		if err == io.EOF {
+			return r, units, files
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		units = append(units, u)
+		files = append(files, r.File())
+	}
+}
+
+func TestReader12(t *testing.T) {
+	const doc = `<?xml version="1.0" encoding="UTF-8"?>
+<xliff version="1.2" xmlns="urn:oasis:names:tc:xliff:document:1.2">
+  <file original="app.properties" source-language="en" target-language="fr" datatype="plaintext">
+    <body>
+      <group id="menu">
+        <trans-unit id="1" resname="greeting">
+          <source>Hello <g id="b">world</g> &amp; <x id="br"/>friends</source>
+          <target state="translated">Bonjour <g id="b">le monde</g></target>
+          <note from="dev" priority="2">Shown on the &lt;home&gt; screen</note>
+          <alt-trans><source>ignored</source><target>ignored</target></alt-trans>
+        </trans-unit>
+      </group>
+      <trans-unit id="2">
+        <source>Bye</source>
+      </trans-unit>
+    </body>
+  </file>
+</xliff>`
+
+	r, units, files := readAll(t, doc)
+	expected := []xliff.Unit{
+		{
+			ID: "1", Name: "greeting",
+			Notes: []xliff.Note{{From: "dev", Priority: 2, Text: "Shown on the <home> screen"}},
+			Segments: []xliff.Segment{{
+				ID: "1", State: "translated",
+				Source: []byte(`Hello <g id="b">world</g> &amp; <x id="br"/>friends`),
+				Target: []byte(`Bonjour <g id="b">le monde</g>`),
+			}},
+		},
+		{ID: "2", Segments: []xliff.Segment{{ID: "2", Source: []byte("Bye")}}},
+	}
+	if diff := cmp.Diff(units, expected); diff != "" {
+		t.Fatal(diff)
+	}
+	file := xliff.File{ID: "app.properties", SourceLanguage: "en", TargetLanguage: "fr"}
+	if diff := cmp.Diff(files[1], file); diff != "" {
+		t.Fatal(diff)
+	}
+	if v := r.Version(); v != "1.2" {
+		t.Fatalf("expected version: 1.2, got: %s", v)
+	}
+}
+
+func TestReader20(t *testing.T) {
+	const doc = `<xliff xmlns="urn:oasis:names:tc:xliff:document:2.0" version="2.0" srcLang="en" trgLang="de">
+  <file id="f1">
+    <unit id="u1" name="title">
+      <notes>
+        <note id="n1" category="context">Window title</note>
+      </notes>
+      <originalData><data id="d1">&lt;b&gt;</data></originalData>
+      <segment id="s1" state="final">
+        <source>Open <pc id="1" dataRefStart="d1">file</pc></source>
+        <target>Datei <pc id="1" dataRefStart="d1">öffnen</pc></target>
+      </segment>
+      <ignorable>
+        <source> </source>
+      </ignorable>
+      <segment id="s2">
+        <source>Cancel</source>
+        <target/>
+      </segment>
+    </unit>
+  </file>
+</xliff>`
+
+	r, units, files := readAll(t, doc)
+	expected := []xliff.Unit{{
+		ID: "u1", Name: "title",
+		Notes: []xliff.Note{{ID: "n1", Category: "context", Text: "Window title"}},
+		Segments: []xliff.Segment{
+			{
+				ID: "s1", State: "final",
+				Source: []byte(`Open <pc id="1" dataRefStart="d1">file</pc>`),
+				Target: []byte(`Datei <pc id="1" dataRefStart="d1">öffnen</pc>`),
+			},
+			{Source: []byte(" ")},
+			{ID: "s2", Source: []byte("Cancel"), Target: []byte{}},
+		},
+	}}
+	if diff := cmp.Diff(units, expected); diff != "" {
+		t.Fatal(diff)
+	}
+	file := xliff.File{ID: "f1", SourceLanguage: "en", TargetLanguage: "de"}
+	if diff := cmp.Diff(files[0], file); diff != "" {
+		t.Fatal(diff)
+	}
+	if v := r.Version(); v != "2.0" {
+		t.Fatalf("expected version: 2.0, got: %s", v)
+	}
+}
+
+func TestReaderUnclosed(t *testing.T) {
+	_, err := xliff.NewReader(strings.NewReader(`<unit id="1"><segment><source>a`)).Next()
+	if err != io.ErrUnexpectedEOF {
+		t.Fatalf("expected error: %v, got: %v", io.ErrUnexpectedEOF, err)
+	}
+}
@@ -0,0 +1,109 @@
+// Package xmlhttp ties the xmltokenizer to net/http: it tokenizes the body of
+// a response honoring its Content-Encoding and Content-Type charset, and it
+// streams transformed XML as the body of a handler's response.
+package xmlhttp
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/muktihari/xmltokenizer"
+	"github.com/muktihari/xmltokenizer/transform"
+)
+
+type errorString string
+
+func (e errorString) Error() string { return string(e) }
+
+const errUnsupportedContentEncoding = errorString("unsupported content encoding")
+
+// NewReader returns a reader of the body of resp in UTF-8: the body is
+// decompressed according to its Content-Encoding, identity and gzip are
+// supported, and decoded from the charset parameter of its Content-Type, which
+// takes precedence over the document's own declaration, see
+// xmltokenizer.NewCharsetReader for charsetReader. The encoding is detected
+// from the document when there is no charset parameter. Closing resp.Body is
+// still the responsibility of the caller.
+func NewReader(resp *http.Response, charsetReader func(charset string, input io.Reader) (io.Reader, error)) (io.Reader, error) {
+	var r io.Reader = resp.Body
+	for _, coding := range strings.Split(resp.Header.Get("Content-Encoding"), ",") {
+		switch coding = strings.ToLower(strings.TrimSpace(coding)); coding {
+		case "", "identity":
+		case "gzip", "x-gzip":
+			zr, err := gzip.NewReader(r)
+			if err != nil {
+				return nil, err
+			}
+			r = zr
+		default:
+			return nil, fmt.Errorf("%q: %w", coding, errUnsupportedContentEncoding)
+		}
+	}
+
+	var charset string
+	if ct := resp.Header.Get("Content-Type"); ct != "" {
+		if _, params, err := mime.ParseMediaType(ct); err == nil {
+			charset = params["charset"]
+		}
+	}
+	return xmltokenizer.NewCharsetReader(r, charset, charsetReader)
+}
+
+// NewTokenizer creates a Tokenizer reading the body of resp, see NewReader.
+func NewTokenizer(resp *http.Response, opts ...xmltokenizer.Option) (*xmltokenizer.Tokenizer, error) {
+	r, err := NewReader(resp, nil)
+	if err != nil {
+		return nil, err
+	}
+	return xmltokenizer.New(r, opts...), nil
+}
+
+// Handler returns an http.Handler responding with the XML returned by open for
+// the request, transformed by fn, see transform.Transform. The response is
+// streamed as it is transformed with the Content-Type "application/xml;
+// charset=utf-8", unless already set. The reader returned by open is closed
+// once done if it is an io.Closer, such as an *os.File.
+//
+// The status is 500 Internal Server Error if open fails, or if the transform
+// fails before anything is written, otherwise the response is aborted with
+// http.ErrAbortHandler so the client sees it truncated rather than complete.
+func Handler(open func(r *http.Request) (io.Reader, error), fn transform.Func, opts ...xmltokenizer.Option) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r, err := open(req)
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		if c, ok := r.(io.Closer); ok {
+			defer c.Close()
+		}
+
+		if w.Header().Get("Content-Type") == "" {
+			w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+		}
+		cw := &countingWriter{w: w}
+		if err := transform.Transform(cw, r, fn, opts...); err != nil {
+			if cw.n == 0 {
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				return
+			}
+			panic(http.ErrAbortHandler)
+		}
+	})
+}
+
+// countingWriter counts the bytes written to w.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
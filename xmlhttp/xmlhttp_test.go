@@ -0,0 +1,176 @@
+package xmlhttp_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/muktihari/xmltokenizer"
+	"github.com/muktihari/xmltokenizer/transform"
+	"github.com/muktihari/xmltokenizer/xmlhttp"
+)
+
+func gzipped(s string) []byte {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write([]byte(s))
+	zw.Close()
+	return buf.Bytes()
+}
+
+func TestNewTokenizer(t *testing.T) {
+	tt := []struct {
+		name     string
+		header   http.Header
+		body     []byte
+		expected string
+		err      error
+	}{
+		{
+			name:     "plain",
+			header:   http.Header{"Content-Type": {"application/xml"}},
+			body:     []byte(`<a>café</a>`),
+			expected: "café",
+		},
+		{
+			name:     "charset overrides declaration",
+			header:   http.Header{"Content-Type": {`text/xml; charset="ISO-8859-1"`}},
+			body:     []byte("<?xml version=\"1.0\" encoding=\"UTF-8\"?><a>caf\xE9</a>"),
+			expected: "café",
+		},
+		{
+			name:     "gzip",
+			header:   http.Header{"Content-Type": {"application/xml; charset=utf-8"}, "Content-Encoding": {"gzip"}},
+			body:     gzipped(`<a>café</a>`),
+			expected: "café",
+		},
+		{
+			name:   "corrupted gzip",
+			header: http.Header{"Content-Encoding": {"gzip"}},
+			body:   []byte(`<a>café</a>`),
+			err:    errors.New("gzip: invalid header"),
+		},
+		{
+			name:   "unsupported content encoding",
+			header: http.Header{"Content-Encoding": {"br"}},
+			body:   []byte(`<a/>`),
+			err:    errors.New(`"br": unsupported content encoding`),
+		},
+		{
+			name:   "unsupported charset",
+			header: http.Header{"Content-Type": {"application/xml; charset=ebcdic"}},
+			body:   []byte(`<a/>`),
+			err:    errors.New(`"ebcdic": unsupported encoding`),
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := &http.Response{Header: tc.header, Body: io.NopCloser(bytes.NewReader(tc.body))}
+			tok, err := xmlhttp.NewTokenizer(resp)
+			if (err == nil) != (tc.err == nil) || (err != nil && err.Error() != tc.err.Error()) {
+				t.Fatalf("expected error: %v, got: %v", tc.err, err)
+			}
+			if err != nil {
+				return
+			}
+			token, err := tok.Token()
+			if err == nil && token.SelfClosing && len(token.Name.Full) == 0 {
+				token, err = tok.Token() // skip the XML declaration
+			}
+			if err != nil {
+				t.Fatalf("expected nil, got: %v", err)
+			}
+			if string(token.Data) != tc.expected {
+				t.Fatalf("expected: %q, got: %q", tc.expected, token.Data)
+			}
+		})
+	}
+}
+
+func TestHandler(t *testing.T) {
+	redact := func(token *xmltokenizer.Token, path []xmltokenizer.Name) (transform.Action, error) {
+		if string(token.Name.Local) == "password" {
+			return transform.Skip, nil
+		}
+		return transform.Keep, nil
+	}
+
+	t.Run("transform upstream response", func(t *testing.T) {
+		upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/xml; charset=iso-8859-1")
+			w.Header().Set("Content-Encoding", "gzip")
+			w.Write(gzipped("<user><name>Jos\xE9</name><password>x</password></user>"))
+		}))
+		defer upstream.Close()
+
+		h := xmlhttp.Handler(func(r *http.Request) (io.Reader, error) {
+			resp, err := http.Get(upstream.URL)
+			if err != nil {
+				return nil, err
+			}
+			rd, err := xmlhttp.NewReader(resp, nil)
+			if err != nil {
+				resp.Body.Close()
+				return nil, err
+			}
+			return struct {
+				io.Reader
+				io.Closer
+			}{rd, resp.Body}, nil
+		}, redact)
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status: %d, got: %d", http.StatusOK, rec.Code)
+		}
+		if ct := rec.Header().Get("Content-Type"); ct != "application/xml; charset=utf-8" {
+			t.Fatalf("expected content type: %q, got: %q", "application/xml; charset=utf-8", ct)
+		}
+		if expected := "<user><name>José</name></user>"; rec.Body.String() != expected {
+			t.Fatalf("expected: %q, got: %q", expected, rec.Body.String())
+		}
+	})
+
+	t.Run("open error", func(t *testing.T) {
+		h := xmlhttp.Handler(func(r *http.Request) (io.Reader, error) {
+			return nil, errors.New("not found")
+		}, redact)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		if rec.Code != http.StatusInternalServerError {
+			t.Fatalf("expected status: %d, got: %d", http.StatusInternalServerError, rec.Code)
+		}
+	})
+
+	t.Run("transform error before writing", func(t *testing.T) {
+		h := xmlhttp.Handler(func(r *http.Request) (io.Reader, error) {
+			return strings.NewReader("<user><password>x"), nil
+		}, func(token *xmltokenizer.Token, path []xmltokenizer.Name) (transform.Action, error) {
+			return transform.Skip, nil
+		})
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		if rec.Code != http.StatusInternalServerError {
+			t.Fatalf("expected status: %d, got: %d", http.StatusInternalServerError, rec.Code)
+		}
+	})
+
+	t.Run("transform error after writing", func(t *testing.T) {
+		h := xmlhttp.Handler(func(r *http.Request) (io.Reader, error) {
+			return strings.NewReader("<user><password>x"), nil
+		}, redact)
+		defer func() {
+			if r := recover(); r != http.ErrAbortHandler {
+				t.Fatalf("expected panic: %v, got: %v", http.ErrAbortHandler, r)
+			}
+		}()
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+}
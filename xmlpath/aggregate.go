@@ -0,0 +1,166 @@
+package xmlpath
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+
+	"github.com/muktihari/xmltokenizer"
+)
+
+// Reducer consumes the values matched by a path, see Aggregator.
+type Reducer interface {
+	// Add adds the value of a match, only valid during the invocation.
+	Add(value []byte) error
+}
+
+// Count counts the matches.
+type Count struct {
+	N int
+}
+
+func (c *Count) Add(value []byte) error { c.N++; return nil }
+
+// Sum sums the matched numeric values.
+type Sum struct {
+	Sum float64
+	N   int
+}
+
+func (s *Sum) Add(value []byte) error {
+	v, err := parseFloat(value)
+	if err != nil {
+		return err
+	}
+	s.Sum += v
+	s.N++
+	return nil
+}
+
+// Mean returns the arithmetic mean of the values, or NaN if there is none.
+func (s *Sum) Mean() float64 {
+	if s.N == 0 {
+		return math.NaN()
+	}
+	return s.Sum / float64(s.N)
+}
+
+// Min keeps the minimum of the matched numeric values.
+type Min struct {
+	Value float64
+	N     int
+}
+
+func (m *Min) Add(value []byte) error {
+	v, err := parseFloat(value)
+	if err != nil {
+		return err
+	}
+	if m.N == 0 || v < m.Value {
+		m.Value = v
+	}
+	m.N++
+	return nil
+}
+
+// Max keeps the maximum of the matched numeric values.
+type Max struct {
+	Value float64
+	N     int
+}
+
+func (m *Max) Add(value []byte) error {
+	v, err := parseFloat(value)
+	if err != nil {
+		return err
+	}
+	if m.N == 0 || v > m.Value {
+		m.Value = v
+	}
+	m.N++
+	return nil
+}
+
+// Distinct counts the occurrences of each distinct matched value.
+type Distinct struct {
+	Values map[string]int
+}
+
+func (d *Distinct) Add(value []byte) error {
+	if d.Values == nil {
+		d.Values = make(map[string]int)
+	}
+	d.Values[string(value)]++
+	return nil
+}
+
+func parseFloat(value []byte) (float64, error) {
+	return strconv.ParseFloat(string(xmltokenizer.TrimSpace(value)), 64)
+}
+
+// Aggregator feeds the values matched by paths to reducers, so analytics such
+// as the mean elevation of all track points run in a single pass over
+// arbitrarily large documents:
+//
+//	var ele xmlpath.Sum
+//	var agg xmlpath.Aggregator
+//	agg.Add("//trkpt/ele", &ele)
+//	err := agg.Run(f)
+//	fmt.Println(ele.Mean())
+//
+// The value of an element is its leading CharData, i.e. the Data of its start
+// element, empty if it is self-closing since the Data is then the CharData
+// following it, and the value of an attribute is its Value, both as they
+// appear in the document. The zero value is ready to use.
+type Aggregator struct {
+	exprs    []string
+	reducers [][]Reducer
+}
+
+// Add binds the reducers to the path expression.
+func (a *Aggregator) Add(expr string, reducers ...Reducer) error {
+	if _, err := compile(expr); err != nil {
+		return err
+	}
+	a.exprs = append(a.exprs, expr)
+	a.reducers = append(a.reducers, reducers)
+	return nil
+}
+
+// Run tokenizes r and feeds the matched values to the reducers. It stops on
+// the first error other than io.EOF, including the ones returned by reducers.
+func (a *Aggregator) Run(r io.Reader, opts ...xmltokenizer.Option) error {
+	set, err := CompileSet(a.exprs...)
+	if err != nil {
+		return err
+	}
+	tok := xmltokenizer.New(r, opts...)
+	for {
+		token, err := tok.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		matched, exit := set.Step(&token)
+		if exit {
+			continue
+		}
+		for _, i := range matched {
+			value := token.Data
+			if token.SelfClosing {
+				value = nil
+			}
+			if attr, ok := set.Attr(i, &token); ok {
+				value = attr.Value
+			}
+			for _, reducer := range a.reducers[i] {
+				if err := reducer.Add(value); err != nil {
+					return fmt.Errorf("%s at line %d: %w", a.exprs[i], token.Begin.Line, err)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package xmlpath_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/muktihari/xmltokenizer/xmlpath"
+)
+
+func TestAggregator(t *testing.T) {
+	var (
+		points  xmlpath.Count
+		ele     xmlpath.Sum
+		minEle  xmlpath.Min
+		maxEle  xmlpath.Max
+		lons    xmlpath.Distinct
+		noneEle xmlpath.Sum
+		agg     xmlpath.Aggregator
+	)
+	for _, b := range []struct {
+		expr     string
+		reducers []xmlpath.Reducer
+	}{
+		{"//trkpt", []xmlpath.Reducer{&points}},
+		{"//trkpt/ele", []xmlpath.Reducer{&ele, &minEle, &maxEle}},
+		{"//*/@lon", []xmlpath.Reducer{&lons}},
+		{"//rte/ele", []xmlpath.Reducer{&noneEle}},
+	} {
+		if err := agg.Add(b.expr, b.reducers...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := agg.Run(strings.NewReader(gpxDoc)); err != nil {
+		t.Fatalf("expected nil, got: %v", err)
+	}
+
+	if points.N != 3 {
+		t.Fatalf("expected count: 3, got: %d", points.N)
+	}
+	if ele.Mean() != 110 || ele.N != 2 {
+		t.Fatalf("expected mean: 110 of 2, got: %g of %d", ele.Mean(), ele.N)
+	}
+	if minEle.Value != 100 || maxEle.Value != 120 {
+		t.Fatalf("expected min/max: 100/120, got: %g/%g", minEle.Value, maxEle.Value)
+	}
+	if diff := cmp.Diff(lons.Values, map[string]int{"8.5": 1, "8.6": 1, "8.7": 1, "2": 1}); diff != "" {
+		t.Fatal(diff)
+	}
+	if mean := noneEle.Mean(); mean == mean {
+		t.Fatalf("expected NaN, got: %g", mean)
+	}
+}
+
+func TestAggregatorSelfClosing(t *testing.T) {
+	var values xmlpath.Distinct
+	var agg xmlpath.Aggregator
+	if err := agg.Add("//v", &values); err != nil {
+		t.Fatal(err)
+	}
+	if err := agg.Run(strings.NewReader(`<r><v/>5<v>7</v>8<v a="/>"/> 9 </r>`)); err != nil {
+		t.Fatalf("expected nil, got: %v", err)
+	}
+	if diff := cmp.Diff(values.Values, map[string]int{"": 2, "7": 1}); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestAggregatorError(t *testing.T) {
+	var agg xmlpath.Aggregator
+	if err := agg.Add("trkpt", &xmlpath.Count{}); err == nil {
+		t.Fatalf("expected error, got nil")
+	}
+	var sum xmlpath.Sum
+	if err := agg.Add("//*/@lat", &sum); err != nil {
+		t.Fatal(err)
+	}
+	err := agg.Run(strings.NewReader(`<a lat="1"><b lat="x"/></a>`))
+	if err == nil || !strings.Contains(err.Error(), "//*/@lat at line 1") {
+		t.Fatalf("expected parse error, got: %v", err)
+	}
+}
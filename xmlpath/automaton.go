@@ -0,0 +1,123 @@
+package xmlpath
+
+import "github.com/muktihari/xmltokenizer"
+
+// state is a position of the automaton: the number of steps of
+// the path at index path that may have been matched so far.
+type state struct {
+	path int
+	step int
+}
+
+// automaton matches tokens against many paths at once: the active states
+// of every path are evaluated together once per element.
+type automaton struct {
+	paths []*path
+
+	// states holds, for every open element, its active states delimited
+	// by bounds. The initial states of the root are at bounds[0].
+	states []state
+	bounds []int
+
+	// matched holds, for every open element, the indexes of the paths
+	// matching it delimited by matchedBounds.
+	matched       []int
+	matchedBounds []int
+	inside        []int // number of open matched elements of each path
+	entered       []int // paths matched by the last start element
+}
+
+func newAutomaton(paths []*path) *automaton {
+	a := &automaton{paths: paths, inside: make([]int, len(paths))}
+	a.reset()
+	return a
+}
+
+func (a *automaton) reset() {
+	a.states = a.states[:0]
+	for i := range a.paths {
+		a.states = append(a.states, state{path: i})
+	}
+	a.bounds = append(a.bounds[:0], 0)
+	a.matched = a.matched[:0]
+	a.matchedBounds = a.matchedBounds[:0]
+	clear(a.inside)
+	a.entered = a.entered[:0]
+}
+
+// step feeds the next token, it returns the indexes of the paths matching
+// the token if it is a start element, or matching its start element if it is
+// an end element; exit reports the latter.
+func (a *automaton) step(token *xmltokenizer.Token) (matched []int, exit bool) {
+	if len(token.Name.Full) == 0 || token.Continued {
+		return nil, false
+	}
+	if token.IsEndElement {
+		if len(a.matchedBounds) == 0 {
+			return nil, false // unbalanced, nothing to pop
+		}
+		lo := a.matchedBounds[len(a.matchedBounds)-1]
+		a.entered = append(a.entered[:0], a.matched[lo:]...)
+		a.matched = a.matched[:lo]
+		a.matchedBounds = a.matchedBounds[:len(a.matchedBounds)-1]
+		a.states = a.states[:a.bounds[len(a.bounds)-1]]
+		a.bounds = a.bounds[:len(a.bounds)-1]
+		for _, i := range a.entered {
+			a.inside[i]--
+		}
+		return a.entered, true
+	}
+
+	// Compute the states of this element from its parent's.
+	start := len(a.states)
+	a.entered = a.entered[:0]
+	for _, s := range a.states[a.bounds[len(a.bounds)-1]:start] {
+		p := a.paths[s.path]
+		st := &p.steps[s.step]
+		if st.descendant {
+			a.states = appendState(a.states, start, s)
+		}
+		if !st.match(token) {
+			continue
+		}
+		if s.step+1 < len(p.steps) {
+			a.states = appendState(a.states, start, state{path: s.path, step: s.step + 1})
+		} else if (p.attr == nil || findAttr(token, p.attr) != nil) && !contains(a.entered, s.path) {
+			a.entered = append(a.entered, s.path)
+		}
+	}
+
+	if token.SelfClosing {
+		a.states = a.states[:start]
+		return a.entered, false
+	}
+	a.bounds = append(a.bounds, start)
+	a.matchedBounds = append(a.matchedBounds, len(a.matched))
+	a.matched = append(a.matched, a.entered...)
+	for _, i := range a.entered {
+		a.inside[i]++
+	}
+	return a.entered, false
+}
+
+// depth returns the number of open elements.
+func (a *automaton) depth() int { return len(a.matchedBounds) }
+
+// appendState appends s to the states starting at start unless it is already there.
+func appendState(states []state, start int, s state) []state {
+	for _, v := range states[start:] {
+		if v == s {
+			return states
+		}
+	}
+	return append(states, s)
+}
+
+func contains(s []int, v int) bool {
+	for _, x := range s {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
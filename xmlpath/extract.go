@@ -0,0 +1,163 @@
+package xmlpath
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+
+	"github.com/muktihari/xmltokenizer"
+)
+
+// Extractor flattens a document into records: one record per element matched
+// by a record path, with one field per value path relative to it. For example
+// the record path "//trkpt" with the value paths "@lat", "@lon" and "ele"
+// turns every track point into a row of a table.
+//
+// A value path is a path without its leading "/", "." selects the record
+// element itself and a leading "//" selects among all its descendants. A field
+// holds the first match within the record, or is empty if there is none. The
+// values are taken as they appear in the document, see Aggregator. A record
+// nested in another record is part of the outer one. An Extractor is not safe
+// for concurrent use.
+type Extractor struct {
+	set    *Set
+	fields []string
+	record []string
+	found  []bool
+	open   int
+}
+
+// NewExtractor compiles the record path and the value paths into an Extractor.
+func NewExtractor(record string, fields ...string) (*Extractor, error) {
+	exprs := make([]string, 0, 1+len(fields))
+	exprs = append(exprs, record)
+	for _, field := range fields {
+		switch {
+		case field == ".":
+			exprs = append(exprs, record)
+		case field == "" || (field[0] == '/' && (len(field) == 1 || field[1] != '/')):
+			return nil, fmt.Errorf("%q: value path must be relative: %w", field, errInvalidPath)
+		case field[0] == '/':
+			exprs = append(exprs, record+field)
+		default:
+			exprs = append(exprs, record+"/"+field)
+		}
+	}
+	set, err := CompileSet(exprs...)
+	if err != nil {
+		return nil, err
+	}
+	return &Extractor{
+		set:    set,
+		fields: fields,
+		record: make([]string, len(fields)),
+		found:  make([]bool, len(fields)),
+	}, nil
+}
+
+// Fields returns the value paths, e.g. to be used as a header.
+func (e *Extractor) Fields() []string { return e.fields }
+
+// Extract tokenizes r and invokes fn for every record. The record is only
+// valid during the invocation. It stops on the first error other than io.EOF,
+// including the one returned by fn.
+func (e *Extractor) Extract(r io.Reader, fn func(record []string) error, opts ...xmltokenizer.Option) error {
+	e.set.Reset()
+	e.open = 0
+	tok := xmltokenizer.New(r, opts...)
+	for {
+		token, err := tok.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		matched, exit := e.set.Step(&token)
+		if len(matched) == 0 {
+			continue
+		}
+		if exit {
+			if contains(matched, 0) {
+				if e.open--; e.open == 0 {
+					if err := fn(e.record); err != nil {
+						return err
+					}
+				}
+			}
+			continue
+		}
+		if contains(matched, 0) {
+			if e.open == 0 {
+				clear(e.record)
+				clear(e.found)
+			}
+			e.open++
+		}
+		if e.open == 0 {
+			continue // value paths matched outside of a record
+		}
+		for _, i := range matched {
+			if i == 0 || e.found[i-1] {
+				continue
+			}
+			value := token.Data
+			if token.SelfClosing {
+				value = nil
+			}
+			if attr, ok := e.set.Attr(i, &token); ok {
+				value = attr.Value
+			}
+			e.record[i-1], e.found[i-1] = string(value), true
+		}
+		if token.SelfClosing && contains(matched, 0) {
+			if e.open--; e.open == 0 {
+				if err := fn(e.record); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+// WriteCSV writes the records of r to w as CSV, preceded by the value paths as
+// a header if header is true.
+func (e *Extractor) WriteCSV(w io.Writer, r io.Reader, header bool, opts ...xmltokenizer.Option) error {
+	cw := csv.NewWriter(w)
+	if header {
+		if err := cw.Write(e.fields); err != nil {
+			return err
+		}
+	}
+	err := e.Extract(r, cw.Write, opts...)
+	cw.Flush()
+	if err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+// Records extracts the records of r in a new goroutine and sends them on the
+// returned channel, which is closed when r is exhausted, on error or when ctx
+// is done. The error, if any, is then available on the error channel. The
+// Extractor must not be used until the record channel is closed.
+func (e *Extractor) Records(ctx context.Context, r io.Reader, opts ...xmltokenizer.Option) (<-chan []string, <-chan error) {
+	records, errc := make(chan []string), make(chan error, 1)
+	go func() {
+		defer close(errc)
+		defer close(records)
+		err := e.Extract(r, func(record []string) error {
+			select {
+			case records <- append([]string(nil), record...):
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}, opts...)
+		if err != nil {
+			errc <- err
+		}
+	}()
+	return records, errc
+}
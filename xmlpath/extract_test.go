@@ -0,0 +1,137 @@
+package xmlpath_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/muktihari/xmltokenizer/xmlpath"
+)
+
+func TestExtractor(t *testing.T) {
+	tt := []struct {
+		name    string
+		xml     string
+		record  string
+		fields  []string
+		records [][]string
+	}{
+		{
+			name:   "attributes and elements",
+			record: "//trkpt",
+			fields: []string{"@lat", "@lon", "ele"},
+			records: [][]string{
+				{"47.1", "8.5", "100"},
+				{"", "8.6", ""},
+				{"47.3", "8.7", "120"},
+			},
+		},
+		{
+			name:   "descendant and self",
+			record: "/gpx/wpt",
+			fields: []string{"@name", "//ele", "."},
+			records: [][]string{
+				{"", "5", ""},
+			},
+		},
+		{
+			name:   "self-closing elements",
+			xml:    `<r><p><v/>5</p><p x="1"/>tail<p><v>7</v></p></r>`,
+			record: "/r/p",
+			fields: []string{"v", "."},
+			records: [][]string{
+				{"", ""},
+				{"", ""},
+				{"7", ""},
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.xml == "" {
+				tc.xml = gpxDoc
+			}
+			e := mustExtractor(t, tc.record, tc.fields...)
+			var records [][]string
+			err := e.Extract(strings.NewReader(tc.xml), func(record []string) error {
+				records = append(records, append([]string(nil), record...))
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("expected nil, got: %v", err)
+			}
+			if diff := cmp.Diff(records, tc.records); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
+
+func TestExtractorCSV(t *testing.T) {
+	e := mustExtractor(t, "/list/item", "@id", "name", "//note")
+	const doc = `<list>
+	<item id="1"><name>a, b</name><x><note>n1</note><note>n2</note></x></item>
+	<item id="2"><item id="3"><name>nested</name></item></item>
+	<name>outside</name>
+</list>`
+	var buf strings.Builder
+	if err := e.WriteCSV(&buf, strings.NewReader(doc), true); err != nil {
+		t.Fatalf("expected nil, got: %v", err)
+	}
+	expected := "@id,name,//note\n" +
+		"1,\"a, b\",n1\n" +
+		"2,,\n"
+	if diff := cmp.Diff(buf.String(), expected); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestExtractorRecords(t *testing.T) {
+	e := mustExtractor(t, "//trkpt", "@lon")
+	records, errc := e.Records(context.Background(), strings.NewReader(gpxDoc))
+	var lons []string
+	for record := range records {
+		lons = append(lons, record[0])
+	}
+	if err := <-errc; err != nil {
+		t.Fatalf("expected nil, got: %v", err)
+	}
+	if diff := cmp.Diff(lons, []string{"8.5", "8.6", "8.7"}); diff != "" {
+		t.Fatal(diff)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	records, errc = e.Records(ctx, strings.NewReader(gpxDoc))
+	<-records
+	cancel()
+	for range records {
+	}
+	if err := <-errc; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected error: %v, got: %v", context.Canceled, err)
+	}
+}
+
+func TestNewExtractorError(t *testing.T) {
+	for _, tc := range [][]string{
+		{"trkpt", "@lat"},
+		{"//trkpt", "/ele"},
+		{"//trkpt", ""},
+		{"//trkpt", "ele[@x"},
+	} {
+		if _, err := xmlpath.NewExtractor(tc[0], tc[1:]...); err == nil {
+			t.Fatalf("%q: expected error, got nil", tc)
+		}
+	}
+}
+
+func mustExtractor(t *testing.T, record string, fields ...string) *xmlpath.Extractor {
+	t.Helper()
+	e, err := xmlpath.NewExtractor(record, fields...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
@@ -0,0 +1,110 @@
+package xmlpath
+
+import (
+	"strconv"
+
+	"github.com/muktihari/xmltokenizer"
+)
+
+// MatchState is the state of a token relative to the elements matched by a PathMatcher.
+type MatchState int
+
+const (
+	// NoMatch is the state of a token outside any matched element.
+	NoMatch MatchState = iota
+	// Enter is the state of the start element, or self-closing element, matched by the path.
+	Enter
+	// Inside is the state of a token within a matched element, e.g. its descendants.
+	Inside
+	// Exit is the state of the end element of a matched element.
+	Exit
+)
+
+func (s MatchState) String() string {
+	switch s {
+	case NoMatch:
+		return "NoMatch"
+	case Enter:
+		return "Enter"
+	case Inside:
+		return "Inside"
+	case Exit:
+		return "Exit"
+	}
+	return "MatchState(" + strconv.Itoa(int(s)) + ")"
+}
+
+// PathMatcher matches a stream of tokens against a compiled path expression.
+// Tokens must be fed in document order using Step, it keeps track of the
+// open elements itself. A PathMatcher is not safe for concurrent use.
+type PathMatcher struct {
+	a *automaton
+}
+
+// Compile compiles the path expression into a PathMatcher, see the package
+// documentation for the syntax.
+func Compile(expr string) (*PathMatcher, error) {
+	p, err := compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	return &PathMatcher{a: newAutomaton([]*path{p})}, nil
+}
+
+// MustCompile is like Compile but panics if the expression is invalid.
+func MustCompile(expr string) *PathMatcher {
+	m, err := Compile(expr)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// String returns the path expression.
+func (m *PathMatcher) String() string { return m.a.paths[0].expr }
+
+// Reset resets the PathMatcher to match a new document.
+func (m *PathMatcher) Reset() { m.a.reset() }
+
+// Step feeds the next token of the document and returns its MatchState.
+func (m *PathMatcher) Step(token *xmltokenizer.Token) MatchState {
+	matched, exit := m.a.step(token)
+	switch {
+	case len(matched) > 0 && exit:
+		return Exit
+	case len(matched) > 0:
+		return Enter
+	case m.a.inside[0] > 0:
+		return Inside
+	}
+	return NoMatch
+}
+
+// Attr returns the attribute selected by the path, if the path ends with
+// "/@name", of the token for which Step returned Enter.
+func (m *PathMatcher) Attr(token *xmltokenizer.Token) (xmltokenizer.Attr, bool) {
+	return attrOf(m.a.paths[0], token)
+}
+
+// SelectsAttr reports whether the path ends with "/@name".
+func (m *PathMatcher) SelectsAttr() bool { return m.a.paths[0].attr != nil }
+
+// MatchAttr reports whether the attribute named name is selected by the path,
+// e.g. to select every attribute matching "/@*" rather than the first one
+// returned by Attr.
+func (m *PathMatcher) MatchAttr(name xmltokenizer.Name) bool {
+	return m.a.paths[0].matchAttr(name)
+}
+
+// Depth returns the number of open elements.
+func (m *PathMatcher) Depth() int { return m.a.depth() }
+
+func attrOf(p *path, token *xmltokenizer.Token) (xmltokenizer.Attr, bool) {
+	if p.attr == nil {
+		return xmltokenizer.Attr{}, false
+	}
+	if attr := findAttr(token, p.attr); attr != nil {
+		return *attr, true
+	}
+	return xmltokenizer.Attr{}, false
+}
@@ -0,0 +1,116 @@
+package xmlpath_test
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/muktihari/xmltokenizer"
+	"github.com/muktihari/xmltokenizer/xmlpath"
+)
+
+const gpxDoc = `<?xml version="1.0"?>
+<gpx>
+  <trk>
+    <trkseg>
+      <trkpt lat="47.1" lon="8.5"><ele>100</ele></trkpt>
+      <trkpt lon="8.6"/>
+      <trkpt lat="47.3" lon="8.7"><ele>120</ele></trkpt>
+    </trkseg>
+  </trk>
+  <wpt lat="1" lon="2"><ele>5</ele></wpt>
+</gpx>`
+
+// steps returns the non-NoMatch states of every token as "name:state".
+func steps(t *testing.T, m *xmlpath.PathMatcher, xml string) []string {
+	var result []string
+	tok := xmltokenizer.New(strings.NewReader(xml))
+	for {
+		token, err := tok.Token()
+		if err == io.EOF {
+			return result
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		state := m.Step(&token)
+		if state == xmlpath.NoMatch {
+			continue
+		}
+		name := string(token.Name.Full)
+		if token.IsEndElement {
+			name = "/" + name
+		}
+		if attr, ok := m.Attr(&token); ok && state == xmlpath.Enter {
+			name += "@" + string(attr.Value)
+		}
+		result = append(result, fmt.Sprintf("%s:%s", name, state))
+	}
+}
+
+func TestPathMatcher(t *testing.T) {
+	tt := []struct {
+		expr     string
+		expected []string
+	}{
+		{
+			expr:     "/gpx/trk//trkpt[@lat]",
+			expected: []string{"trkpt:Enter", "ele:Inside", "/ele:Inside", "/trkpt:Exit", "trkpt:Enter", "ele:Inside", "/ele:Inside", "/trkpt:Exit"},
+		},
+		{
+			expr:     "//trkpt[@lat='47.3']/ele",
+			expected: []string{"ele:Enter", "/ele:Exit"},
+		},
+		{
+			expr:     "//*[@lon=\"8.6\"]",
+			expected: []string{"trkpt:Enter"},
+		},
+		{
+			expr:     "//ele",
+			expected: []string{"ele:Enter", "/ele:Exit", "ele:Enter", "/ele:Exit", "ele:Enter", "/ele:Exit"},
+		},
+		{
+			expr:     "/gpx/wpt/@lat",
+			expected: []string{"wpt@1:Enter", "ele:Inside", "/ele:Inside", "/wpt:Exit"},
+		},
+		{
+			expr:     "/trk",
+			expected: nil,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.expr, func(t *testing.T) {
+			m := xmlpath.MustCompile(tc.expr)
+			if diff := cmp.Diff(steps(t, m, gpxDoc), tc.expected); diff != "" {
+				t.Fatal(diff)
+			}
+			m.Reset()
+			if diff := cmp.Diff(steps(t, m, gpxDoc), tc.expected); diff != "" {
+				t.Fatalf("after reset: %s", diff)
+			}
+		})
+	}
+}
+
+func TestNestedMatches(t *testing.T) {
+	m := xmlpath.MustCompile("//a")
+	got := steps(t, m, `<a><a/><a><b/></a></a>`)
+	expected := []string{"a:Enter", "a:Enter", "a:Enter", "b:Inside", "/a:Exit", "/a:Exit"}
+	if diff := cmp.Diff(got, expected); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestCompileError(t *testing.T) {
+	exprs := []string{"gpx", "/", "/gpx//", "/gpx/@", "//@lat", "/a[lat]", "/a[@lat=1]", "/a[@lat='1'", "/a]"}
+	for _, expr := range exprs {
+		t.Run(expr, func(t *testing.T) {
+			if _, err := xmlpath.Compile(expr); err == nil {
+				t.Fatalf("expected error, got nil")
+			}
+		})
+	}
+}
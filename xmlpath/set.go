@@ -0,0 +1,62 @@
+package xmlpath
+
+import "github.com/muktihari/xmltokenizer"
+
+// Set matches a stream of tokens against many path expressions at once, which
+// is faster than using a PathMatcher for each since all paths are compiled
+// into one automaton evaluated once per token. A Set is not safe for
+// concurrent use.
+type Set struct {
+	a *automaton
+}
+
+// CompileSet compiles the path expressions into a Set, see the package
+// documentation for the syntax. The paths are identified by their index.
+func CompileSet(exprs ...string) (*Set, error) {
+	paths := make([]*path, len(exprs))
+	for i, expr := range exprs {
+		p, err := compile(expr)
+		if err != nil {
+			return nil, err
+		}
+		paths[i] = p
+	}
+	return &Set{a: newAutomaton(paths)}, nil
+}
+
+// Len returns the number of paths.
+func (s *Set) Len() int { return len(s.a.paths) }
+
+// String returns the expression of the path at index i.
+func (s *Set) String(i int) string { return s.a.paths[i].expr }
+
+// Reset resets the Set to match a new document.
+func (s *Set) Reset() { s.a.reset() }
+
+// Step feeds the next token of the document. It returns the indexes of the
+// paths for which the token's MatchState is Enter, or Exit when exit is true.
+// The returned slice is only valid before the next Step invocation.
+func (s *Set) Step(token *xmltokenizer.Token) (matched []int, exit bool) {
+	return s.a.step(token)
+}
+
+// Inside reports whether the last token fed is within an element matched by
+// the path at index i. The start element of the match is within it, its end
+// element is not.
+func (s *Set) Inside(i int) bool { return s.a.inside[i] > 0 }
+
+// Attr returns the attribute selected by the path at index i, if the
+// path ends with "/@name", of the token entering a match of that path.
+func (s *Set) Attr(i int, token *xmltokenizer.Token) (xmltokenizer.Attr, bool) {
+	return attrOf(s.a.paths[i], token)
+}
+
+// SelectsAttr reports whether the path at index i ends with "/@name".
+func (s *Set) SelectsAttr(i int) bool { return s.a.paths[i].attr != nil }
+
+// MatchAttr reports whether the attribute named name is selected by the path
+// at index i, e.g. to select every attribute matching "/@*" rather than the
+// first one returned by Attr.
+func (s *Set) MatchAttr(i int, name xmltokenizer.Name) bool {
+	return s.a.paths[i].matchAttr(name)
+}
@@ -0,0 +1,128 @@
+package xmlpath_test
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/muktihari/xmltokenizer"
+	"github.com/muktihari/xmltokenizer/xmlpath"
+)
+
+func TestSet(t *testing.T) {
+	s, err := xmlpath.CompileSet("//trkpt[@lat]", "//ele", "/gpx/wpt/@lon", "//trkpt/ele")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	tok := xmltokenizer.New(strings.NewReader(gpxDoc))
+	for {
+		token, err := tok.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		matched, exit := s.Step(&token)
+		for _, i := range matched {
+			event := "enter"
+			if exit {
+				event = "exit"
+			} else if attr, ok := s.Attr(i, &token); ok {
+				event += "@" + string(attr.Value)
+			}
+			got = append(got, fmt.Sprintf("%s %s %t", s.String(i), event, s.Inside(0)))
+		}
+	}
+
+	expected := []string{
+		"//trkpt[@lat] enter true",
+		"//ele enter true",
+		"//trkpt/ele enter true",
+		"//ele exit true",
+		"//trkpt/ele exit true",
+		"//trkpt[@lat] exit false",
+		"//trkpt[@lat] enter true",
+		"//ele enter true",
+		"//trkpt/ele enter true",
+		"//ele exit true",
+		"//trkpt/ele exit true",
+		"//trkpt[@lat] exit false",
+		"/gpx/wpt/@lon enter@2 false",
+		"//ele enter false",
+		"//ele exit false",
+		"/gpx/wpt/@lon exit false",
+	}
+	if diff := cmp.Diff(got, expected); diff != "" {
+		t.Fatal(diff)
+	}
+	if s.Len() != 4 {
+		t.Fatalf("expected len: 4, got: %d", s.Len())
+	}
+
+	if _, err := xmlpath.CompileSet("//a", "b"); err == nil {
+		t.Fatalf("expected error, got nil")
+	}
+}
+
+func TestSetMatchAttr(t *testing.T) {
+	s, err := xmlpath.CompileSet("//trkpt", "//trkpt/@*", "//trkpt/@gpx:lat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lat := xmltokenizer.Name{Prefix: []byte("gpx"), Local: []byte("lat"), Full: []byte("gpx:lat")}
+	lon := xmltokenizer.Name{Local: []byte("lon"), Full: []byte("lon")}
+
+	tt := []struct {
+		i           int
+		selectsAttr bool
+		lat, lon    bool
+	}{
+		{i: 0},
+		{i: 1, selectsAttr: true, lat: true, lon: true},
+		{i: 2, selectsAttr: true, lat: true},
+	}
+	for _, tc := range tt {
+		if s.SelectsAttr(tc.i) != tc.selectsAttr || s.MatchAttr(tc.i, lat) != tc.lat || s.MatchAttr(tc.i, lon) != tc.lon {
+			t.Fatalf("[%d] expected: %t %t %t, got: %t %t %t", tc.i, tc.selectsAttr, tc.lat, tc.lon,
+				s.SelectsAttr(tc.i), s.MatchAttr(tc.i, lat), s.MatchAttr(tc.i, lon))
+		}
+	}
+
+	m := xmlpath.MustCompile("//trkpt/@lon")
+	if !m.SelectsAttr() || !m.MatchAttr(lon) || m.MatchAttr(lat) {
+		t.Fatalf("expected //trkpt/@lon to select lon only")
+	}
+}
+
+func BenchmarkSet(b *testing.B) {
+	exprs := make([]string, 32)
+	for i := range exprs {
+		exprs[i] = fmt.Sprintf("//trk/trkseg/trkpt[@lat='%d']/ele", i)
+	}
+	s, err := xmlpath.CompileSet(exprs...)
+	if err != nil {
+		b.Fatal(err)
+	}
+	tok := xmltokenizer.New(strings.NewReader(gpxDoc), xmltokenizer.WithPersistentTokens())
+	var tokens []xmltokenizer.Token
+	for {
+		token, err := tok.Token()
+		if err != nil {
+			break
+		}
+		tokens = append(tokens, token)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.Reset()
+		for j := range tokens {
+			s.Step(&tokens[j])
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package xmlpath
+
+import (
+	"bytes"
+
+	"github.com/muktihari/xmltokenizer"
+)
+
+// NormalizeSpace appends b to dst with its leading and trailing whitespace
+// stripped and every other run of whitespace replaced by a single space, as
+// the XPath normalize-space function does, and returns the extended buffer.
+func NormalizeSpace(dst, b []byte) []byte {
+	w := normalizer{dst: dst, start: len(dst)}
+	w.write(b)
+	return w.dst
+}
+
+// StringValue reads the tokens of the element started by start from tok, up to
+// and including its end element, and appends the normalized string-value of
+// the element to dst: the text of the element and its descendants, with the
+// entities decoded and the whitespace normalized as by NormalizeSpace, e.g.
+// "Total: 12 EUR" for
+//
+//	<total>
+//	  Total: <amount>12</amount> <currency>EUR</currency>
+//	</total>
+//
+// The start element must be the last token returned by tok. Since the
+// whitespace between elements is not part of any token, tok must be created
+// with xmltokenizer.WithSpace, otherwise words separated only by elements
+// and whitespace are joined.
+func StringValue(dst []byte, tok *xmltokenizer.Tokenizer, start *xmltokenizer.Token) ([]byte, error) {
+	return stringValue(dst, tok, start, nil)
+}
+
+// StringValue is like the package-level StringValue, feeding the consumed
+// tokens to the PathMatcher so it remains in sync. Typically start is the
+// token for which Step returned Enter.
+func (m *PathMatcher) StringValue(dst []byte, tok *xmltokenizer.Tokenizer, start *xmltokenizer.Token) ([]byte, error) {
+	return stringValue(dst, tok, start, func(token *xmltokenizer.Token) { m.Step(token) })
+}
+
+func stringValue(dst []byte, tok *xmltokenizer.Tokenizer, start *xmltokenizer.Token, step func(*xmltokenizer.Token)) ([]byte, error) {
+	w := normalizer{dst: dst, start: len(dst)}
+	// The CharData following a self-closing element belongs to its parent.
+	if start.IsEndElement || start.SelfClosing || len(start.Name.Full) == 0 {
+		return w.dst, nil
+	}
+	w.writeCharData(charDataOf(tok.Raw()))
+	for depth := 1; depth > 0; {
+		token, err := tok.Token()
+		if err != nil {
+			return w.dst, err
+		}
+		if step != nil {
+			step(&token)
+		}
+		w.writeText(tok.Space())
+		switch {
+		case token.Continued:
+			w.writeText(token.Data)
+		case len(token.Name.Full) > 0:
+			if token.IsEndElement {
+				depth--
+			} else if !token.SelfClosing {
+				depth++
+			}
+			if depth > 0 {
+				w.writeCharData(charDataOf(tok.Raw()))
+			}
+		case bytes.HasPrefix(token.Data, []byte("<![CDATA[")):
+			w.writeCharData(token.Data)
+		}
+	}
+	return w.dst, nil
+}
+
+// charDataOf returns the raw CharData or CDATA following the tag of a raw start element.
+func charDataOf(raw []byte) []byte {
+	var quote byte
+	for i, c := range raw {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '>':
+			return raw[i+1:]
+		}
+	}
+	return nil
+}
+
+// normalizer appends normalized text to dst, see NormalizeSpace.
+type normalizer struct {
+	dst   []byte
+	start int  // len(dst) before any text is appended
+	space bool // whitespace is pending
+	buf   []byte
+}
+
+// writeCharData writes raw CharData which may contain CDATA sections.
+func (w *normalizer) writeCharData(b []byte) {
+	const prefix, suffix = "<![CDATA[", "]]>"
+	for len(b) > 0 {
+		i := bytes.Index(b, []byte(prefix))
+		if i == -1 {
+			w.writeText(b)
+			return
+		}
+		w.writeText(b[:i])
+		b = b[i+len(prefix):]
+		end := bytes.Index(b, []byte(suffix))
+		if end == -1 {
+			w.write(b)
+			return
+		}
+		w.write(b[:end])
+		b = b[end+len(suffix):]
+	}
+}
+
+// writeText writes raw CharData with its entities decoded.
+func (w *normalizer) writeText(b []byte) {
+	if bytes.IndexByte(b, '&') == -1 {
+		w.write(b)
+		return
+	}
+	w.buf = xmltokenizer.Attr{Value: b}.ValueUnescaped(w.buf[:0])
+	w.write(w.buf)
+}
+
+func (w *normalizer) write(b []byte) {
+	for _, c := range b {
+		if c == ' ' || c == '\t' || c == '\r' || c == '\n' {
+			w.space = true
+			continue
+		}
+		if w.space && len(w.dst) > w.start {
+			w.dst = append(w.dst, ' ')
+		}
+		w.space = false
+		w.dst = append(w.dst, c)
+	}
+}
@@ -0,0 +1,118 @@
+package xmlpath_test
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/muktihari/xmltokenizer"
+	"github.com/muktihari/xmltokenizer/xmlpath"
+)
+
+func TestNormalizeSpace(t *testing.T) {
+	tt := []struct {
+		in, expected string
+	}{
+		{in: "", expected: ""},
+		{in: " \t\r\n ", expected: ""},
+		{in: "a", expected: "a"},
+		{in: "  a \n\t b  c ", expected: "a b c"},
+	}
+	for _, tc := range tt {
+		if out := xmlpath.NormalizeSpace([]byte("x:"), []byte(tc.in)); string(out) != "x:"+tc.expected {
+			t.Fatalf("%q: expected: %q, got: %q", tc.in, "x:"+tc.expected, out)
+		}
+	}
+}
+
+func TestStringValue(t *testing.T) {
+	const doc = `<report>
+	<total>
+	  Total: <amount>12</amount> <currency>EUR</currency>
+	</total>
+	<note id="1">
+	  a&amp;b<br/> c<!-- skipped --><![CDATA[ <d> ]]><i>e<![CDATA[f]]></i>g&#x21;
+	</note>
+	<empty/>
+	<x a=">">y</x>
+</report>`
+
+	m := xmlpath.MustCompile("/report/*")
+	tok := xmltokenizer.New(strings.NewReader(doc), xmltokenizer.WithSpace())
+	var values []string
+	var buf []byte
+	for {
+		token, err := tok.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if m.Step(&token) != xmlpath.Enter {
+			continue
+		}
+		if buf, err = m.StringValue(buf[:0], tok, &token); err != nil {
+			t.Fatal(err)
+		}
+		values = append(values, string(buf))
+	}
+
+	expected := []string{"Total: 12 EUR", "a&b c <d> efg!", "", "y"}
+	if strings.Join(values, "|") != strings.Join(expected, "|") {
+		t.Fatalf("expected: %q, got: %q", expected, values)
+	}
+	if m.Depth() != 0 {
+		t.Fatalf("expected depth: 0, got: %d", m.Depth())
+	}
+}
+
+func TestStringValueSelfClosing(t *testing.T) {
+	tt := []struct {
+		xml      string
+		expected []string
+	}{
+		{xml: `<r><a/>tail</r>`, expected: []string{""}},
+		{xml: `<r><a x="1"/> tail <b/></r>`, expected: []string{"", ""}},
+		{xml: `<r><a x="/>"/>tail<b>c</b></r>`, expected: []string{"", "c"}},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.xml, func(t *testing.T) {
+			m := xmlpath.MustCompile("/r/*")
+			tok := xmltokenizer.New(strings.NewReader(tc.xml), xmltokenizer.WithSpace())
+			var values []string
+			for {
+				token, err := tok.Token()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				if m.Step(&token) != xmlpath.Enter {
+					continue
+				}
+				value, err := m.StringValue(nil, tok, &token)
+				if err != nil {
+					t.Fatal(err)
+				}
+				values = append(values, string(value))
+			}
+			if strings.Join(values, "|") != strings.Join(tc.expected, "|") {
+				t.Fatalf("expected: %q, got: %q", tc.expected, values)
+			}
+		})
+	}
+}
+
+func TestStringValueError(t *testing.T) {
+	tok := xmltokenizer.New(strings.NewReader(`<a>b<c>d`), xmltokenizer.WithSpace())
+	token, err := tok.Token()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = xmlpath.StringValue(nil, tok, &token); err == nil {
+		t.Fatalf("expected error, got nil")
+	}
+}
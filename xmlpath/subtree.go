@@ -0,0 +1,128 @@
+package xmlpath
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/muktihari/xmltokenizer"
+)
+
+const (
+	// ErrIDNotFound is returned by SubtreeByID when no element has the ID.
+	ErrIDNotFound = errorString("id not found")
+	// ErrDuplicateID is returned by SubtreeByID when more than one element
+	// has the ID.
+	ErrDuplicateID = errorString("duplicate id")
+)
+
+// Subtrees tokenizes r and invokes fn with the byte-exact form of every
+// element matched by the path expression, see Tokenizer.Subtree, e.g. to
+// verify the signatures of SOAP or SAML fragments over their exact bytes.
+// The elements nested within a matched element are not matched. The bytes
+// are only valid during the fn invocation. It stops on the first error other
+// than io.EOF, including the one returned by fn.
+func Subtrees(r io.Reader, expr string, fn func(raw []byte) error, opts ...xmltokenizer.Option) error {
+	m, err := Compile(expr)
+	if err != nil {
+		return err
+	}
+	tok := xmltokenizer.New(r, opts...)
+	for {
+		token, err := tok.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if m.Step(&token) != Enter {
+			continue
+		}
+		raw, err := tok.Subtree(&token)
+		if err != nil {
+			return err
+		}
+		if !token.SelfClosing {
+			// Its end element has been consumed by Subtree, the matcher only
+			// needs to pop the element.
+			m.Step(&xmltokenizer.Token{Name: token.Name, IsEndElement: true})
+		}
+		if err := fn(raw); err != nil {
+			return err
+		}
+	}
+}
+
+// SubtreeByID returns a copy of the byte-exact form of the element having the
+// given ID, see Tokenizer.Subtree, as referenced by the URI "#id" of an XML
+// Signature. The ID is the value of an attribute whose local name is "Id",
+// "ID" or "id", such as the wsu:Id of WS-Security, the ID of SAML and xml:id.
+// Since elements sharing an ID are the basis of signature wrapping attacks,
+// the whole document is read to return ErrDuplicateID in that case.
+func SubtreeByID(r io.Reader, id string, opts ...xmltokenizer.Option) ([]byte, error) {
+	var found []byte
+	tok := xmltokenizer.New(r, opts...)
+	for {
+		token, err := tok.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !hasID(&token, id) {
+			continue
+		}
+		if found != nil {
+			return nil, fmt.Errorf("%q: %w", id, ErrDuplicateID)
+		}
+		raw, err := tok.Subtree(&token)
+		if err != nil {
+			return nil, err
+		}
+		found = bytes.Clone(raw)
+		if n, err := countID(found, id); err != nil {
+			return nil, err
+		} else if n > 1 {
+			return nil, fmt.Errorf("%q: %w", id, ErrDuplicateID)
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("%q: %w", id, ErrIDNotFound)
+	}
+	return found, nil
+}
+
+// countID returns the number of elements of the subtree having the ID.
+func countID(subtree []byte, id string) (n int, err error) {
+	tok := xmltokenizer.New(bytes.NewReader(subtree))
+	for {
+		token, err := tok.Token()
+		if err == io.EOF {
+			return n, nil
+		}
+		if err != nil {
+			return n, err
+		}
+		if hasID(&token, id) {
+			n++
+		}
+	}
+}
+
+// hasID reports whether the start element has an ID attribute of the given value.
+func hasID(token *xmltokenizer.Token, id string) bool {
+	if token.IsEndElement {
+		return false
+	}
+	for i := range token.Attrs {
+		switch string(token.Attrs[i].Name.Local) {
+		case "Id", "ID", "id":
+			if string(token.Attrs[i].Value) == id {
+				return true
+			}
+		}
+	}
+	return false
+}
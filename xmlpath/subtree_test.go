@@ -0,0 +1,126 @@
+package xmlpath_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/muktihari/xmltokenizer/xmlpath"
+)
+
+const soapDoc = `<soap:Envelope xmlns:soap="http://www.w3.org/2003/05/soap-envelope" xmlns:wsu="urn:wsu">
+  <soap:Header>
+    <wsse:Security><ds:Signature><ds:Reference URI="#body"/></ds:Signature></wsse:Security>
+  </soap:Header>
+  <soap:Body wsu:Id="body">
+    <m:Order xmlns:m="urn:m"  id = 'o1' ><m:Item>A &amp; B</m:Item><!-- c --></m:Order>
+  </soap:Body>
+</soap:Envelope>`
+
+func TestSubtrees(t *testing.T) {
+	var result []string
+	err := xmlpath.Subtrees(strings.NewReader(soapDoc), "//*[@id]", func(raw []byte) error {
+		result = append(result, string(raw))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("expected nil, got: %v", err)
+	}
+	expected := []string{
+		`<m:Order xmlns:m="urn:m"  id = 'o1' ><m:Item>A &amp; B</m:Item><!-- c --></m:Order>`,
+	}
+	if diff := cmp.Diff(result, expected); diff != "" {
+		t.Fatal(diff)
+	}
+
+	// The matcher stays in sync after consuming a subtree.
+	result = nil
+	err = xmlpath.Subtrees(strings.NewReader(`<a><b><b/></b><c/><b>x</b></a>`), "/a/b", func(raw []byte) error {
+		result = append(result, string(raw))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("expected nil, got: %v", err)
+	}
+	if diff := cmp.Diff(result, []string{`<b><b/></b>`, `<b>x</b>`}); diff != "" {
+		t.Fatal(diff)
+	}
+
+	// The text following a subtree belongs to its parent.
+	result = nil
+	err = xmlpath.Subtrees(strings.NewReader(`<a><b>1</b>tail<b/> tail <c/></a>`), "/a/b", func(raw []byte) error {
+		result = append(result, string(raw))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("expected nil, got: %v", err)
+	}
+	if diff := cmp.Diff(result, []string{`<b>1</b>`, `<b/>`}); diff != "" {
+		t.Fatal(diff)
+	}
+
+	errStop := errors.New("stop")
+	err = xmlpath.Subtrees(strings.NewReader(soapDoc), "//soap:Body", func([]byte) error { return errStop })
+	if !errors.Is(err, errStop) {
+		t.Fatalf("expected error: %v, got: %v", errStop, err)
+	}
+}
+
+func TestSubtreeByID(t *testing.T) {
+	tt := []struct {
+		name     string
+		xml      string
+		id       string
+		expected string
+		err      error
+	}{
+		{
+			name: "wsu:Id",
+			xml:  soapDoc,
+			id:   "body",
+			expected: `<soap:Body wsu:Id="body">
+    <m:Order xmlns:m="urn:m"  id = 'o1' ><m:Item>A &amp; B</m:Item><!-- c --></m:Order>
+  </soap:Body>`,
+		},
+		{
+			name:     "id",
+			xml:      soapDoc,
+			id:       "o1",
+			expected: `<m:Order xmlns:m="urn:m"  id = 'o1' ><m:Item>A &amp; B</m:Item><!-- c --></m:Order>`,
+		},
+		{
+			name:     "self-closing",
+			xml:      `<Response><Assertion ID="_1"/></Response>`,
+			id:       "_1",
+			expected: `<Assertion ID="_1"/>`,
+		},
+		{
+			name:     "text following the element",
+			xml:      `<r><a ID="x">1</a>tail<b/></r>`,
+			id:       "x",
+			expected: `<a ID="x">1</a>`,
+		},
+		{
+			name:     "text following the self-closing element",
+			xml:      `<r><a ID="x"/>tail</r>`,
+			id:       "x",
+			expected: `<a ID="x"/>`,
+		},
+		{name: "not found", xml: soapDoc, id: "nope", err: xmlpath.ErrIDNotFound},
+		{name: "duplicate", xml: `<r><a ID="x"/><b ID="x"/></r>`, id: "x", err: xmlpath.ErrDuplicateID},
+		{name: "nested duplicate", xml: `<r><a ID="x"><b ID="x"/></a></r>`, id: "x", err: xmlpath.ErrDuplicateID},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			raw, err := xmlpath.SubtreeByID(strings.NewReader(tc.xml), tc.id)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expected error: %v, got: %v", tc.err, err)
+			}
+			if diff := cmp.Diff(string(raw), tc.expected); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+// Package xmlpath matches tokens of the xmltokenizer against path expressions
+// such as "/gpx/trk//trkpt[@lat]" while streaming, without building a tree.
+//
+// A path is a sequence of steps separated by "/", "//" matches any number of
+// elements in between. A step is an element name or "*" for any, followed by
+// any number of predicates: "[@name]" requires the attribute and
+// "[@name='value']" requires its value. A trailing "/@name" selects an
+// attribute of the matched element. A name with a prefix is compared against
+// the Full name, otherwise against the Local name.
+package xmlpath
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/muktihari/xmltokenizer"
+)
+
+type errorString string
+
+func (e errorString) Error() string { return string(e) }
+
+const errInvalidPath = errorString("invalid path")
+
+type step struct {
+	name       []byte // "*" matches any name.
+	descendant bool   // True when the step is preceded by "//".
+	preds      []predicate
+}
+
+type predicate struct {
+	attr     []byte
+	value    []byte
+	hasValue bool
+}
+
+// path is a compiled path expression.
+type path struct {
+	expr  string
+	steps []step
+	attr  []byte // Non-nil when the path selects an attribute.
+}
+
+// compile compiles the path expression s.
+func compile(s string) (*path, error) {
+	if !strings.HasPrefix(s, "/") {
+		return nil, fmt.Errorf("%q: must start with '/': %w", s, errInvalidPath)
+	}
+	p := &path{expr: s}
+	rest := s
+	for len(rest) > 0 {
+		var st step
+		if strings.HasPrefix(rest, "//") {
+			st.descendant, rest = true, rest[2:]
+		} else {
+			rest = rest[1:]
+		}
+		if strings.HasPrefix(rest, "@") {
+			name := rest[1:]
+			if name == "" || strings.ContainsAny(name, "/[]") || st.descendant || len(p.steps) == 0 {
+				return nil, fmt.Errorf("%q: attribute must be the last step of an element: %w", s, errInvalidPath)
+			}
+			p.attr = []byte(name)
+			break
+		}
+		end := strings.IndexAny(rest, "[/")
+		if end == -1 {
+			end = len(rest)
+		}
+		if end == 0 || strings.ContainsAny(rest[:end], "]@='\"") {
+			return nil, fmt.Errorf("%q: invalid name: %w", s, errInvalidPath)
+		}
+		st.name, rest = []byte(rest[:end]), rest[end:]
+		for strings.HasPrefix(rest, "[") {
+			pred, n, err := parsePredicate(rest)
+			if err != nil {
+				return nil, fmt.Errorf("%q: %v: %w", s, err, errInvalidPath)
+			}
+			st.preds, rest = append(st.preds, pred), rest[n:]
+		}
+		if rest != "" && rest[0] != '/' {
+			return nil, fmt.Errorf("%q: unexpected %q: %w", s, rest[0], errInvalidPath)
+		}
+		p.steps = append(p.steps, st)
+	}
+	if len(p.steps) == 0 {
+		return nil, fmt.Errorf("%q: no element: %w", s, errInvalidPath)
+	}
+	return p, nil
+}
+
+// parsePredicate parses "[@name]" or "[@name='value']" at the start of s,
+// returning the number of bytes consumed.
+func parsePredicate(s string) (predicate, int, error) {
+	var pred predicate
+	if !strings.HasPrefix(s, "[@") {
+		return pred, 0, fmt.Errorf("predicate must start with \"[@\"")
+	}
+	i := 2
+	end := strings.IndexAny(s[i:], "=]")
+	if end <= 0 {
+		return pred, 0, fmt.Errorf("invalid predicate attribute")
+	}
+	pred.attr, i = []byte(s[i:i+end]), i+end
+	if s[i] == ']' {
+		return pred, i + 1, nil
+	}
+	i++ // skip '='
+	if i >= len(s) || (s[i] != '\'' && s[i] != '"') {
+		return pred, 0, fmt.Errorf("predicate value must be quoted")
+	}
+	q := strings.IndexByte(s[i+1:], s[i])
+	if q == -1 || i+q+2 >= len(s) || s[i+q+2] != ']' {
+		return pred, 0, fmt.Errorf("unterminated predicate")
+	}
+	pred.value, pred.hasValue = []byte(s[i+1:i+1+q]), true
+	return pred, i + q + 3, nil
+}
+
+// match reports whether the step matches the element token.
+func (st *step) match(token *xmltokenizer.Token) bool {
+	if !matchName(st.name, token.Name) {
+		return false
+	}
+	for i := range st.preds {
+		attr := findAttr(token, st.preds[i].attr)
+		if attr == nil || (st.preds[i].hasValue && !bytes.Equal(attr.Value, st.preds[i].value)) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchName(pattern []byte, name xmltokenizer.Name) bool {
+	if len(pattern) == 1 && pattern[0] == '*' {
+		return true
+	}
+	if bytes.IndexByte(pattern, ':') != -1 {
+		return bytes.Equal(pattern, name.Full)
+	}
+	return bytes.Equal(pattern, name.Local)
+}
+
+// matchAttr reports whether the attribute named name is selected by p.
+func (p *path) matchAttr(name xmltokenizer.Name) bool {
+	return p.attr != nil && matchName(p.attr, name)
+}
+
+// findAttr returns the first attribute of token whose name matches pattern.
+func findAttr(token *xmltokenizer.Token, pattern []byte) *xmltokenizer.Attr {
+	for i := range token.Attrs {
+		if matchName(pattern, token.Attrs[i].Name) {
+			return &token.Attrs[i]
+		}
+	}
+	return nil
+}
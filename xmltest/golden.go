@@ -0,0 +1,101 @@
+package xmltest
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/muktihari/xmltokenizer"
+)
+
+// UpdateEnv is the environment variable which, when not empty, directs Golden
+// to update the golden files, e.g. XMLTEST_UPDATE=1 go test ./...
+const UpdateEnv = "XMLTEST_UPDATE"
+
+// Golden tokenizes r and compares the tokens, formatted by FormatTokens,
+// against the content of the golden file, failing the test if they differ.
+// An error other than io.EOF is recorded as the last line. When UpdateEnv is
+// set, the golden file is written instead.
+func Golden(tb testing.TB, golden string, r io.Reader, opts ...xmltokenizer.Option) {
+	tb.Helper()
+
+	var tokens []xmltokenizer.Token
+	tok := xmltokenizer.New(r, opts...)
+	var err error
+	for {
+		var token xmltokenizer.Token
+		token, err = tok.Token()
+		if err != nil {
+			break
+		}
+		tokens = append(tokens, token.DeepCopy())
+	}
+	got := FormatTokens(tokens)
+	if err != io.EOF {
+		got += fmt.Sprintf("error: %v\n", err)
+	}
+
+	if os.Getenv(UpdateEnv) != "" {
+		if err := os.MkdirAll(filepath.Dir(golden), 0o755); err != nil {
+			tb.Fatalf("update golden: %v", err)
+		}
+		if err := os.WriteFile(golden, []byte(got), 0o644); err != nil {
+			tb.Fatalf("update golden: %v", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		tb.Fatalf("read golden: %v (set %s=1 to create it)", err, UpdateEnv)
+	}
+	if string(want) == got {
+		return
+	}
+	wantLines := strings.Split(string(want), "\n")
+	gotLines := strings.Split(got, "\n")
+	for i := 0; i < max(len(wantLines), len(gotLines)); i++ {
+		var w, g string
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if w != g {
+			tb.Fatalf("%s:%d differs (set %s=1 to accept):\n\twant: %s\n\tgot:  %s", golden, i+1, UpdateEnv, w, g)
+			return
+		}
+	}
+	tb.Fatalf("%s differs in its number of lines (set %s=1 to accept): want: %d, got: %d",
+		golden, UpdateEnv, len(wantLines), len(gotLines))
+}
+
+// FormatTokens formats tokens in a stable textual form, one line per token:
+//
+//	begin-end	kind	name	attrs	data
+//
+// where begin and end are the token's positions in "line:column" form,
+// attrs are space-separated name="value" and data is the quoted token's Data.
+func FormatTokens(tokens []xmltokenizer.Token) string {
+	var buf bytes.Buffer
+	for i := range tokens {
+		token := &tokens[i]
+		fmt.Fprintf(&buf, "%d:%d-%d:%d\t%s\t%s\t",
+			token.Begin.Line, token.Begin.Column,
+			token.End.Line, token.End.Column,
+			token.Kind(), token.Name.Full)
+		for j := range token.Attrs {
+			if j > 0 {
+				buf.WriteByte(' ')
+			}
+			fmt.Fprintf(&buf, "%s=%q", token.Attrs[j].Name.Full, token.Attrs[j].Value)
+		}
+		fmt.Fprintf(&buf, "\t%q\n", token.Data)
+	}
+	return buf.String()
+}
@@ -0,0 +1,71 @@
+package xmltest_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/muktihari/xmltokenizer/xmltest"
+)
+
+func TestGolden(t *testing.T) {
+	f, err := os.Open(filepath.Join("..", "testdata", "dtd.xml"))
+	if err != nil {
+		panic(err)
+	}
+	defer f.Close()
+
+	xmltest.Golden(t, filepath.Join("testdata", "dtd.golden"), f)
+}
+
+type fatalRecorder struct {
+	testing.TB
+	msg string
+}
+
+func (r *fatalRecorder) Helper() {}
+
+func (r *fatalRecorder) Fatalf(format string, args ...any) { r.msg = format }
+
+func TestGoldenMismatch(t *testing.T) {
+	t.Setenv(xmltest.UpdateEnv, "")
+
+	tt := []struct {
+		name   string
+		golden string
+	}{
+		{name: "line", golden: "1:1-1:4\tStartElement\tb\t\t\"\"\n"},
+		{name: "trailing empty lines", golden: "1:1-1:4\tStartElement\ta\t\t\"\"\n\n"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			golden := filepath.Join(t.TempDir(), "a.golden")
+			if err := os.WriteFile(golden, []byte(tc.golden), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			r := &fatalRecorder{TB: t}
+			xmltest.Golden(r, golden, strings.NewReader("<a>"))
+			if !strings.Contains(r.msg, "differs") {
+				t.Fatalf("expected a mismatch, got: %q", r.msg)
+			}
+		})
+	}
+}
+
+func TestGoldenUpdate(t *testing.T) {
+	t.Setenv(xmltest.UpdateEnv, "1")
+
+	golden := filepath.Join(t.TempDir(), "sub", "a.golden")
+	xmltest.Golden(t, golden, strings.NewReader("<a>"))
+
+	b, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("expected nil, got: %v", err)
+	}
+	if expected := "1:1-1:4\tStartElement\ta\t\t\"\"\n"; string(b) != expected {
+		t.Fatalf("expected: %q, got: %q", expected, b)
+	}
+}
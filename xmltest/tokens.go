@@ -0,0 +1,37 @@
+package xmltest
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/muktihari/xmltokenizer"
+)
+
+// TokenizeString returns all tokens of s until io.EOF. Unlike the tokens
+// returned by the Tokenizer, the returned tokens own their memory.
+func TokenizeString(s string, opts ...xmltokenizer.Option) ([]xmltokenizer.Token, error) {
+	tok := xmltokenizer.New(strings.NewReader(s), opts...)
+	var tokens []xmltokenizer.Token
+	for {
+		token, err := tok.Token()
+		if err == io.EOF {
+			return tokens, nil
+		}
+		if err != nil {
+			return tokens, err
+		}
+		tokens = append(tokens, token.DeepCopy())
+	}
+}
+
+// MustTokens is like TokenizeString but fails the test on error.
+func MustTokens(tb testing.TB, xml string, opts ...xmltokenizer.Option) []xmltokenizer.Token {
+	tb.Helper()
+
+	tokens, err := TokenizeString(xml, opts...)
+	if err != nil {
+		tb.Fatalf("tokenize: %v", err)
+	}
+	return tokens
+}
@@ -0,0 +1,45 @@
+package xmltest_test
+
+import (
+	"testing"
+
+	"github.com/muktihari/xmltokenizer"
+	"github.com/muktihari/xmltokenizer/xmltest"
+)
+
+func TestMustTokens(t *testing.T) {
+	// A small buffer makes the tokenizer reuse its memory between tokens.
+	tokens := xmltest.MustTokens(t, `<a x="1"><b y="2">text</b></a>`, xmltokenizer.WithReadBufferSize(1))
+
+	expected := []xmltokenizer.Token{
+		{
+			Name:  xmltokenizer.Name{Local: []byte("a"), Full: []byte("a")},
+			Attrs: []xmltokenizer.Attr{{Name: xmltokenizer.Name{Local: []byte("x"), Full: []byte("x")}, Value: []byte("1")}},
+		},
+		{
+			Name:  xmltokenizer.Name{Local: []byte("b"), Full: []byte("b")},
+			Attrs: []xmltokenizer.Attr{{Name: xmltokenizer.Name{Local: []byte("y"), Full: []byte("y")}, Value: []byte("2")}},
+			Data:  []byte("text"),
+		},
+		{Name: xmltokenizer.Name{Local: []byte("b"), Full: []byte("b")}, IsEndElement: true},
+		{Name: xmltokenizer.Name{Local: []byte("a"), Full: []byte("a")}, IsEndElement: true},
+	}
+	if len(tokens) != len(expected) {
+		t.Fatalf("expected len: %d, got: %d", len(expected), len(tokens))
+	}
+	for i := range tokens {
+		if diff := expected[i].Diff(tokens[i]); diff != "" {
+			t.Fatalf("[%d] %s", i, diff)
+		}
+	}
+}
+
+func TestTokenizeString(t *testing.T) {
+	tokens, err := xmltest.TokenizeString(`<a><b`)
+	if err == nil {
+		t.Fatalf("expected error, got nil")
+	}
+	if len(tokens) != 1 {
+		t.Fatalf("expected len: 1, got: %d", len(tokens))
+	}
+}
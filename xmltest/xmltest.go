@@ -0,0 +1,31 @@
+// Package xmltest provides helpers for testing code built on top of the
+// xmltokenizer, such as schema packages implementing UnmarshalToken.
+package xmltest
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// AssertEquivalent unmarshals data using both unmarshalA and unmarshalB, e.g. one
+// built on top of xmltokenizer and the other built on top of encoding/xml, and
+// fails the test if any of them returns an error or their results differ.
+// The opts are passed to cmp.Diff to compare the results.
+func AssertEquivalent[T any](tb testing.TB, data []byte, unmarshalA, unmarshalB func(r io.Reader) (T, error), opts ...cmp.Option) {
+	tb.Helper()
+
+	a, err := unmarshalA(bytes.NewReader(data))
+	if err != nil {
+		tb.Fatalf("unmarshalA: %v", err)
+	}
+	b, err := unmarshalB(bytes.NewReader(data))
+	if err != nil {
+		tb.Fatalf("unmarshalB: %v", err)
+	}
+	if diff := cmp.Diff(a, b, opts...); diff != "" {
+		tb.Fatalf("results differ (-unmarshalA +unmarshalB):\n%s", diff)
+	}
+}
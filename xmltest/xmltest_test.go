@@ -0,0 +1,86 @@
+package xmltest_test
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/muktihari/xmltokenizer"
+	"github.com/muktihari/xmltokenizer/xmltest"
+)
+
+type person struct {
+	Name string `xml:"name"`
+}
+
+const sample = `<person><name>Gopher</name></person>`
+
+func unmarshalWithXMLTokenizer(r io.Reader) (p person, err error) {
+	tok := xmltokenizer.New(r)
+	for {
+		token, err := tok.Token()
+		if err == io.EOF {
+			return p, nil
+		}
+		if err != nil {
+			return p, err
+		}
+		if string(token.Name.Local) == "name" && !token.IsEndElement {
+			p.Name = string(token.Data)
+		}
+	}
+}
+
+func unmarshalWithStdlibXML(r io.Reader) (p person, err error) {
+	err = xml.NewDecoder(r).Decode(&p)
+	return p, err
+}
+
+// recorder records whether the test is failed without stopping the test.
+type recorder struct {
+	testing.TB
+	failed bool
+}
+
+func (r *recorder) Helper()                      {}
+func (r *recorder) Fatalf(string, ...any)        { r.failed = true }
+func (r *recorder) Errorf(string, ...any)        { r.failed = true }
+func (r *recorder) Logf(format string, a ...any) {}
+
+func TestAssertEquivalent(t *testing.T) {
+	tt := []struct {
+		name           string
+		unmarshalB     func(r io.Reader) (person, error)
+		expectedFailed bool
+	}{
+		{
+			name:       "equivalent",
+			unmarshalB: unmarshalWithStdlibXML,
+		},
+		{
+			name: "differ",
+			unmarshalB: func(r io.Reader) (person, error) {
+				return person{Name: "Ferris"}, nil
+			},
+			expectedFailed: true,
+		},
+		{
+			name: "error",
+			unmarshalB: func(r io.Reader) (person, error) {
+				return person{}, errors.New("error")
+			},
+			expectedFailed: true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			r := &recorder{TB: t}
+			xmltest.AssertEquivalent(r, []byte(sample), unmarshalWithXMLTokenizer, tc.unmarshalB)
+			if r.failed != tc.expectedFailed {
+				t.Fatalf("expected failed: %t, got: %t", tc.expectedFailed, r.failed)
+			}
+		})
+	}
+}